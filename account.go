@@ -1,6 +1,8 @@
 package endless
 
 import (
+	"fmt"
+
 	"github.com/endless-labs/endless-go-sdk/crypto"
 	"github.com/endless-labs/endless-go-sdk/internal/types"
 )
@@ -48,3 +50,53 @@ func NewEd25519SingleSenderAccount() (*Account, error) {
 func NewSecp256k1Account() (*Account, error) {
 	return types.NewSecp256k1Account()
 }
+
+// NewAccountFromKeyShares recombines the account's private key from shares of [crypto.SplitPrivateKey], and checks it
+// derives the address.  For an account whose key was rotated, recombine with [crypto.RecoverKeyFromShares] and the
+// current authentication key instead, then use [NewAccountFromSigner] with the address.
+func NewAccountFromKeyShares(address AccountAddress, shares ...*crypto.KeyShare) (*Account, error) {
+	signer, err := crypto.RecoverKeyFromShares(address.AuthKey(), shares...)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromSigner(signer)
+}
+
+// MnemonicPassphrase is an option to [NewAccountFromMnemonic], the optional BIP-39 passphrase
+type MnemonicPassphrase string
+
+// NewAccountFromMnemonic derives an account from a BIP-39 mnemonic phrase and derivation path.
+//
+// The key type follows the path, as in other Endless wallets:
+//   - A fully hardened path e.g. from [crypto.Ed25519DerivationPath] derives a legacy Ed25519 account with SLIP-0010
+//   - Otherwise e.g. from [crypto.Secp256k1DerivationPath] derives a Secp256k1 single key account with BIP-32
+//
+// Options:
+//   - [MnemonicPassphrase] the BIP-39 passphrase, defaults to empty
+func NewAccountFromMnemonic(phrase string, path string, options ...any) (*Account, error) {
+	passphrase := ""
+	for i, option := range options {
+		switch value := option.(type) {
+		case MnemonicPassphrase:
+			passphrase = string(value)
+		default:
+			return nil, fmt.Errorf("NewAccountFromMnemonic arg [%d] unknown option type %T", i+3, option)
+		}
+	}
+	seed, err := crypto.MnemonicToSeed(phrase, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.IsHardenedPath(path) {
+		privateKey, err := crypto.DeriveEd25519PrivateKey(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewAccountFromSigner(privateKey)
+	}
+	privateKey, err := crypto.DeriveSecp256k1PrivateKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromSigner(crypto.NewSingleSigner(privateKey))
+}
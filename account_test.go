@@ -0,0 +1,93 @@
+package endless
+
+import (
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// testCoinType is the BIP-44 coin type of the test derivation paths
+const testCoinType = uint32(637)
+
+func TestNewAccountFromMnemonic(t *testing.T) {
+	seed, err := crypto.MnemonicToSeed(testMnemonic, "")
+	assert.NoError(t, err)
+
+	account, err := NewAccountFromMnemonic(testMnemonic, crypto.Ed25519DerivationPath(testCoinType, 0))
+	assert.NoError(t, err)
+	ed25519Key, err := crypto.DeriveEd25519PrivateKey(seed, crypto.Ed25519DerivationPath(testCoinType, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, ed25519Key, account.Signer)
+	assert.Equal(t, AccountAddress(*ed25519Key.AuthKey()), account.Address)
+
+	account, err = NewAccountFromMnemonic(testMnemonic, crypto.Secp256k1DerivationPath(testCoinType, 0))
+	assert.NoError(t, err)
+	secp256k1Key, err := crypto.DeriveSecp256k1PrivateKey(seed, crypto.Secp256k1DerivationPath(testCoinType, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.NewSingleSigner(secp256k1Key), account.Signer)
+
+	// Each account index and passphrase is a different account
+	other, err := NewAccountFromMnemonic(testMnemonic, crypto.Ed25519DerivationPath(testCoinType, 1))
+	assert.NoError(t, err)
+	withPassphrase, err := NewAccountFromMnemonic(testMnemonic, crypto.Ed25519DerivationPath(testCoinType, 0), MnemonicPassphrase("secret"))
+	assert.NoError(t, err)
+	first, err := NewAccountFromMnemonic(testMnemonic, crypto.Ed25519DerivationPath(testCoinType, 0))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Address, other.Address)
+	assert.NotEqual(t, first.Address, withPassphrase.Address)
+
+	_, err = NewAccountFromMnemonic("abandon abandon", crypto.Ed25519DerivationPath(testCoinType, 0))
+	assert.ErrorIs(t, err, crypto.ErrInvalidMnemonic)
+	_, err = NewAccountFromMnemonic(testMnemonic, "44'/637'")
+	assert.Error(t, err)
+	_, err = NewAccountFromMnemonic(testMnemonic, crypto.Ed25519DerivationPath(testCoinType, 0), "passphrase")
+	assert.Error(t, err)
+}
+
+func TestNewAccountFromSigner_MultiEd25519(t *testing.T) {
+	node := newTestNode(t)
+	privateKeys := make([]*crypto.Ed25519PrivateKey, 3)
+	pubKeys := make([]*crypto.Ed25519PublicKey, 3)
+	for i := range privateKeys {
+		privateKey, err := crypto.GenerateEd25519PrivateKey()
+		assert.NoError(t, err)
+		privateKeys[i] = privateKey
+		pubKeys[i] = privateKey.PubKey().(*crypto.Ed25519PublicKey)
+	}
+	signer, err := crypto.NewMultiEd25519Signer(pubKeys, 2, privateKeys[0], privateKeys[2])
+	assert.NoError(t, err)
+	account, err := NewAccountFromSigner(signer)
+	assert.NoError(t, err)
+	node.SetAccount(account.Address, 0, account.AuthKey().ToHex())
+
+	_, err = node.client.BuildSignAndSubmitTransaction(account, envelopeTestPayload(t), GasUnitPrice(100), MaxGasAmount(1000))
+	if !assert.NoError(t, err) {
+		return
+	}
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.Equal(t, TransactionAuthenticatorMultiEd25519, submitted[0].Authenticator.Variant)
+	verification, err := node.client.VerifyTransaction(submitted[0])
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Err())
+}
+
+func TestNewAccountFromKeyShares(t *testing.T) {
+	account, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	shares, err := crypto.SplitPrivateKey(account.Signer, 2, 3)
+	assert.NoError(t, err)
+
+	recovered, err := NewAccountFromKeyShares(account.Address, shares[2], shares[0])
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, recovered.Address)
+	assert.Equal(t, account.AuthKey(), recovered.AuthKey())
+
+	other, err := NewEd25519Account()
+	assert.NoError(t, err)
+	_, err = NewAccountFromKeyShares(other.Address, shares[0], shares[1])
+	assert.ErrorIs(t, err, crypto.ErrKeyShareAuthKey)
+}
@@ -0,0 +1,262 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+//region RotationProofChallenge
+
+// RotationProofChallenge is the 0x1::account::RotationProofChallenge struct, signed by both the current and new keys
+// to prove the account owns both when rotating its authentication key
+//
+// Implements:
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type RotationProofChallenge struct {
+	SequenceNumber uint64         // SequenceNumber of the account when rotating, so the proof can't be replayed
+	Originator     AccountAddress // Originator is the address of the account
+	CurrentAuthKey AccountAddress // CurrentAuthKey is the authentication key being replaced
+	NewPublicKey   []byte         // NewPublicKey is the bytes of the new public key
+}
+
+// MarshalBCS serializes the challenge to bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (challenge *RotationProofChallenge) MarshalBCS(ser *bcs.Serializer) {
+	ser.U64(challenge.SequenceNumber)
+	ser.Struct(&challenge.Originator)
+	ser.Struct(&challenge.CurrentAuthKey)
+	ser.WriteBytes(challenge.NewPublicKey)
+}
+
+// UnmarshalBCS deserializes the challenge from bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (challenge *RotationProofChallenge) UnmarshalBCS(des *bcs.Deserializer) {
+	challenge.SequenceNumber = des.U64()
+	des.Struct(&challenge.Originator)
+	des.Struct(&challenge.CurrentAuthKey)
+	challenge.NewPublicKey = des.ReadBytes()
+}
+
+// SigningMessage is the bytes signed by both keys, the challenge's 0x1::type_info::TypeInfo followed by the
+// challenge, as 0x1::signature::SignedMessage is serialized
+func (challenge *RotationProofChallenge) SigningMessage() ([]byte, error) {
+	return bcs.SerializeSingle(func(ser *bcs.Serializer) {
+		ser.Struct(&AccountOne)
+		ser.WriteString("account")
+		ser.WriteString("RotationProofChallenge")
+		ser.Struct(challenge)
+	})
+}
+
+//endregion
+
+// ErrRotationScheme is returned when building a rotate_authentication_key payload from or to a key the
+// 0x1::account::rotate_authentication_key entry function doesn't accept.  [NodeClient.RotateAuthenticationKey] rotates
+// those keys by adding the new key and removing the current one instead.
+var ErrRotationScheme = errors.New("rotate_authentication_key only accepts Ed25519 and MultiEd25519 keys")
+
+// checkRotationScheme checks the key is one rotate_authentication_key accepts, so the transaction isn't submitted
+// only to abort on chain
+func checkRotationScheme(key crypto.PublicKey) error {
+	switch key.Scheme() {
+	case crypto.Ed25519Scheme, crypto.MultiEd25519Scheme:
+		return nil
+	default:
+		return fmt.Errorf("%w, got scheme %d", ErrRotationScheme, key.Scheme())
+	}
+}
+
+// canSignMultiAuthKey tells if the key's authenticators can be combined into a [crypto.MultiAuthKeyAuthenticator]
+func canSignMultiAuthKey(key crypto.PublicKey) bool {
+	switch key.(type) {
+	case *crypto.Ed25519PublicKey, *crypto.AnyPublicKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// RotateAuthenticationKeyPayload creates a payload rotating an account's authentication key from one public key to
+// another.  capRotateKey is the current key's signature of the [RotationProofChallenge], and capUpdateTable is the new
+// key's.
+//
+// Only Ed25519 and MultiEd25519 keys can be rotated, others return [ErrRotationScheme].
+func RotateAuthenticationKeyPayload(fromKey crypto.PublicKey, toKey crypto.PublicKey, capRotateKey crypto.Signature, capUpdateTable crypto.Signature) (*EntryFunction, error) {
+	if fromKey == nil || toKey == nil {
+		return nil, errors.New("rotation needs a single public key for both the current and new keys")
+	}
+	if err := checkRotationScheme(fromKey); err != nil {
+		return nil, err
+	}
+	if err := checkRotationScheme(toKey); err != nil {
+		return nil, err
+	}
+	fromScheme, err := bcs.SerializeU8(fromKey.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	toScheme, err := bcs.SerializeU8(toKey.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	fromPublicKeyBytes, err := bcs.SerializeBytes(fromKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	toPublicKeyBytes, err := bcs.SerializeBytes(toKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	capRotateKeyBytes, err := bcs.SerializeBytes(capRotateKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	capUpdateTableBytes, err := bcs.SerializeBytes(capUpdateTable.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return authenticationKeyPayloadCommon("rotate_authentication_key", fromScheme, fromPublicKeyBytes, toScheme, toPublicKeyBytes, capRotateKeyBytes, capUpdateTableBytes), nil
+}
+
+// RotateAuthenticationKey replaces the authentication key of account's signer with newSigner's.  Returns the account at
+// the same address bound to newSigner, to use once the returned transaction commits.
+//
+// If both keys are Ed25519 or MultiEd25519, e.g. a [crypto.Ed25519PrivateKey] or [crypto.MultiEd25519Signer], both
+// sign the [RotationProofChallenge], and the account's signer submits 0x1::account::rotate_authentication_key.
+//
+// Otherwise, e.g. for a [crypto.SingleSigner] or [MultiKeyAccount], the new key is added with
+// 0x1::account::batch_add_authentication_key, co-signed by the new key to prove it's held.  Once that commits, the
+// current key is removed with 0x1::account::batch_remove_authentication_key, signed by the new key, or by the current
+// key if the new key is a MultiKey or MultiEd25519 key, which can't sign for an account with several keys.  Rotating
+// between two such keys isn't supported.
+//
+// Options are as for [NodeClient.BuildTransaction], the SequenceNumber option is also used for the challenge, and the
+// removal is sent with the following sequence number.
+func (rc *NodeClient) RotateAuthenticationKey(account *Account, newSigner crypto.Signer, options ...any) (*Account, *api.SubmitTransactionResponse, error) {
+	fromKey, toKey := account.PubKey(), newSigner.PubKey()
+	if fromKey == nil || toKey == nil {
+		return nil, nil, errors.New("rotation needs a single public key for both the current and new keys")
+	}
+	replace := checkRotationScheme(fromKey) != nil || checkRotationScheme(toKey) != nil
+	if replace && !canSignMultiAuthKey(fromKey) && !canSignMultiAuthKey(toKey) {
+		return nil, nil, fmt.Errorf("rotation from %T to %T isn't supported, one of the keys must be an Ed25519 or SingleKey key", fromKey, toKey)
+	}
+
+	sequenceNumber, haveSequenceNumber := uint64(0), false
+	for _, option := range options {
+		if value, ok := option.(SequenceNumber); ok {
+			sequenceNumber, haveSequenceNumber = uint64(value), true
+		}
+	}
+	if !haveSequenceNumber {
+		info, err := rc.Account(account.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		sequenceNumber, err = info.SequenceNumber()
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, SequenceNumber(sequenceNumber))
+	}
+
+	var response *api.SubmitTransactionResponse
+	var err error
+	if replace {
+		response, err = rc.replaceAuthenticationKey(account, newSigner, sequenceNumber, options...)
+	} else {
+		response, err = rc.rotateAuthenticationKeyWithProof(account, newSigner, sequenceNumber, options...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	rotated, err := NewAccountFromSigner(newSigner, *account.Address.AuthKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	return rotated, response, nil
+}
+
+// rotateAuthenticationKeyWithProof submits rotate_authentication_key, with both keys' signatures of the challenge
+func (rc *NodeClient) rotateAuthenticationKeyWithProof(account *Account, newSigner crypto.Signer, sequenceNumber uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	fromKey, toKey := account.PubKey(), newSigner.PubKey()
+	challenge := &RotationProofChallenge{
+		SequenceNumber: sequenceNumber,
+		Originator:     account.Address,
+		NewPublicKey:   toKey.Bytes(),
+	}
+	challenge.CurrentAuthKey.FromAuthKey(account.AuthKey())
+	message, err := challenge.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	capRotateKey, err := account.SignMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("current key failed to sign rotation proof: %w", err)
+	}
+	capUpdateTable, err := newSigner.SignMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("new key failed to sign rotation proof: %w", err)
+	}
+	if !fromKey.Verify(message, capRotateKey) || !toKey.Verify(message, capUpdateTable) {
+		return nil, errors.New("rotation proof signature is invalid")
+	}
+
+	payload, err := RotateAuthenticationKeyPayload(fromKey, toKey, capRotateKey, capUpdateTable)
+	if err != nil {
+		return nil, err
+	}
+	return rc.BuildSignAndSubmitTransaction(account, TransactionPayload{Payload: payload}, options...)
+}
+
+// replaceAuthenticationKey adds the new key, waits for it to commit, then removes the current key
+func (rc *NodeClient) replaceAuthenticationKey(account *Account, newSigner crypto.Signer, sequenceNumber uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	newOwner, err := NewAccountFromSigner(newSigner)
+	if err != nil {
+		return nil, err
+	}
+	added, err := addAuthenticationKeys(rc, account, []TransactionSigner{newOwner}, 1, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add new authentication key: %w", err)
+	}
+	committed, err := rc.WaitForTransaction(added.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add new authentication key: %w", err)
+	}
+	if !committed.Success {
+		return nil, fmt.Errorf("failed to add new authentication key: %s", committed.VmStatus)
+	}
+
+	// Either key alone now signs for the account
+	remover := &MultiAuthKeyAccount{
+		Address:               account.Address,
+		NumSignaturesRequired: 1,
+		Owners:                []crypto.Signer{newSigner},
+		AuthKeys:              []crypto.AuthenticationKey{*account.AuthKey(), *newSigner.AuthKey()},
+	}
+	if !canSignMultiAuthKey(newSigner.PubKey()) {
+		remover.Owners = []crypto.Signer{account.Signer}
+	}
+	payload, err := RemoveAuthenticationKeysPayload([]crypto.AuthenticationKey{*account.AuthKey()}, 1)
+	if err != nil {
+		return nil, err
+	}
+	removeOptions := slices.DeleteFunc(slices.Clone(options), func(option any) bool { _, ok := option.(SequenceNumber); return ok })
+	removeOptions = append(removeOptions, SequenceNumber(sequenceNumber+1))
+	response, err := rc.BuildSignAndSubmitTransaction(remover, TransactionPayload{Payload: payload}, removeOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove current authentication key: %w", err)
+	}
+	return response, nil
+}
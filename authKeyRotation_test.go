@@ -0,0 +1,171 @@
+package endless
+
+import (
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationProofChallenge(t *testing.T) {
+	challenge := &RotationProofChallenge{
+		SequenceNumber: 7,
+		Originator:     AccountAddress{0x01},
+		CurrentAuthKey: AccountAddress{0x02},
+		NewPublicKey:   []byte{0x03, 0x04},
+	}
+	challengeBytes, err := bcs.Serialize(challenge)
+	assert.NoError(t, err)
+	decoded := &RotationProofChallenge{}
+	assert.NoError(t, bcs.Deserialize(decoded, challengeBytes))
+	assert.Equal(t, challenge, decoded)
+
+	// The message is the TypeInfo of 0x1::account::RotationProofChallenge, then the challenge
+	message, err := challenge.SigningMessage()
+	assert.NoError(t, err)
+	typeInfo := append(append(AccountOne[:], append([]byte{7}, "account"...)...), append([]byte{22}, "RotationProofChallenge"...)...)
+	assert.Equal(t, append(typeInfo, challengeBytes...), message)
+}
+
+func TestRotateAuthenticationKey(t *testing.T) {
+	node := newTestNode(t)
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(account.Address, 3, account.AuthKey().ToHex())
+
+	privateKeys := make([]*crypto.Ed25519PrivateKey, 3)
+	pubKeys := make([]*crypto.Ed25519PublicKey, 3)
+	for i := range privateKeys {
+		privateKeys[i], err = crypto.GenerateEd25519PrivateKey()
+		assert.NoError(t, err)
+		pubKeys[i] = privateKeys[i].PubKey().(*crypto.Ed25519PublicKey)
+	}
+	multiEd25519, err := crypto.NewMultiEd25519Signer(pubKeys, 2, privateKeys[0], privateKeys[2])
+	assert.NoError(t, err)
+	ed25519Key, err := crypto.GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+
+	// Ed25519 to MultiEd25519 and back, the address stays the same
+	current := account
+	for i, newSigner := range []crypto.Signer{multiEd25519, ed25519Key} {
+		var options []any
+		if i > 0 {
+			options = append(options, SequenceNumber(3+i))
+		}
+		rotated, _, err := node.client.RotateAuthenticationKey(current, newSigner, options...)
+		assert.NoError(t, err)
+		assert.Equal(t, account.Address, rotated.Address)
+		assert.Equal(t, newSigner.AuthKey(), rotated.AuthKey())
+
+		submitted := node.Submitted()
+		assert.Len(t, submitted, i+1)
+		txn := submitted[i]
+		assert.NoError(t, txn.Verify())
+		entryFunction := txn.Transaction.Payload.Payload.(*EntryFunction)
+		assert.Equal(t, "rotate_authentication_key", entryFunction.Function)
+		assert.Equal(t, []byte{current.PubKey().Scheme()}, entryFunction.Args[0])
+		assert.Equal(t, []byte{newSigner.PubKey().Scheme()}, entryFunction.Args[2])
+
+		// Both proofs are of the challenge at the transaction's sequence number
+		challenge := &RotationProofChallenge{
+			SequenceNumber: txn.Transaction.SequenceNumber,
+			Originator:     account.Address,
+			NewPublicKey:   newSigner.PubKey().Bytes(),
+		}
+		challenge.CurrentAuthKey.FromAuthKey(current.AuthKey())
+		message, err := challenge.SigningMessage()
+		assert.NoError(t, err)
+		for _, proof := range []struct {
+			pubKey crypto.PublicKey
+			arg    []byte
+		}{{current.PubKey(), entryFunction.Args[4]}, {newSigner.PubKey(), entryFunction.Args[5]}} {
+			des := bcs.NewDeserializer(proof.arg)
+			signatureBytes := des.ReadBytes()
+			assert.NoError(t, des.Error())
+			var signature crypto.Signature
+			switch proof.pubKey.(type) {
+			case *crypto.Ed25519PublicKey:
+				signature = &crypto.Ed25519Signature{}
+			case *crypto.MultiEd25519PublicKey:
+				signature = &crypto.MultiEd25519Signature{}
+			}
+			assert.NoError(t, signature.FromBytes(signatureBytes))
+			assert.True(t, proof.pubKey.Verify(message, signature))
+		}
+		current = rotated
+	}
+	assert.Equal(t, uint64(4), node.Submitted()[1].Transaction.SequenceNumber)
+
+	// Other keys can't be rotated with rotate_authentication_key
+	secp256k1Key, err := crypto.GenerateSecp256k1Key()
+	assert.NoError(t, err)
+	_, err = RotateAuthenticationKeyPayload(current.PubKey(), crypto.NewSingleSigner(secp256k1Key).PubKey(), &crypto.Ed25519Signature{}, &crypto.AnySignature{})
+	assert.ErrorIs(t, err, ErrRotationScheme)
+
+	// Signers without a single public key can't prove ownership
+	_, _, err = node.client.RotateAuthenticationKey(current, &MultiAuthKeyAccount{}, SequenceNumber(5))
+	assert.Error(t, err)
+}
+
+func TestRotateAuthenticationKey_Replace(t *testing.T) {
+	node := newTestNode(t)
+	node.AutoCommit = true
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(account.Address, 0, account.AuthKey().ToHex())
+
+	secp256k1Key, err := crypto.GenerateSecp256k1Key()
+	assert.NoError(t, err)
+	signers, pubKeys := testMultiKeySigners(t)
+	multiKey, err := NewMultiKeyAccount(pubKeys, 2, signers[0], signers[2])
+	assert.NoError(t, err)
+
+	// Ed25519 to SingleKey, removed by the new key, then SingleKey to MultiKey, removed by the current key
+	current := account
+	for i, newSigner := range []crypto.Signer{crypto.NewSingleSigner(secp256k1Key), multiKey} {
+		rotated, response, err := node.client.RotateAuthenticationKey(current, newSigner)
+		assert.NoError(t, err)
+		assert.Equal(t, account.Address, rotated.Address)
+		assert.Equal(t, newSigner.AuthKey(), rotated.AuthKey())
+
+		submitted := node.Submitted()
+		assert.Len(t, submitted, 2*i+2)
+		add, remove := submitted[2*i], submitted[2*i+1]
+		for _, txn := range []*SignedTransaction{add, remove} {
+			assert.NoError(t, txn.Verify())
+			assert.Equal(t, account.Address, txn.Transaction.Sender)
+		}
+		assert.Equal(t, uint64(2*i), add.Transaction.SequenceNumber)
+		assert.Equal(t, uint64(2*i+1), remove.Transaction.SequenceNumber)
+
+		// The new key co-signs adding itself
+		assert.Equal(t, "batch_add_authentication_key", add.Transaction.Payload.Payload.(*EntryFunction).Function)
+		multiAgent := add.Authenticator.Auth.(*MultiAgentTransactionAuthenticator)
+		assert.Equal(t, []AccountAddress{AccountAddress(*newSigner.AuthKey())}, multiAgent.SecondarySignerAddresses)
+
+		// Then the current key is removed
+		removePayload := remove.Transaction.Payload.Payload.(*EntryFunction)
+		assert.Equal(t, "batch_remove_authentication_key", removePayload.Function)
+		assert.Equal(t, append([]byte{1, 32}, current.AuthKey()[:]...), removePayload.Args[0])
+		removedBy := remove.Authenticator.Auth.(*SingleSenderTransactionAuthenticator).Sender.Auth.(*crypto.MultiAuthKeyAuthenticator)
+		assert.Len(t, removedBy.PubKeys, 1)
+		if i == 0 {
+			assert.Equal(t, newSigner.AuthKey(), removedBy.PubKeys[0].AuthKey())
+		} else {
+			assert.Equal(t, current.AuthKey(), removedBy.PubKeys[0].AuthKey())
+		}
+		hash, err := remove.Hash()
+		assert.NoError(t, err)
+		assert.Equal(t, hash, response.Hash)
+		current = rotated
+	}
+
+	// Neither MultiKey can sign for the account while it has both keys
+	otherSigners, otherPubKeys := testMultiKeySigners(t)
+	otherMultiKey, err := NewMultiKeyAccount(otherPubKeys, 1, otherSigners[0])
+	assert.NoError(t, err)
+	_, _, err = node.client.RotateAuthenticationKey(current, otherMultiKey)
+	assert.Error(t, err)
+	assert.Len(t, node.Submitted(), 4)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/crypto"
 )
 
 // NetworkConfig a configuration for the Client and which network to use.  Use one of the preconfigured  [TestnetConfig], or [MainnetConfig] unless you have your own full node.
@@ -167,17 +168,18 @@ type EndlessRpcClient interface {
 	//	}
 	TransactionByVersion(version uint64) (data *api.CommittedTransaction, err error)
 
-	// PollForTransactions Waits up to 10 seconds for transactions to be done, polling at 10Hz
-	// Accepts options PollPeriod and PollTimeout which should wrap time.Duration values.
+	// PollForTransactions Waits up to 10 seconds for transactions to be done, polling at 10Hz and backing off
+	// Accepts options PollPeriod, PollMaxPeriod and PollTimeout which should wrap time.Duration values, and
+	// TransactionLifecycle callbacks.  Returns a result per hash, in the same order as the hashes.
 	//
 	//	hashes := []string{"0x1234", "0x4567"}
-	//	err := client.PollForTransactions(hashes)
+	//	results, err := client.PollForTransactions(hashes)
 	//
 	// Can additionally configure different options
 	//
 	//	hashes := []string{"0x1234", "0x4567"}
-	//	err := client.PollForTransactions(hashes, PollPeriod(500 * time.Milliseconds), PollTimeout(5 * time.Seconds))
-	PollForTransactions(txnHashes []string, options ...any) error
+	//	results, err := client.PollForTransactions(hashes, PollPeriod(500 * time.Milliseconds), PollTimeout(5 * time.Seconds))
+	PollForTransactions(txnHashes []string, options ...any) ([]TransactionWaitResult, error)
 
 	// WaitForTransaction Do a long-GET for one transaction and wait for it to complete
 	//
@@ -554,17 +556,18 @@ func (client *Client) TransactionsByVersions(version []uint64, prune bool) (data
 	return client.nodeClient.TransactionsByVersions(version, prune)
 }
 
-// PollForTransactions Waits up to 10 seconds for transactions to be done, polling at 10Hz
-// Accepts options PollPeriod and PollTimeout which should wrap time.Duration values.
+// PollForTransactions Waits up to 10 seconds for transactions to be done, polling at 10Hz and backing off
+// Accepts options PollPeriod, PollMaxPeriod and PollTimeout which should wrap time.Duration values, and
+// TransactionLifecycle callbacks.  Returns a result per hash, in the same order as the hashes.
 //
 //	hashes := []string{"0x1234", "0x4567"}
-//	err := client.PollForTransactions(hashes)
+//	results, err := client.PollForTransactions(hashes)
 //
 // Can additionally configure different options
 //
 //	hashes := []string{"0x1234", "0x4567"}
-//	err := client.PollForTransactions(hashes, PollPeriod(500 * time.Milliseconds), PollTimeout(5 * time.Seconds))
-func (client *Client) PollForTransactions(txnHashes []string, options ...any) error {
+//	results, err := client.PollForTransactions(hashes, PollPeriod(500 * time.Milliseconds), PollTimeout(5 * time.Seconds))
+func (client *Client) PollForTransactions(txnHashes []string, options ...any) ([]TransactionWaitResult, error) {
 	return client.nodeClient.PollForTransactions(txnHashes, options...)
 }
 
@@ -791,3 +794,61 @@ func (client *Client) AccountModule(address AccountAddress, moduleName string, l
 func (client *Client) EntryFunctionWithArgs(address AccountAddress, moduleName string, functionName string, typeArgs []any, args []any) (*EntryFunction, error) {
 	return client.nodeClient.EntryFunctionWithArgs(address, moduleName, functionName, typeArgs, args)
 }
+
+// NewSequenceNumberManager creates a [SequenceNumberManager] for the sender address, see [NewSequenceNumberManager]
+func (client *Client) NewSequenceNumberManager(address AccountAddress, options ...any) (*SequenceNumberManager, error) {
+	return NewSequenceNumberManager(client.nodeClient, address, options...)
+}
+
+// VerifyOffchainMessage verifies a [SignedOffchainMessage], including that it was signed by authentication keys of
+// the account on-chain
+//
+//	signed, _ := endless.ParseSignedOffchainMessage(signedHex)
+//	err := client.VerifyOffchainMessage(signed)
+func (client *Client) VerifyOffchainMessage(signed *SignedOffchainMessage) error {
+	return client.nodeClient.VerifyOffchainMessage(signed)
+}
+
+// VerifyTransaction checks a signed transaction's signatures against the signing accounts on-chain, including their
+// authentication keys and NumSignaturesRequired, and returns a report per signer
+//
+//	verification, err := client.VerifyTransaction(signedTxn)
+//	if err == nil && verification.Err() == nil {
+//		_, err = client.SubmitTransaction(signedTxn)
+//	}
+func (client *Client) VerifyTransaction(txn *SignedTransaction) (*TransactionVerification, error) {
+	return client.nodeClient.VerifyTransaction(txn)
+}
+
+// NewMultisigAccount returns a [MultisigAccount] for reading and executing proposals of the on-chain multisig at
+// address
+//
+//	multisig := client.NewMultisigAccount(multisigAddress)
+//	info, err := multisig.Info()
+func (client *Client) NewMultisigAccount(address AccountAddress) *MultisigAccount {
+	return NewMultisigAccount(client.nodeClient, address)
+}
+
+// NewMultiAuthKeyAccount loads a [MultiAuthKeyAccount] from on-chain, with the owner keys held locally, see
+// [NewMultiAuthKeyAccount]
+func (client *Client) NewMultiAuthKeyAccount(address AccountAddress, owners ...crypto.Signer) (*MultiAuthKeyAccount, error) {
+	return NewMultiAuthKeyAccount(client.nodeClient, address, owners...)
+}
+
+// UpgradeToMultiAuthKeyAccount adds the new owners' keys to a single key account, see [UpgradeToMultiAuthKeyAccount]
+//
+//	account, response, err := client.UpgradeToMultiAuthKeyAccount(owner, []endless.TransactionSigner{owner2, owner3}, 2)
+//	_, err = client.WaitForTransaction(response.Hash)
+func (client *Client) UpgradeToMultiAuthKeyAccount(account *Account, newOwners []TransactionSigner, numSignaturesRequired uint64, options ...any) (*MultiAuthKeyAccount, *api.SubmitTransactionResponse, error) {
+	return UpgradeToMultiAuthKeyAccount(client.nodeClient, account, newOwners, numSignaturesRequired, options...)
+}
+
+// RotateAuthenticationKey replaces the account's authentication key with newSigner's, returning the account at the same
+// address bound to newSigner, see [NodeClient.RotateAuthenticationKey]
+//
+//	newKey, err := crypto.GenerateEd25519PrivateKey()
+//	rotated, response, err := client.RotateAuthenticationKey(account, crypto.NewSingleSigner(newKey))
+//	_, err = client.WaitForTransaction(response.Hash)
+func (client *Client) RotateAuthenticationKey(account *Account, newSigner crypto.Signer, options ...any) (*Account, *api.SubmitTransactionResponse, error) {
+	return client.nodeClient.RotateAuthenticationKey(account, newSigner, options...)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk"
+)
+
+// describe prints what the transaction does, so it can be checked before signing
+func describe(out io.Writer, in *input) error {
+	var rawTxn *endless.RawTransaction
+	kind := "single signer"
+	var secondarySigners []endless.AccountAddress
+	var feePayer *endless.AccountAddress
+	switch txn := in.txn.(type) {
+	case *endless.RawTransaction:
+		rawTxn = txn
+	case *endless.RawTransactionWithData:
+		switch inner := txn.Inner.(type) {
+		case *endless.MultiAgentRawTransactionWithData:
+			kind = "multi-agent"
+			rawTxn = inner.RawTxn
+			secondarySigners = inner.SecondarySigners
+		case *endless.MultiAgentWithFeePayerRawTransactionWithData:
+			kind = "fee payer"
+			rawTxn = inner.RawTxn
+			secondarySigners = inner.SecondarySigners
+			feePayer = inner.FeePayer
+		default:
+			return fmt.Errorf("unknown RawTransactionWithData variant %d", txn.Variant)
+		}
+	default:
+		return fmt.Errorf("unsupported transaction type %T", in.txn)
+	}
+
+	lines := [][2]string{
+		{"Type", kind},
+		{"Sender", rawTxn.Sender.String()},
+		{"Sequence number", fmt.Sprint(rawTxn.SequenceNumber)},
+		{"Max gas amount", fmt.Sprint(rawTxn.MaxGasAmount)},
+		{"Gas unit price", fmt.Sprint(rawTxn.GasUnitPrice)},
+		{"Expiration", fmt.Sprintf("%s (%d)", time.Unix(int64(rawTxn.ExpirationTimestampSeconds), 0).UTC().Format(time.RFC3339), rawTxn.ExpirationTimestampSeconds)},
+		{"Chain id", fmt.Sprint(rawTxn.ChainId)},
+	}
+	for i := range secondarySigners {
+		lines = append(lines, [2]string{fmt.Sprintf("Secondary signer %d", i), secondarySigners[i].String()})
+	}
+	if feePayer != nil {
+		lines = append(lines, [2]string{"Fee payer", feePayer.String()})
+	}
+	lines = append(lines, describePayload(rawTxn.Payload.Payload)...)
+	if in.envelope != nil {
+		for _, signer := range in.envelope.Signers {
+			lines = append(lines, [2]string{
+				"Signer " + signer.Role.String(),
+				fmt.Sprintf("%s signed %d of %d", signer.Address.String(), len(signer.Authenticators), signer.Threshold),
+			})
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(out, "%-20s %s\n", line[0]+":", line[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describePayload lists the payload's function and arguments
+func describePayload(payload endless.TransactionPayloadImpl) [][2]string {
+	switch payload := payload.(type) {
+	case *endless.EntryFunction:
+		return describeEntryFunction(payload.Module, payload.Function, payload.ArgTypes, payload.Args)
+	case *endless.SafeEntryFunction:
+		lines := describeEntryFunction(payload.Module, payload.Function, payload.ArgTypes, payload.Args)
+		return append(lines, [2]string{"Safe hash", endless.BytesToHex(payload.Hash[:])})
+	case *endless.Script:
+		lines := [][2]string{{"Payload", fmt.Sprintf("script of %d bytes", len(payload.Code))}}
+		for i := range payload.ArgTypes {
+			lines = append(lines, [2]string{fmt.Sprintf("Type arg %d", i), payload.ArgTypes[i].String()})
+		}
+		for i, arg := range payload.Args {
+			lines = append(lines, [2]string{fmt.Sprintf("Arg %d", i), fmt.Sprint(arg.Value)})
+		}
+		return lines
+	case *endless.Multisig:
+		return [][2]string{{"Payload", "multisig transaction for " + payload.MultisigAddress.String()}}
+	default:
+		return [][2]string{{"Payload", fmt.Sprintf("%T", payload)}}
+	}
+}
+
+func describeEntryFunction(module endless.ModuleId, function string, argTypes []endless.TypeTag, args [][]byte) [][2]string {
+	lines := [][2]string{{"Payload", strings.Join([]string{module.Address.String(), module.Name, function}, "::")}}
+	for i := range argTypes {
+		lines = append(lines, [2]string{fmt.Sprintf("Type arg %d", i), argTypes[i].String()})
+	}
+	for i, arg := range args {
+		lines = append(lines, [2]string{fmt.Sprintf("Arg %d", i), endless.BytesToHex(arg)})
+	}
+	return lines
+}
@@ -0,0 +1,304 @@
+// endless-sign signs transactions on an offline machine.
+//
+// It reads an unsigned transaction, prints what it does, and signs it with a local key.  It never touches the network,
+// so it can run on an air-gapped machine, with the transaction carried over by file or QR code.
+//
+// The input is one of:
+//   - A [endless.TransactionEnvelope] as JSON or base64, the output is the envelope with the signature added
+//   - A hex BCS [endless.RawTransaction], the output is a hex BCS [endless.SignedTransaction]
+//   - A hex BCS [endless.RawTransactionWithData], the output is a hex BCS [crypto.AccountAuthenticator]
+//
+// Usage:
+//
+//	endless-sign -key-file key.txt [-address <address>] [-output auto|authenticator|signed|envelope] [-show] [file]
+//	endless-sign -keystore key.json [-password-file file] [flags] [file]
+//
+// If no file is given, the transaction is read from stdin.  The key is an AIP-80 private key string e.g.
+// "ed25519-priv-0x...", or hex with -key-type.  Alternatively the key can be an [endless.EncryptedKey] keystore file,
+// with the password from -password-file or $ENDLESS_KEYSTORE_PASSWORD.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// Output formats
+const (
+	outputAuto          = "auto"
+	outputAuthenticator = "authenticator"
+	outputSigned        = "signed"
+	outputEnvelope      = "envelope"
+)
+
+// input is a decoded transaction to sign
+type input struct {
+	envelope *endless.TransactionEnvelope // envelope is set if the input was an envelope
+	isJson   bool                         // isJson is true if the envelope was JSON
+	txn      endless.RawTransactionImpl   // txn is the transaction to sign
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "endless-sign:", err)
+		os.Exit(1)
+	}
+}
+
+// run is the whole program, separate from main for testing
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("endless-sign", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	keyString := flags.String("key", "", "private key, AIP-80 or hex.  Prefer -key-file, so the key isn't in shell history")
+	keyFile := flags.String("key-file", "", "file containing the private key, AIP-80 or hex")
+	keyType := flags.String("key-type", "", "key type for a hex key: ed25519 or secp256k1")
+	keystoreFile := flags.String("keystore", "", "encrypted keystore file containing the key")
+	passwordFile := flags.String("password-file", "", "file containing the keystore password, defaults to $ENDLESS_KEYSTORE_PASSWORD")
+	singleKey := flags.Bool("single-key", false, "sign an ed25519 key as a single key account rather than legacy ed25519")
+	addressString := flags.String("address", "", "address of the signing account, if it differs from the key's derived address")
+	output := flags.String("output", outputAuto, "output: auto, authenticator, signed, or envelope")
+	show := flags.Bool("show", false, "only print the decoded transaction, don't sign")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return errors.New("expected at most one input file")
+	}
+
+	var raw []byte
+	var err error
+	if flags.NArg() == 1 {
+		raw, err = os.ReadFile(flags.Arg(0))
+	} else {
+		raw, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return err
+	}
+	in, err := decodeInput(string(raw))
+	if err != nil {
+		return err
+	}
+	if err = describe(stderr, in); err != nil {
+		return err
+	}
+	if *show {
+		return nil
+	}
+
+	var account *endless.Account
+	if *keystoreFile != "" {
+		account, err = loadKeystore(*keystoreFile, *passwordFile)
+	} else {
+		if *keyFile != "" {
+			keyBytes, err := os.ReadFile(*keyFile)
+			if err != nil {
+				return err
+			}
+			*keyString = string(keyBytes)
+		}
+		if *keyString == "" {
+			return errors.New("a key is required, use -key-file, -key or -keystore")
+		}
+		account, err = loadAccount(strings.TrimSpace(*keyString), *keyType, *singleKey)
+	}
+	if err != nil {
+		return err
+	}
+	if *addressString != "" {
+		if err = account.Address.ParseStringRelaxed(*addressString); err != nil {
+			return fmt.Errorf("invalid address: %w", err)
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "Signing as %s\n", account.Address.String())
+
+	result, err := sign(in, account, *output)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, result)
+	return err
+}
+
+// decodeInput detects the format of the input, and decodes it
+func decodeInput(raw string) (*input, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("input is empty")
+	}
+	if strings.HasPrefix(raw, "{") {
+		envelope, err := endless.ParseTransactionEnvelope(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &input{envelope: envelope, isJson: true, txn: envelope.Transaction}, nil
+	}
+	if isHex(raw) {
+		txnBytes, err := endless.ParseHex(raw)
+		if err != nil {
+			return nil, err
+		}
+		// Only one of the two will consume all the bytes
+		rawTxn := &endless.RawTransaction{}
+		if err = bcs.Deserialize(rawTxn, txnBytes); err == nil {
+			return &input{txn: rawTxn}, nil
+		}
+		rawTxnWithData := &endless.RawTransactionWithData{}
+		if err = bcs.Deserialize(rawTxnWithData, txnBytes); err == nil {
+			return &input{txn: rawTxnWithData}, nil
+		}
+		return nil, errors.New("hex input is not a RawTransaction or RawTransactionWithData")
+	}
+	envelope, err := endless.ParseTransactionEnvelope(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &input{envelope: envelope, txn: envelope.Transaction}, nil
+}
+
+func isHex(value string) bool {
+	value = strings.TrimPrefix(value, "0x")
+	if len(value) == 0 || len(value)%2 != 0 {
+		return false
+	}
+	for _, c := range value {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// loadAccount parses a private key into an account, detecting the key type from an AIP-80 prefix
+func loadAccount(key string, keyType string, singleKey bool) (*endless.Account, error) {
+	variant := crypto.PrivateKeyVariant(keyType)
+	for prefixVariant, prefix := range crypto.AIP80Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			variant = prefixVariant
+		}
+	}
+	if variant == "" {
+		return nil, errors.New("key type is unknown, use an AIP-80 key or -key-type")
+	}
+	keyBytes, err := crypto.ParsePrivateKey(key, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	var signer crypto.Signer
+	switch variant {
+	case crypto.PrivateKeyVariantEd25519:
+		privateKey := &crypto.Ed25519PrivateKey{}
+		if err = privateKey.FromBytes(keyBytes); err != nil {
+			return nil, err
+		}
+		signer = privateKey
+		if singleKey {
+			signer = crypto.NewSingleSigner(privateKey)
+		}
+	case crypto.PrivateKeyVariantSecp256k1:
+		privateKey := &crypto.Secp256k1PrivateKey{}
+		if err = privateKey.FromBytes(keyBytes); err != nil {
+			return nil, err
+		}
+		signer = crypto.NewSingleSigner(privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", variant)
+	}
+	return endless.NewAccountFromSigner(signer)
+}
+
+// loadKeystore decrypts an account from a keystore file
+func loadKeystore(path string, passwordFile string) (*endless.Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := endless.ParseEncryptedKey(data)
+	if err != nil {
+		return nil, err
+	}
+	password, ok := os.LookupEnv("ENDLESS_KEYSTORE_PASSWORD")
+	if passwordFile != "" {
+		passwordBytes, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		password, ok = strings.TrimRight(string(passwordBytes), "\r\n"), true
+	}
+	if !ok {
+		return nil, errors.New("a keystore password is required, use -password-file or $ENDLESS_KEYSTORE_PASSWORD")
+	}
+	return key.Decrypt(password)
+}
+
+// sign signs the input, and formats the output
+func sign(in *input, account *endless.Account, output string) (string, error) {
+	if output == outputAuto {
+		switch {
+		case in.envelope != nil:
+			output = outputEnvelope
+		case isRawTransaction(in.txn):
+			output = outputSigned
+		default:
+			output = outputAuthenticator
+		}
+	}
+
+	switch output {
+	case outputAuthenticator:
+		auth, err := in.txn.Sign(account)
+		if err != nil {
+			return "", err
+		}
+		authBytes, err := bcs.Serialize(auth)
+		if err != nil {
+			return "", err
+		}
+		return endless.BytesToHex(authBytes), nil
+	case outputSigned, outputEnvelope:
+		envelope := in.envelope
+		if envelope == nil {
+			var err error
+			envelope, err = endless.NewTransactionEnvelope(in.txn)
+			if err != nil {
+				return "", err
+			}
+		}
+		if err := envelope.Sign(account); err != nil {
+			return "", err
+		}
+		if output == outputEnvelope {
+			if in.isJson {
+				envelopeJson, err := json.Marshal(envelope)
+				return string(envelopeJson), err
+			}
+			return envelope.Base64()
+		}
+		signedTxn, err := envelope.Finalize()
+		if err != nil {
+			return "", err
+		}
+		signedBytes, err := bcs.Serialize(signedTxn)
+		if err != nil {
+			return "", err
+		}
+		return endless.BytesToHex(signedBytes), nil
+	default:
+		return "", fmt.Errorf("unknown output %s", output)
+	}
+}
+
+func isRawTransaction(txn endless.RawTransactionImpl) bool {
+	_, ok := txn.(*endless.RawTransaction)
+	return ok
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTransaction(t *testing.T, sender endless.AccountAddress) *endless.RawTransaction {
+	entryFunction, err := endless.CoinTransferPayload(nil, endless.AccountOne, 100)
+	assert.NoError(t, err)
+	return &endless.RawTransaction{
+		Sender:                     sender,
+		SequenceNumber:             3,
+		Payload:                    endless.TransactionPayload{Payload: entryFunction},
+		MaxGasAmount:               1000,
+		GasUnitPrice:               100,
+		ExpirationTimestampSeconds: 1_700_000_000,
+		ChainId:                    4,
+	}
+}
+
+func testKey(t *testing.T) (*crypto.Ed25519PrivateKey, string, *endless.Account) {
+	privateKey, err := crypto.GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	aip80, err := privateKey.ToAIP80()
+	assert.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	assert.NoError(t, os.WriteFile(keyFile, []byte(aip80+"\n"), 0600))
+	account, err := endless.NewAccountFromSigner(privateKey)
+	assert.NoError(t, err)
+	return privateKey, keyFile, account
+}
+
+func runWithInput(t *testing.T, stdin string, args ...string) (string, string, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err := run(args, strings.NewReader(stdin), stdout, stderr)
+	return strings.TrimSpace(stdout.String()), stderr.String(), err
+}
+
+func TestRun_RawTransaction(t *testing.T) {
+	_, keyFile, account := testKey(t)
+	rawTxn := testTransaction(t, account.Address)
+	txnBytes, err := bcs.Serialize(rawTxn)
+	assert.NoError(t, err)
+
+	stdout, stderr, err := runWithInput(t, endless.BytesToHex(txnBytes), "-key-file", keyFile)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "0x1::endless_account::transfer")
+	assert.Contains(t, stderr, "Sequence number:     3")
+
+	signedBytes, err := endless.ParseHex(stdout)
+	assert.NoError(t, err)
+	signedTxn := &endless.SignedTransaction{}
+	assert.NoError(t, bcs.Deserialize(signedTxn, signedBytes))
+	assert.NoError(t, signedTxn.Verify())
+	assert.Equal(t, account.Address, signedTxn.Transaction.Sender)
+}
+
+func TestRun_Keystore(t *testing.T) {
+	account, err := endless.NewSecp256k1Account()
+	assert.NoError(t, err)
+	key, err := endless.EncryptKey(account, "hunter2", endless.KeystoreScrypt{N: 1 << 10, R: 8, P: 1})
+	assert.NoError(t, err)
+	keyJson, err := json.Marshal(key)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	keystoreFile := filepath.Join(dir, "key.json")
+	assert.NoError(t, os.WriteFile(keystoreFile, keyJson, 0600))
+	passwordFile := filepath.Join(dir, "password")
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("hunter2\n"), 0600))
+
+	txnBytes, err := bcs.Serialize(testTransaction(t, account.Address))
+	assert.NoError(t, err)
+	stdout, _, err := runWithInput(t, endless.BytesToHex(txnBytes), "-keystore", keystoreFile, "-password-file", passwordFile)
+	assert.NoError(t, err)
+	signedBytes, err := endless.ParseHex(stdout)
+	assert.NoError(t, err)
+	signedTxn := &endless.SignedTransaction{}
+	assert.NoError(t, bcs.Deserialize(signedTxn, signedBytes))
+	assert.NoError(t, signedTxn.Verify())
+
+	t.Setenv("ENDLESS_KEYSTORE_PASSWORD", "wrong")
+	_, _, err = runWithInput(t, endless.BytesToHex(txnBytes), "-keystore", keystoreFile)
+	assert.ErrorIs(t, err, endless.ErrKeystorePassword)
+}
+
+func TestRun_EnvelopeFile(t *testing.T) {
+	_, keyFile, account := testKey(t)
+	secondary, err := endless.NewEd25519Account()
+	assert.NoError(t, err)
+	rawTxn := &endless.RawTransactionWithData{
+		Variant: endless.MultiAgentRawTransactionWithDataVariant,
+		Inner: &endless.MultiAgentRawTransactionWithData{
+			RawTxn:           testTransaction(t, account.Address),
+			SecondarySigners: []endless.AccountAddress{secondary.Address},
+		},
+	}
+	envelope, err := endless.NewTransactionEnvelope(rawTxn)
+	assert.NoError(t, err)
+	assert.NoError(t, envelope.Sign(secondary))
+	encoded, err := envelope.Base64()
+	assert.NoError(t, err)
+	inputFile := filepath.Join(t.TempDir(), "txn.txt")
+	assert.NoError(t, os.WriteFile(inputFile, []byte(encoded), 0600))
+
+	stdout, stderr, err := runWithInput(t, "", "-key-file", keyFile, inputFile)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "multi-agent")
+	assert.Contains(t, stderr, "signed 0 of 1")
+
+	signed, err := endless.ParseTransactionEnvelope(stdout)
+	assert.NoError(t, err)
+	assert.True(t, signed.Complete())
+
+	// Same again, asking for the signed transaction
+	stdout, _, err = runWithInput(t, encoded, "-key-file", keyFile, "-output", "signed")
+	assert.NoError(t, err)
+	signedBytes, err := endless.ParseHex(stdout)
+	assert.NoError(t, err)
+	signedTxn := &endless.SignedTransaction{}
+	assert.NoError(t, bcs.Deserialize(signedTxn, signedBytes))
+	assert.NoError(t, signedTxn.Verify())
+}
+
+func TestRun_Authenticator(t *testing.T) {
+	privateKey, _, account := testKey(t)
+	aip80, err := privateKey.ToAIP80()
+	assert.NoError(t, err)
+	rawTxn := &endless.RawTransactionWithData{
+		Variant: endless.MultiAgentWithFeePayerRawTransactionWithDataVariant,
+		Inner: &endless.MultiAgentWithFeePayerRawTransactionWithData{
+			RawTxn:           testTransaction(t, account.Address),
+			SecondarySigners: []endless.AccountAddress{},
+			FeePayer:         &endless.AccountZero,
+		},
+	}
+	txnBytes, err := bcs.Serialize(rawTxn)
+	assert.NoError(t, err)
+
+	stdout, stderr, err := runWithInput(t, endless.BytesToHex(txnBytes), "-key", aip80)
+	assert.NoError(t, err)
+	assert.Contains(t, stderr, "fee payer")
+	authBytes, err := endless.ParseHex(stdout)
+	assert.NoError(t, err)
+	auth := &crypto.AccountAuthenticator{}
+	assert.NoError(t, bcs.Deserialize(auth, authBytes))
+	message, err := rawTxn.SigningMessage()
+	assert.NoError(t, err)
+	assert.True(t, auth.Verify(message))
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, keyFile, account := testKey(t)
+	txnBytes, err := bcs.Serialize(testTransaction(t, account.Address))
+	assert.NoError(t, err)
+	input := endless.BytesToHex(txnBytes)
+
+	// Show only needs no key
+	stdout, stderr, err := runWithInput(t, input, "-show")
+	assert.NoError(t, err)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "Sender:")
+
+	_, _, err = runWithInput(t, input)
+	assert.Error(t, err)
+	_, _, err = runWithInput(t, "0x1234", "-key-file", keyFile)
+	assert.Error(t, err)
+	_, _, err = runWithInput(t, input, "-key", "0x1234")
+	assert.Error(t, err)
+	// Signing as someone other than the sender
+	_, _, err = runWithInput(t, input, "-key-file", keyFile, "-address", "0x2", "-output", "signed")
+	assert.Error(t, err)
+}
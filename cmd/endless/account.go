@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// accountCommand handles: account create, account show
+func (cli *cli) accountCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: account create|show")
+	}
+	switch args[0] {
+	case "create":
+		flags := cli.flagSet("account create")
+		keyType := flags.String("type", string(crypto.PrivateKeyVariantEd25519), "key type: ed25519 or secp256k1")
+		save := flags.String("save", "", "save the key to this profile, with the current profile's network")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		privateKey, err := generateKey(crypto.PrivateKeyVariant(*keyType))
+		if err != nil {
+			return err
+		}
+		account, err := loadAccount(privateKey)
+		if err != nil {
+			return err
+		}
+		out := newResult().
+			add("address", account.Address.String()).
+			add("public_key", account.PubKey().ToHex()).
+			add("private_key", privateKey)
+		if *save != "" {
+			profile, ok := cli.config.Profiles[*save]
+			if !ok {
+				profile = &Profile{Network: cli.profile.Network, NodeUrl: cli.profile.NodeUrl, ChainId: cli.profile.ChainId}
+				cli.config.Profiles[*save] = profile
+			}
+			profile.PrivateKey = privateKey
+			profile.Address = ""
+			if err = cli.config.save(cli.configPath); err != nil {
+				return err
+			}
+			out.add("profile", *save)
+		}
+		return cli.emit(out)
+	case "show":
+		address, err := cli.addressArg(args[1:])
+		if err != nil {
+			return err
+		}
+		client, err := cli.connect()
+		if err != nil {
+			return err
+		}
+		info, err := client.Account(address)
+		if err != nil {
+			return err
+		}
+		sequenceNumber, err := info.SequenceNumber()
+		if err != nil {
+			return err
+		}
+		return cli.emit(newResult().
+			add("address", address.String()).
+			add("sequence_number", sequenceNumber).
+			add("authentication_keys", info.AuthenticationKeyHex).
+			add("num_signatures_required", info.NumSignaturesRequired))
+	default:
+		return fmt.Errorf("unknown account command %s", args[0])
+	}
+}
+
+// generateKey creates a new AIP-80 private key
+func generateKey(keyType crypto.PrivateKeyVariant) (string, error) {
+	switch keyType {
+	case crypto.PrivateKeyVariantEd25519:
+		privateKey, err := crypto.GenerateEd25519PrivateKey()
+		if err != nil {
+			return "", err
+		}
+		return privateKey.ToAIP80()
+	case crypto.PrivateKeyVariantSecp256k1:
+		privateKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			return "", err
+		}
+		return privateKey.ToAIP80()
+	default:
+		return "", fmt.Errorf("unknown key type %s", keyType)
+	}
+}
+
+// balanceCommand handles: balance [-coin address] [address]
+func (cli *cli) balanceCommand(args []string) error {
+	flags := cli.flagSet("balance")
+	coin := flags.String("coin", "", "coin address, defaults to EDS")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	address, err := cli.addressArg(flags.Args())
+	if err != nil {
+		return err
+	}
+	client, err := cli.connect()
+	if err != nil {
+		return err
+	}
+	out := newResult().add("address", address.String())
+	if *coin == "" {
+		balance, err := client.AccountEDSBalance(address)
+		if err != nil {
+			return err
+		}
+		return cli.emit(out.add("coin", "EDS").add("balance", balance.String()))
+	}
+	balance, err := client.AccountCoinBalance(*coin, address)
+	if err != nil {
+		return err
+	}
+	return cli.emit(out.add("coin", *coin).add("balance", balance.String()))
+}
+
+// signingAccount is the profile's account, for commands that submit transactions
+func (cli *cli) signingAccount() (*endless.Account, *endless.Client, error) {
+	account, err := cli.profile.account()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := cli.connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, client, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// Profile is a named network and account to use for commands
+type Profile struct {
+	Network    string `json:"network,omitempty"`     // Network is a named network e.g. testnet, or empty for NodeUrl
+	NodeUrl    string `json:"node_url,omitempty"`    // NodeUrl overrides the node of the network
+	ChainId    uint8  `json:"chain_id,omitempty"`    // ChainId overrides the chain id of the network, fetched if 0
+	PrivateKey string `json:"private_key,omitempty"` // PrivateKey is an AIP-80 private key
+	Address    string `json:"address,omitempty"`     // Address of the account, if it differs from the key's derived address
+}
+
+// Config is the CLI config file, holding named profiles
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// defaultConfigPath is $ENDLESS_CONFIG, or ~/.endless/config.json
+func defaultConfigPath() string {
+	if path := os.Getenv("ENDLESS_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".endless", "config.json")
+	}
+	return filepath.Join(home, ".endless", "config.json")
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: make(map[string]*Profile)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+	return config, nil
+}
+
+// save writes the config file, readable only by the user as it holds private keys
+func (config *Config) save(path string) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// networkConfig resolves the profile to a network for the client
+func (profile *Profile) networkConfig() (endless.NetworkConfig, error) {
+	network := endless.NetworkConfig{}
+	if profile.Network != "" {
+		named, ok := endless.NamedNetworks[profile.Network]
+		if !ok {
+			return network, fmt.Errorf("unknown network %s", profile.Network)
+		}
+		network = named
+	}
+	if profile.NodeUrl != "" {
+		network.NodeUrl = profile.NodeUrl
+	}
+	if profile.ChainId != 0 {
+		network.ChainId = profile.ChainId
+	}
+	if network.NodeUrl == "" {
+		return network, errors.New("profile has no network or node url")
+	}
+	return network, nil
+}
+
+// account loads the profile's account, if it has a private key
+func (profile *Profile) account() (*endless.Account, error) {
+	if profile.PrivateKey == "" {
+		return nil, errors.New("profile has no private key, use account create -save or profile set -private-key")
+	}
+	account, err := loadAccount(profile.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Address != "" {
+		if err = account.Address.ParseStringRelaxed(profile.Address); err != nil {
+			return nil, fmt.Errorf("invalid profile address: %w", err)
+		}
+	}
+	return account, nil
+}
+
+// address is the profile's account address, which doesn't need the private key if the address is set
+func (profile *Profile) address() (endless.AccountAddress, error) {
+	address := endless.AccountAddress{}
+	if profile.Address != "" {
+		err := address.ParseStringRelaxed(profile.Address)
+		return address, err
+	}
+	account, err := profile.account()
+	if err != nil {
+		return address, err
+	}
+	return account.Address, nil
+}
+
+// loadAccount parses an AIP-80 private key into an account
+func loadAccount(key string) (*endless.Account, error) {
+	key = strings.TrimSpace(key)
+	switch {
+	case strings.HasPrefix(key, crypto.AIP80Prefixes[crypto.PrivateKeyVariantEd25519]):
+		privateKey := &crypto.Ed25519PrivateKey{}
+		if err := privateKey.FromHex(key); err != nil {
+			return nil, err
+		}
+		return endless.NewAccountFromSigner(privateKey)
+	case strings.HasPrefix(key, crypto.AIP80Prefixes[crypto.PrivateKeyVariantSecp256k1]):
+		privateKey := &crypto.Secp256k1PrivateKey{}
+		if err := privateKey.FromHex(key); err != nil {
+			return nil, err
+		}
+		return endless.NewAccountFromSigner(crypto.NewSingleSigner(privateKey))
+	default:
+		return nil, errors.New("private key must be AIP-80 e.g. ed25519-priv-0x...")
+	}
+}
+
+// profileCommand handles: profile list, profile set
+func (cli *cli) profileCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: profile list|set")
+	}
+	switch args[0] {
+	case "list":
+		names := make([]string, 0, len(cli.config.Profiles))
+		for name := range cli.config.Profiles {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		profiles := make([]any, len(names))
+		for i, name := range names {
+			profile := cli.config.Profiles[name]
+			address, _ := profile.address()
+			profiles[i] = newResult().
+				add("name", name).
+				add("network", profile.Network).
+				add("node_url", profile.NodeUrl).
+				add("address", address.String()).
+				add("has_key", profile.PrivateKey != "")
+		}
+		return cli.emit(newResult().add("profiles", profiles))
+	case "set":
+		flags := cli.flagSet("profile set")
+		network := flags.String("network", "", "named network e.g. testnet or mainnet")
+		nodeUrl := flags.String("node-url", "", "node url, overrides the network's")
+		chainId := flags.Uint("chain-id", 0, "chain id, overrides the network's")
+		privateKey := flags.String("private-key", "", "AIP-80 private key")
+		address := flags.String("address", "", "account address, if it differs from the key's derived address")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return errors.New("usage: profile set [flags] <name>")
+		}
+		name := flags.Arg(0)
+		profile, ok := cli.config.Profiles[name]
+		if !ok {
+			profile = &Profile{}
+			cli.config.Profiles[name] = profile
+		}
+		var err error
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "network":
+				profile.Network = *network
+			case "node-url":
+				profile.NodeUrl = *nodeUrl
+			case "chain-id":
+				profile.ChainId = uint8(*chainId)
+			case "private-key":
+				if _, err = loadAccount(*privateKey); err == nil {
+					profile.PrivateKey = *privateKey
+				}
+			case "address":
+				profile.Address = *address
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if err = cli.config.save(cli.configPath); err != nil {
+			return err
+		}
+		return cli.emit(newResult().add("profile", name).add("saved", cli.configPath))
+	default:
+		return fmt.Errorf("unknown profile command %s", args[0])
+	}
+}
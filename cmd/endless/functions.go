@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/api"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, " ")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+// parseFunctionId splits "address::module::function"
+func parseFunctionId(functionId string) (endless.AccountAddress, string, string, error) {
+	address := endless.AccountAddress{}
+	parts := strings.Split(functionId, "::")
+	if len(parts) != 3 {
+		return address, "", "", fmt.Errorf("invalid function %s, expected address::module::function", functionId)
+	}
+	if err := address.ParseStringRelaxed(parts[0]); err != nil {
+		return address, "", "", fmt.Errorf("invalid function %s: %w", functionId, err)
+	}
+	return address, parts[1], parts[2], nil
+}
+
+// buildFunction looks up the function's on-chain ABI, and converts the command line type args and args to BCS
+func (cli *cli) buildFunction(functionId string, typeArgs []string, args []string, view bool) (*endless.EntryFunction, error) {
+	address, moduleName, functionName, err := parseFunctionId(functionId)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cli.connect()
+	if err != nil {
+		return nil, err
+	}
+	module, err := client.AccountModule(address, moduleName)
+	if err != nil {
+		return nil, err
+	}
+	if module.Abi == nil {
+		return nil, fmt.Errorf("module %s has no ABI", moduleName)
+	}
+	var function *api.MoveFunction
+	for _, exposed := range module.Abi.ExposedFunctions {
+		if exposed.Name == functionName {
+			function = exposed
+			break
+		}
+	}
+	if function == nil {
+		return nil, fmt.Errorf("function %s not found in module %s", functionName, moduleName)
+	}
+	if view && !function.IsView {
+		return nil, fmt.Errorf("function %s is not a view function", functionId)
+	}
+	if !view && !function.IsEntry {
+		return nil, fmt.Errorf("function %s is not an entry function", functionId)
+	}
+
+	convertedArgs, err := convertArgs(function, args)
+	if err != nil {
+		return nil, err
+	}
+	anyTypeArgs := make([]any, len(typeArgs))
+	for i, typeArg := range typeArgs {
+		anyTypeArgs[i] = typeArg
+	}
+	return endless.EntryFunctionFromAbi(function, address, moduleName, functionName, anyTypeArgs, convertedArgs)
+}
+
+// convertArgs turns command line strings into values [endless.ConvertArg] accepts for the function's parameters.
+// Scalars are passed as strings, vectors are JSON arrays, and vector<u8> is hex.
+func convertArgs(function *api.MoveFunction, args []string) ([]any, error) {
+	params := make([]*endless.TypeTag, 0, len(function.Params))
+	for _, param := range function.Params {
+		typeTag, err := endless.ParseTypeTag(param)
+		if err != nil {
+			return nil, err
+		}
+		if isSigner(typeTag) {
+			continue
+		}
+		params = append(params, typeTag)
+	}
+	if len(args) != len(params) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(params), len(args))
+	}
+
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		vector, ok := params[i].Value.(*endless.VectorTag)
+		if !ok {
+			converted[i] = arg
+			continue
+		}
+		if _, ok = vector.TypeParam.Value.(*endless.U8Tag); ok {
+			converted[i] = arg
+			continue
+		}
+		value, err := convertVectorArg(vector.TypeParam, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		converted[i] = value
+	}
+	return converted, nil
+}
+
+func isSigner(typeTag *endless.TypeTag) bool {
+	switch inner := typeTag.Value.(type) {
+	case *endless.SignerTag:
+		return true
+	case *endless.ReferenceTag:
+		_, ok := inner.TypeParam.Value.(*endless.SignerTag)
+		return ok
+	default:
+		return false
+	}
+}
+
+// convertVectorArg parses a JSON array into the typed slice for the element type
+func convertVectorArg(element endless.TypeTag, arg string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(arg))
+	decoder.UseNumber()
+	items := make([]any, 0)
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("vector arguments must be a JSON array: %w", err)
+	}
+	strs := make([]string, len(items))
+	for i, item := range items {
+		strs[i] = fmt.Sprint(item)
+	}
+
+	switch element.Value.(type) {
+	case *endless.U16Tag:
+		return parseUints(strs, 16, func(v uint64) uint16 { return uint16(v) })
+	case *endless.U32Tag:
+		return parseUints(strs, 32, func(v uint64) uint32 { return uint32(v) })
+	case *endless.U64Tag:
+		return parseUints(strs, 64, func(v uint64) uint64 { return v })
+	case *endless.U128Tag, *endless.U256Tag:
+		out := make([]big.Int, len(strs))
+		for i, str := range strs {
+			if _, ok := out[i].SetString(str, 10); !ok {
+				return nil, fmt.Errorf("invalid integer %s", str)
+			}
+		}
+		return out, nil
+	case *endless.BoolTag:
+		out := make([]bool, len(strs))
+		for i, str := range strs {
+			value, err := strconv.ParseBool(str)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = value
+		}
+		return out, nil
+	case *endless.AddressTag:
+		out := make([]endless.AccountAddress, len(strs))
+		for i, str := range strs {
+			if err := out[i].ParseStringRelaxed(str); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		out := make([]any, len(strs))
+		for i, str := range strs {
+			out[i] = str
+		}
+		return out, nil
+	}
+}
+
+func parseUints[T uint16 | uint32 | uint64](strs []string, bits int, convert func(uint64) T) ([]T, error) {
+	out := make([]T, len(strs))
+	for i, str := range strs {
+		value, err := strconv.ParseUint(str, 10, bits)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = convert(value)
+	}
+	return out, nil
+}
+
+// viewCommand handles: view [-type-arg tag]... <function> [args]
+func (cli *cli) viewCommand(args []string) error {
+	flags := cli.flagSet("view")
+	typeArgs := stringList{}
+	flags.Var(&typeArgs, "type-arg", "type argument, repeat for each")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return errors.New("usage: view [-type-arg tag]... <function> [args]")
+	}
+	entryFunction, err := cli.buildFunction(flags.Arg(0), typeArgs, flags.Args()[1:], true)
+	if err != nil {
+		return err
+	}
+	values, err := cli.client.View(&endless.ViewPayload{
+		Module:   entryFunction.Module,
+		Function: entryFunction.Function,
+		ArgTypes: entryFunction.ArgTypes,
+		Args:     entryFunction.Args,
+	})
+	if err != nil {
+		return err
+	}
+	return cli.emit(newResult().add("function", flags.Arg(0)).add("result", values))
+}
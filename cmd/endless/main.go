@@ -0,0 +1,253 @@
+// endless is a command line interface for the Endless blockchain, built on the Go SDK.
+//
+// Networks and accounts are kept as named profiles in a JSON config file, by default ~/.endless/config.json or
+// $ENDLESS_CONFIG.  Every command can print JSON with -json, for scripting.
+//
+// Usage:
+//
+//	endless [-config path] [-profile name] [-json] <command> [flags] [args]
+//
+// Commands:
+//
+//	profile list                              list the profiles in the config file
+//	profile set [flags] <name>                create or update a profile
+//	account create [-type] [-save profile]    generate a new account key
+//	account show [address]                    show an account's sequence number and authentication keys
+//	balance [-coin address] [address]         show the EDS, or other coin balance of an account
+//	transfer [-coin address] <to> <amount>    transfer EDS or another coin
+//	batch-transfer [-coin address] <csv>      transfer to many accounts from a CSV of address,amount
+//	view <function> [args]                    call a view function e.g. 0x1::account::exists_at
+//	run <function> [args]                     submit an entry function, arguments converted with the on-chain ABI
+//	simulate <function> [args]                simulate an entry function without submitting it
+//	txn show <hash>                           show a transaction
+//	txn wait <hash>                           wait for a transaction to commit
+//	publish <payload.json>                    publish a package from a build-publish-payload JSON file
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk"
+)
+
+// cli is the state shared by every command
+type cli struct {
+	configPath  string
+	config      *Config
+	profileName string
+	profile     *Profile
+	json        bool
+	stdout      io.Writer
+	stderr      io.Writer
+	client      *endless.Client
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "endless:", err)
+		os.Exit(1)
+	}
+}
+
+// run is the whole program, separate from main for testing
+func run(args []string, stdout io.Writer, stderr io.Writer) error {
+	cli := &cli{stdout: stdout, stderr: stderr}
+	flags := cli.flagSet("endless")
+	flags.StringVar(&cli.configPath, "config", defaultConfigPath(), "config file")
+	flags.StringVar(&cli.profileName, "profile", "default", "profile to use")
+	flags.BoolVar(&cli.json, "json", false, "print JSON output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("a command is required")
+	}
+
+	var err error
+	cli.config, err = loadConfig(cli.configPath)
+	if err != nil {
+		return err
+	}
+	cli.profile = cli.config.Profiles[cli.profileName]
+	if cli.profile == nil {
+		if cli.profileName != "default" {
+			return fmt.Errorf("unknown profile %s", cli.profileName)
+		}
+		cli.profile = &Profile{Network: endless.TestnetConfig.Name}
+	}
+
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "profile":
+		return cli.profileCommand(commandArgs)
+	case "account":
+		return cli.accountCommand(commandArgs)
+	case "balance":
+		return cli.balanceCommand(commandArgs)
+	case "transfer":
+		return cli.transferCommand(commandArgs)
+	case "batch-transfer":
+		return cli.batchTransferCommand(commandArgs)
+	case "view":
+		return cli.viewCommand(commandArgs)
+	case "run":
+		return cli.runCommand(commandArgs, false)
+	case "simulate":
+		return cli.runCommand(commandArgs, true)
+	case "txn":
+		return cli.txnCommand(commandArgs)
+	case "publish":
+		return cli.publishCommand(commandArgs)
+	default:
+		return fmt.Errorf("unknown command %s", command)
+	}
+}
+
+// flagSet creates a flag set that reports errors instead of exiting
+func (cli *cli) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cli.stderr)
+	return flags
+}
+
+// connect creates the client for the profile's network
+func (cli *cli) connect() (*endless.Client, error) {
+	if cli.client != nil {
+		return cli.client, nil
+	}
+	network, err := cli.profile.networkConfig()
+	if err != nil {
+		return nil, err
+	}
+	cli.client, err = endless.NewClient(network)
+	return cli.client, err
+}
+
+// addressArg is the address from the args, or the profile's address if there is none
+func (cli *cli) addressArg(args []string) (endless.AccountAddress, error) {
+	if len(args) > 1 {
+		return endless.AccountAddress{}, errors.New("expected at most one address")
+	}
+	if len(args) == 1 {
+		address := endless.AccountAddress{}
+		err := address.ParseStringRelaxed(args[0])
+		return address, err
+	}
+	return cli.profile.address()
+}
+
+// result is an ordered set of output fields
+type result struct {
+	keys   []string
+	values map[string]any
+}
+
+func newResult() *result {
+	return &result{values: make(map[string]any)}
+}
+
+// add adds a field to the result
+func (r *result) add(key string, value any) *result {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+	return r
+}
+
+// MarshalJSON writes the fields in order
+func (r *result) MarshalJSON() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		keyJson, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJson, err := json.Marshal(r.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyJson)
+		buffer.WriteByte(':')
+		buffer.Write(valueJson)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// emit prints the result as JSON, or as readable text
+func (cli *cli) emit(r *result) error {
+	if cli.json {
+		encoder := json.NewEncoder(cli.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+	return writeText(cli.stdout, r, "")
+}
+
+// writeText prints a result as indented "key: value" lines
+func writeText(out io.Writer, r *result, indent string) error {
+	for _, key := range r.keys {
+		var err error
+		switch value := r.values[key].(type) {
+		case *result:
+			if _, err = fmt.Fprintf(out, "%s%s:\n", indent, key); err == nil {
+				err = writeText(out, value, indent+"  ")
+			}
+		case []any:
+			if _, err = fmt.Fprintf(out, "%s%s:\n", indent, key); err != nil {
+				return err
+			}
+			for i, item := range value {
+				if nested, ok := item.(*result); ok {
+					if i > 0 {
+						if _, err = fmt.Fprintln(out); err != nil {
+							return err
+						}
+					}
+					err = writeText(out, nested, indent+"  ")
+				} else {
+					_, err = fmt.Fprintf(out, "%s  %s\n", indent, textValue(item))
+				}
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			_, err = fmt.Fprintf(out, "%s%s: %s\n", indent, key, textValue(value))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textValue formats simple values directly, and anything else as compact JSON
+func textValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	case nil:
+		return ""
+	}
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(valueJson))
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+/* fakeNode is a minimal node API for the commands to talk to */
+
+type fakeNode struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	submitted []*endless.SignedTransaction
+	views     [][]byte
+	hashes    map[string]*endless.SignedTransaction
+}
+
+const testModuleAbi = `{
+  "address": "0x1",
+  "name": "test",
+  "friends": [],
+  "structs": [],
+  "exposed_functions": [
+    {"name": "multi", "visibility": "public", "is_entry": true, "is_view": false, "generic_type_params": [],
+     "params": ["&signer", "u64", "vector<address>", "vector<u8>"], "return": []},
+    {"name": "get", "visibility": "public", "is_entry": false, "is_view": true, "generic_type_params": [],
+     "params": ["address", "vector<u64>"], "return": ["u64"]}
+  ]
+}`
+
+func newFakeNode(t *testing.T) *fakeNode {
+	node := &fakeNode{t: t, hashes: make(map[string]*endless.SignedTransaction)}
+	node.server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+func (node *fakeNode) writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	assert.NoError(node.t, json.NewEncoder(w).Encode(body))
+}
+
+func (node *fakeNode) txnJson(hash string, txn *endless.SignedTransaction) map[string]any {
+	return map[string]any{
+		"type":                      "user_transaction",
+		"hash":                      hash,
+		"sender":                    txn.Transaction.Sender.String(),
+		"sequence_number":           strconv.FormatUint(txn.Transaction.SequenceNumber, 10),
+		"max_gas_amount":            strconv.FormatUint(txn.Transaction.MaxGasAmount, 10),
+		"gas_unit_price":            strconv.FormatUint(txn.Transaction.GasUnitPrice, 10),
+		"expiration_timestamp_secs": strconv.FormatUint(txn.Transaction.ExpirationTimestampSeconds, 10),
+		"version":                   "10",
+		"success":                   true,
+		"vm_status":                 "Executed successfully",
+		"gas_used":                  "7",
+		"timestamp":                 "1700000000000000",
+		"changes":                   []any{},
+		"events":                    []any{},
+	}
+}
+
+func (node *fakeNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		node.writeJson(w, http.StatusOK, map[string]any{"chain_id": 4, "ledger_timestamp": "1700000000000000"})
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/module/test"):
+		node.writeJson(w, http.StatusOK, map[string]any{"bytecode": "0x00", "abi": json.RawMessage(testModuleAbi)})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/accounts/"):
+		node.writeJson(w, http.StatusOK, map[string]any{"sequence_number": "5", "authentication_key": []string{"0x01"}, "num_signatures_required": 1})
+	case r.Method == http.MethodGet && path == "/estimate_gas_price":
+		node.writeJson(w, http.StatusOK, map[string]any{"gas_estimate": 100})
+	case r.Method == http.MethodPost && path == "/view":
+		body, _ := io.ReadAll(r.Body)
+		node.views = append(node.views, body)
+		node.writeJson(w, http.StatusOK, []any{"1000"})
+	case r.Method == http.MethodPost && (path == "/transactions" || path == "/transactions/simulate"):
+		body, _ := io.ReadAll(r.Body)
+		signedTxn := &endless.SignedTransaction{}
+		assert.NoError(node.t, bcs.Deserialize(signedTxn, body))
+		if path == "/transactions/simulate" {
+			node.writeJson(w, http.StatusOK, []any{node.txnJson("0xsimulated", signedTxn)})
+			return
+		}
+		hash, err := signedTxn.Hash()
+		assert.NoError(node.t, err)
+		node.submitted = append(node.submitted, signedTxn)
+		node.hashes[hash] = signedTxn
+		out := node.txnJson(hash, signedTxn)
+		out["type"] = "pending_transaction"
+		node.writeJson(w, http.StatusAccepted, out)
+	case r.Method == http.MethodGet && (strings.HasPrefix(path, "/transactions/by_hash/") || strings.HasPrefix(path, "/transactions/wait_by_hash/")):
+		hash := path[strings.LastIndex(path, "/")+1:]
+		txn, ok := node.hashes[hash]
+		if !ok {
+			node.writeJson(w, http.StatusNotFound, map[string]any{"message": "not found", "error_code": "transaction_not_found"})
+			return
+		}
+		node.writeJson(w, http.StatusOK, node.txnJson(hash, txn))
+	default:
+		node.writeJson(w, http.StatusNotFound, map[string]any{"message": "not found " + path, "error_code": "web_framework_error"})
+	}
+}
+
+// runCli runs the command with a config in a temporary directory, returning stdout
+func runCli(t *testing.T, configPath string, args ...string) (string, error) {
+	stdout := &bytes.Buffer{}
+	err := run(append([]string{"-config", configPath}, args...), stdout, io.Discard)
+	return stdout.String(), err
+}
+
+// setupProfile creates a default profile with a new account on the fake node
+func setupProfile(t *testing.T, node *fakeNode) (string, *endless.Account) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	_, err := runCli(t, configPath, "profile", "set", "-node-url", node.server.URL+"/v1", "-chain-id", "4", "default")
+	assert.NoError(t, err)
+	out, err := runCli(t, configPath, "-json", "account", "create", "-save", "default")
+	assert.NoError(t, err)
+	created := map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &created))
+	account, err := loadAccount(created["private_key"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address.String(), created["address"])
+	return configPath, account
+}
+
+func TestProfile(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, account := setupProfile(t, node)
+
+	info, err := os.Stat(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	out, err := runCli(t, configPath, "-json", "profile", "list")
+	assert.NoError(t, err)
+	listed := struct {
+		Profiles []map[string]any `json:"profiles"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &listed))
+	assert.Len(t, listed.Profiles, 1)
+	assert.Equal(t, "default", listed.Profiles[0]["name"])
+	assert.Equal(t, account.Address.String(), listed.Profiles[0]["address"])
+	assert.Equal(t, true, listed.Profiles[0]["has_key"])
+
+	_, err = runCli(t, configPath, "-profile", "missing", "balance")
+	assert.ErrorContains(t, err, "unknown profile missing")
+	_, err = runCli(t, configPath, "profile", "set", "-private-key", "0x1234", "other")
+	assert.Error(t, err)
+}
+
+func TestAccountAndBalance(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, account := setupProfile(t, node)
+
+	out, err := runCli(t, configPath, "account", "show")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "address: "+account.Address.String())
+	assert.Contains(t, out, "sequence_number: 5")
+
+	out, err = runCli(t, configPath, "-json", "balance", "0x1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"address":"0x1","coin":"EDS","balance":"1000"}`, out)
+	assert.Len(t, node.views, 1)
+}
+
+func TestTransfer(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, account := setupProfile(t, node)
+
+	out, err := runCli(t, configPath, "-json", "transfer", "-wait", "0x1", "100")
+	assert.NoError(t, err)
+	transferred := map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &transferred))
+	assert.Equal(t, true, transferred["success"])
+	assert.Equal(t, account.Address.String(), transferred["sender"])
+
+	assert.Len(t, node.submitted, 1)
+	payload := node.submitted[0].Transaction.Payload.Payload.(*endless.EntryFunction)
+	assert.Equal(t, "transfer", payload.Function)
+	assert.Equal(t, uint64(5), node.submitted[0].Transaction.SequenceNumber)
+
+	hash := transferred["hash"].(string)
+	out, err = runCli(t, configPath, "txn", "show", hash)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "type: user_transaction")
+	assert.Contains(t, out, "vm_status: Executed successfully")
+}
+
+func TestBatchTransfer(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, _ := setupProfile(t, node)
+
+	csv := "address,amount\n0x1,10\n0x2,20\n\n0x3,30\n"
+	csvPath := filepath.Join(t.TempDir(), "transfers.csv")
+	assert.NoError(t, os.WriteFile(csvPath, []byte(csv), 0600))
+
+	out, err := runCli(t, configPath, "-json", "batch-transfer", "-chunk", "2", csvPath)
+	assert.NoError(t, err)
+	batches := struct {
+		Transactions []map[string]any `json:"transactions"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &batches))
+	assert.Len(t, batches.Transactions, 2)
+	assert.Equal(t, float64(2), batches.Transactions[0]["recipients"])
+	assert.Equal(t, float64(1), batches.Transactions[1]["recipients"])
+
+	assert.Len(t, node.submitted, 2)
+	assert.Equal(t, uint64(5), node.submitted[0].Transaction.SequenceNumber)
+	assert.Equal(t, uint64(6), node.submitted[1].Transaction.SequenceNumber)
+
+	assert.NoError(t, os.WriteFile(csvPath, []byte("0x1,10\n0x2,lots\n"), 0600))
+	_, err = runCli(t, configPath, "batch-transfer", csvPath)
+	assert.ErrorContains(t, err, "invalid amount")
+}
+
+func TestRunAndView(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, _ := setupProfile(t, node)
+
+	_, err := runCli(t, configPath, "run", "0x1::test::multi", "7", `["0x1","0x2"]`, "0xabcd")
+	assert.NoError(t, err)
+	assert.Len(t, node.submitted, 1)
+	payload := node.submitted[0].Transaction.Payload.Payload.(*endless.EntryFunction)
+	assert.Equal(t, "multi", payload.Function)
+	amount, err := bcs.SerializeU64(7)
+	assert.NoError(t, err)
+	addresses, err := bcs.SerializeSequenceOnly([]endless.AccountAddress{endless.AccountOne, endless.AccountTwo})
+	assert.NoError(t, err)
+	data, err := bcs.SerializeBytes([]byte{0xab, 0xcd})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{amount, addresses, data}, payload.Args)
+
+	out, err := runCli(t, configPath, "-json", "simulate", "0x1::test::multi", "7", `["0x1"]`, "0x")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"gas_used": 7`)
+	assert.Len(t, node.submitted, 1)
+
+	_, err = runCli(t, configPath, "run", "0x1::test::multi", "7")
+	assert.ErrorContains(t, err, "expected 3 arguments")
+	_, err = runCli(t, configPath, "run", "0x1::test::get", "0x1", "[1]")
+	assert.ErrorContains(t, err, "not an entry function")
+
+	out, err = runCli(t, configPath, "-json", "view", "0x1::test::get", "0x1", "[1,2]")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"function":"0x1::test::get","result":["1000"]}`, out)
+	numbers, err := bcs.SerializeSingle(func(ser *bcs.Serializer) {
+		ser.Uleb128(2)
+		ser.U64(1)
+		ser.U64(2)
+	})
+	assert.NoError(t, err)
+	numbersArg, err := bcs.SerializeBytes(numbers)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasSuffix(node.views[0], numbersArg))
+}
+
+func TestPublish(t *testing.T) {
+	node := newFakeNode(t)
+	configPath, _ := setupProfile(t, node)
+
+	payloadPath := filepath.Join(t.TempDir(), "payload.json")
+	payload := `{"function_id":"0x1::code::publish_package_txn","type_args":[],"args":[` +
+		`{"type":"hex","value":"0x0102"},{"type":"hex","value":["0xa1","0xb2b3"]}]}`
+	assert.NoError(t, os.WriteFile(payloadPath, []byte(payload), 0600))
+
+	out, err := runCli(t, configPath, "publish", payloadPath)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "modules: 2")
+	assert.Len(t, node.submitted, 1)
+	entryFunction := node.submitted[0].Transaction.Payload.Payload.(*endless.EntryFunction)
+	assert.Equal(t, "publish_package_txn", entryFunction.Function)
+	expected, err := endless.PublishPackagePayloadFromJsonFile([]byte{1, 2}, [][]byte{{0xa1}, {0xb2, 0xb3}})
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Payload.(*endless.EntryFunction).Args, entryFunction.Args)
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk"
+	"github.com/endless-labs/endless-go-sdk/api"
+)
+
+// txnFlags are the flags shared by commands that submit transactions
+type txnFlags struct {
+	maxGas       uint64
+	gasUnitPrice uint64
+	wait         bool
+}
+
+// register adds the transaction flags to the flag set
+func (f *txnFlags) register(flags *flag.FlagSet) {
+	flags.Uint64Var(&f.maxGas, "max-gas", 0, "max gas amount, defaults to the SDK default")
+	flags.Uint64Var(&f.gasUnitPrice, "gas-unit-price", 0, "gas unit price, estimated if not set")
+	flags.BoolVar(&f.wait, "wait", false, "wait for the transaction to commit")
+}
+
+// options converts the flags to client build options
+func (f *txnFlags) options() []any {
+	options := make([]any, 0, 2)
+	if f.maxGas != 0 {
+		options = append(options, endless.MaxGasAmount(f.maxGas))
+	}
+	if f.gasUnitPrice != 0 {
+		options = append(options, endless.GasUnitPrice(f.gasUnitPrice))
+	}
+	return options
+}
+
+// submit builds, signs and submits the payload, optionally waiting for it to commit
+func (cli *cli) submit(account *endless.Account, payload endless.TransactionPayload, flags *txnFlags, options ...any) (*result, error) {
+	client, err := cli.connect()
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, flags.options()...)
+	submitted, err := client.BuildSignAndSubmitTransaction(account, payload, options...)
+	if err != nil {
+		return nil, err
+	}
+	out := newResult().
+		add("hash", submitted.Hash).
+		add("sender", account.Address.String()).
+		add("sequence_number", submitted.SequenceNumber)
+	if !flags.wait {
+		return out, nil
+	}
+	committed, err := client.WaitForTransaction(submitted.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s: %w", submitted.Hash, err)
+	}
+	return out.
+		add("success", committed.Success).
+		add("vm_status", committed.VmStatus).
+		add("gas_used", committed.GasUsed).
+		add("version", committed.Version), nil
+}
+
+// coinFlag is the -coin flag as the optional coin address for the coin payloads
+func coinFlag(coin string) *string {
+	if coin == "" {
+		return nil
+	}
+	return &coin
+}
+
+// transferCommand handles: transfer [-coin address] <to> <amount>
+func (cli *cli) transferCommand(args []string) error {
+	flags := cli.flagSet("transfer")
+	coin := flags.String("coin", "", "coin address, defaults to EDS")
+	txn := &txnFlags{}
+	txn.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.New("usage: transfer [-coin address] <to> <amount>")
+	}
+	dest := endless.AccountAddress{}
+	if err := dest.ParseStringRelaxed(flags.Arg(0)); err != nil {
+		return fmt.Errorf("invalid destination %s: %w", flags.Arg(0), err)
+	}
+	amount, err := strconv.ParseUint(flags.Arg(1), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s: %w", flags.Arg(1), err)
+	}
+	account, _, err := cli.signingAccount()
+	if err != nil {
+		return err
+	}
+	payload, err := endless.CoinTransferPayload(coinFlag(*coin), dest, amount)
+	if err != nil {
+		return err
+	}
+	out, err := cli.submit(account, endless.TransactionPayload{Payload: payload}, txn)
+	if err != nil {
+		return err
+	}
+	return cli.emit(out)
+}
+
+// readTransfers reads address,amount lines, skipping blank lines, # comments and a header line
+func readTransfers(path string) ([]endless.AccountAddress, []uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	dests := make([]endless.AccountAddress, 0)
+	amounts := make([]uint64, 0)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("%s:%d: expected address,amount", path, lineNumber)
+		}
+		dest := endless.AccountAddress{}
+		if err = dest.ParseStringRelaxed(strings.TrimSpace(fields[0])); err != nil {
+			if lineNumber == 1 && len(dests) == 0 {
+				continue // header
+			}
+			return nil, nil, fmt.Errorf("%s:%d: invalid address: %w", path, lineNumber, err)
+		}
+		amount, err := strconv.ParseUint(strings.TrimSpace(fields[1]), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: invalid amount: %w", path, lineNumber, err)
+		}
+		dests = append(dests, dest)
+		amounts = append(amounts, amount)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(dests) == 0 {
+		return nil, nil, fmt.Errorf("%s has no transfers", path)
+	}
+	return dests, amounts, nil
+}
+
+// batchTransferCommand handles: batch-transfer [-coin address] [-chunk n] <csv>
+//
+// Transfers are split into batch transactions of at most chunk recipients, submitted with consecutive sequence numbers.
+func (cli *cli) batchTransferCommand(args []string) error {
+	flags := cli.flagSet("batch-transfer")
+	coin := flags.String("coin", "", "coin address, defaults to EDS")
+	chunk := flags.Int("chunk", 100, "recipients per transaction")
+	txn := &txnFlags{}
+	txn.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *chunk < 1 {
+		return errors.New("usage: batch-transfer [-coin address] [-chunk n] <csv>")
+	}
+	dests, amounts, err := readTransfers(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	account, client, err := cli.signingAccount()
+	if err != nil {
+		return err
+	}
+	info, err := client.Account(account.Address)
+	if err != nil {
+		return err
+	}
+	sequenceNumber, err := info.SequenceNumber()
+	if err != nil {
+		return err
+	}
+
+	transactions := make([]any, 0, (len(dests)+*chunk-1) / *chunk)
+	for start := 0; start < len(dests); start += *chunk {
+		end := min(start+*chunk, len(dests))
+		payload, err := endless.CoinBatchTransferPayload(coinFlag(*coin), dests[start:end], amounts[start:end])
+		if err != nil {
+			return err
+		}
+		out, err := cli.submit(account, endless.TransactionPayload{Payload: payload}, txn, endless.SequenceNumber(sequenceNumber))
+		if err != nil {
+			return fmt.Errorf("transfers %d to %d: %w", start, end-1, err)
+		}
+		transactions = append(transactions, out.add("recipients", end-start))
+		sequenceNumber++
+	}
+	return cli.emit(newResult().add("transactions", transactions))
+}
+
+// runCommand handles: run|simulate [-type-arg tag]... <function> [args]
+func (cli *cli) runCommand(args []string, simulate bool) error {
+	name := "run"
+	if simulate {
+		name = "simulate"
+	}
+	flags := cli.flagSet(name)
+	typeArgs := stringList{}
+	flags.Var(&typeArgs, "type-arg", "type argument, repeat for each")
+	txn := &txnFlags{}
+	txn.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return fmt.Errorf("usage: %s [-type-arg tag]... <function> [args]", name)
+	}
+	account, client, err := cli.signingAccount()
+	if err != nil {
+		return err
+	}
+	entryFunction, err := cli.buildFunction(flags.Arg(0), typeArgs, flags.Args()[1:], false)
+	if err != nil {
+		return err
+	}
+	payload := endless.TransactionPayload{Payload: entryFunction}
+
+	if !simulate {
+		out, err := cli.submit(account, payload, txn)
+		if err != nil {
+			return err
+		}
+		return cli.emit(out)
+	}
+	rawTxn, err := client.BuildTransaction(account.Address, payload, txn.options()...)
+	if err != nil {
+		return err
+	}
+	simulated, err := client.SimulateTransaction(rawTxn, account)
+	if err != nil {
+		return err
+	}
+	if len(simulated) == 0 {
+		return errors.New("simulation returned no transactions")
+	}
+	return cli.emit(newResult().
+		add("function", flags.Arg(0)).
+		add("success", simulated[0].Success).
+		add("vm_status", simulated[0].VmStatus).
+		add("gas_used", simulated[0].GasUsed).
+		add("gas_unit_price", simulated[0].GasUnitPrice))
+}
+
+// txnCommand handles: txn show <hash>, txn wait [-timeout duration] <hash>
+func (cli *cli) txnCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: txn show|wait")
+	}
+	switch args[0] {
+	case "show":
+		if len(args) != 2 {
+			return errors.New("usage: txn show <hash>")
+		}
+		client, err := cli.connect()
+		if err != nil {
+			return err
+		}
+		txn, err := client.TransactionByHash(args[1])
+		if err != nil {
+			return err
+		}
+		out := newResult().add("type", string(txn.Type)).add("hash", txn.Hash())
+		switch inner := txn.Inner.(type) {
+		case *api.UserTransaction:
+			return cli.emit(userTransactionResult(out, inner))
+		case *api.PendingTransaction:
+			return cli.emit(out.
+				add("sender", inner.Sender.String()).
+				add("sequence_number", inner.SequenceNumber).
+				add("payload", inner.Payload))
+		default:
+			if success := txn.Success(); success != nil {
+				out.add("success", *success)
+			}
+			if version := txn.Version(); version != nil {
+				out.add("version", *version)
+			}
+			return cli.emit(out)
+		}
+	case "wait":
+		flags := cli.flagSet("txn wait")
+		timeout := flags.Duration("timeout", 10*time.Second, "how long to wait")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return errors.New("usage: txn wait [-timeout duration] <hash>")
+		}
+		client, err := cli.connect()
+		if err != nil {
+			return err
+		}
+		txn, err := client.WaitForTransaction(flags.Arg(0), endless.PollTimeout(*timeout))
+		if err != nil {
+			return err
+		}
+		return cli.emit(userTransactionResult(newResult().add("type", string(api.TransactionVariantUser)).add("hash", txn.Hash), txn))
+	default:
+		return fmt.Errorf("unknown txn command %s", args[0])
+	}
+}
+
+// userTransactionResult adds the committed transaction's fields to the result
+func userTransactionResult(out *result, txn *api.UserTransaction) *result {
+	return out.
+		add("version", txn.Version).
+		add("success", txn.Success).
+		add("vm_status", txn.VmStatus).
+		add("sender", txn.Sender.String()).
+		add("sequence_number", txn.SequenceNumber).
+		add("gas_used", txn.GasUsed).
+		add("gas_unit_price", txn.GasUnitPrice).
+		add("timestamp", txn.Timestamp).
+		add("payload", txn.Payload).
+		add("events", len(txn.Events))
+}
+
+// publishPayload is the JSON file written by `endless move build-publish-payload`
+type publishPayload struct {
+	FunctionId string            `json:"function_id"`
+	TypeArgs   []string          `json:"type_args"`
+	Args       []json.RawMessage `json:"args"`
+}
+
+// publishArg is an argument in the publish payload, the value is a hex string or an array of hex strings
+type publishArg struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// readPublishPayload reads the package metadata and module bytecode from a publish payload file
+func readPublishPayload(path string) ([]byte, [][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload := &publishPayload{}
+	if err = json.Unmarshal(data, payload); err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload %s: %w", path, err)
+	}
+	if len(payload.Args) != 2 {
+		return nil, nil, fmt.Errorf("invalid publish payload %s: expected 2 args, got %d", path, len(payload.Args))
+	}
+	metadataArg := &publishArg{}
+	codeArg := &publishArg{}
+	if err = json.Unmarshal(payload.Args[0], metadataArg); err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload metadata: %w", err)
+	}
+	if err = json.Unmarshal(payload.Args[1], codeArg); err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload code: %w", err)
+	}
+
+	metadataHex := ""
+	if err = json.Unmarshal(metadataArg.Value, &metadataHex); err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload metadata: %w", err)
+	}
+	metadata, err := endless.ParseHex(metadataHex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload metadata: %w", err)
+	}
+	codeHex := make([]string, 0)
+	if err = json.Unmarshal(codeArg.Value, &codeHex); err != nil {
+		return nil, nil, fmt.Errorf("invalid publish payload code: %w", err)
+	}
+	code := make([][]byte, len(codeHex))
+	for i, moduleHex := range codeHex {
+		if code[i], err = endless.ParseHex(moduleHex); err != nil {
+			return nil, nil, fmt.Errorf("invalid publish payload module %d: %w", i, err)
+		}
+	}
+	return metadata, code, nil
+}
+
+// publishCommand handles: publish <payload.json>
+func (cli *cli) publishCommand(args []string) error {
+	flags := cli.flagSet("publish")
+	txn := &txnFlags{}
+	txn.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: publish <payload.json>")
+	}
+	metadata, code, err := readPublishPayload(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	account, _, err := cli.signingAccount()
+	if err != nil {
+		return err
+	}
+	payload, err := endless.PublishPackagePayloadFromJsonFile(metadata, code)
+	if err != nil {
+		return err
+	}
+	out, err := cli.submit(account, *payload, txn)
+	if err != nil {
+		return err
+	}
+	return cli.emit(out.add("modules", len(code)))
+}
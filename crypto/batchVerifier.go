@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// minEd25519Batch is the fewest Ed25519 signatures worth batching, fewer are checked one by one
+const minEd25519Batch = 4
+
+//region BatchVerifier
+
+// BatchVerifier verifies many authenticators at once, e.g. a relayer checking incoming transactions before
+// forwarding them.
+//
+// Ed25519 signatures, on their own or in a [SingleKeyAuthenticator], are verified in batches with
+// [ed25519consensus.BatchVerifier], which is much faster than one by one.  When a batch fails, each of its signatures
+// is checked on its own to find the invalid ones.  All other signatures, e.g. Secp256k1, are checked one by one, in
+// parallel across cores.  The results are the same as [AccountAuthenticator.Verify] for each.
+//
+//	verifier := crypto.NewBatchVerifier()
+//	for _, item := range items {
+//		verifier.Add(item.Auth, item.Message)
+//	}
+//	valid := verifier.Verify()
+type BatchVerifier struct {
+	Workers int // Workers is the number of goroutines verifying, runtime.NumCPU() if 0
+	items   []batchItem
+}
+
+// batchItem is an authenticator and the messages it may have signed, valid if it signed any of them
+type batchItem struct {
+	auth     *AccountAuthenticator
+	messages [][]byte
+}
+
+// NewBatchVerifier creates an empty [BatchVerifier]
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add adds an authenticator to verify, returning its index in the results of [BatchVerifier.Verify].  It's valid if
+// it signed any of the messages.
+func (v *BatchVerifier) Add(auth *AccountAuthenticator, messages ...[]byte) int {
+	v.items = append(v.items, batchItem{auth: auth, messages: messages})
+	return len(v.items) - 1
+}
+
+// Len is the number of authenticators added
+func (v *BatchVerifier) Len() int {
+	return len(v.items)
+}
+
+// Verify verifies every authenticator added, returning whether each is valid, in the order added
+func (v *BatchVerifier) Verify() []bool {
+	results := make([]bool, len(v.items))
+	ed25519Items := make([]int, 0, len(v.items))
+	otherItems := make([]int, 0)
+	for i, item := range v.items {
+		if key, _ := batchEd25519(item.auth); key != nil && len(item.messages) == 1 {
+			ed25519Items = append(ed25519Items, i)
+		} else {
+			otherItems = append(otherItems, i)
+		}
+	}
+
+	workers := v.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	tasks := make(chan func(), workers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range tasks {
+				task()
+				wg.Done()
+			}
+		}()
+	}
+
+	// Split the Ed25519 signatures into a batch per worker, a failure then only falls back for its own batch
+	chunkSize := (len(ed25519Items) + workers - 1) / workers
+	if chunkSize < minEd25519Batch {
+		chunkSize = minEd25519Batch
+	}
+	for start := 0; start < len(ed25519Items); start += chunkSize {
+		chunk := ed25519Items[start:min(start+chunkSize, len(ed25519Items))]
+		wg.Add(1)
+		tasks <- func() { v.verifyEd25519(chunk, results) }
+	}
+	for _, i := range otherItems {
+		wg.Add(1)
+		tasks <- func() { results[i] = v.items[i].verify() }
+	}
+	wg.Wait()
+	close(tasks)
+	return results
+}
+
+// verifyEd25519 verifies the Ed25519 items as a batch, falling back to each on its own if the batch fails
+func (v *BatchVerifier) verifyEd25519(indices []int, results []bool) {
+	if len(indices) >= minEd25519Batch {
+		batch := ed25519consensus.NewPreallocatedBatchVerifier(len(indices))
+		for _, i := range indices {
+			key, sig := batchEd25519(v.items[i].auth)
+			batch.Add(key.Inner, v.items[i].messages[0], sig.Bytes())
+		}
+		if batch.Verify() {
+			for _, i := range indices {
+				results[i] = true
+			}
+			return
+		}
+	}
+	for _, i := range indices {
+		results[i] = v.items[i].verify()
+	}
+}
+
+// verify checks the authenticator signed any of the messages
+func (item *batchItem) verify() bool {
+	if item.auth == nil || item.auth.Auth == nil {
+		return false
+	}
+	for _, message := range item.messages {
+		if item.auth.Verify(message) {
+			return true
+		}
+	}
+	return false
+}
+
+//endregion
+
+// batchEd25519 returns the Ed25519 key and signature of the authenticator, or nil if it isn't a single Ed25519
+// signature
+func batchEd25519(auth *AccountAuthenticator) (*Ed25519PublicKey, *Ed25519Signature) {
+	if auth == nil {
+		return nil, nil
+	}
+	var key, sig any
+	switch inner := auth.Auth.(type) {
+	case *Ed25519Authenticator:
+		key, sig = inner.PubKey, inner.Sig
+	case *SingleKeyAuthenticator:
+		if inner.PubKey == nil || inner.Sig == nil {
+			return nil, nil
+		}
+		key, sig = inner.PubKey.PubKey, inner.Sig.Signature
+	default:
+		return nil, nil
+	}
+	ed25519Key, ok := key.(*Ed25519PublicKey)
+	if !ok || ed25519Key == nil {
+		return nil, nil
+	}
+	ed25519Sig, ok := sig.(*Ed25519Signature)
+	if !ok || ed25519Sig == nil {
+		return nil, nil
+	}
+	return ed25519Key, ed25519Sig
+}
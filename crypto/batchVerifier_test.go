@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	signers := make([]Signer, 0)
+	for i := 0; i < 12; i++ {
+		ed25519Key, err := GenerateEd25519PrivateKey()
+		assert.NoError(t, err)
+		signers = append(signers, ed25519Key, NewSingleSigner(ed25519Key))
+	}
+	for i := 0; i < 4; i++ {
+		secp256k1Key, err := GenerateSecp256k1Key()
+		assert.NoError(t, err)
+		signers = append(signers, NewSingleSigner(secp256k1Key))
+	}
+
+	auths := make([]*AccountAuthenticator, len(signers))
+	messages := make([][]byte, len(signers))
+	for i, signer := range signers {
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+		auth, err := signer.Sign(messages[i])
+		assert.NoError(t, err)
+		auths[i] = auth
+	}
+
+	// All valid, in batches
+	for _, workers := range []int{0, 1, 3} {
+		verifier := &BatchVerifier{Workers: workers}
+		for i, auth := range auths {
+			assert.Equal(t, i, verifier.Add(auth, messages[i]))
+		}
+		assert.Equal(t, len(auths), verifier.Len())
+		for i, valid := range verifier.Verify() {
+			assert.True(t, valid, "authenticator %d", i)
+		}
+	}
+
+	// Invalid signatures are found in a failed batch, of every key type, and any of several messages may be signed
+	invalid := map[int]bool{3: true, 10: true, 25: true, 26: true}
+	verifier := NewBatchVerifier()
+	for i, auth := range auths {
+		switch {
+		case invalid[i]:
+			verifier.Add(auth, []byte("another message"))
+		case i == 5:
+			verifier.Add(auth, []byte("another message"), messages[i])
+		default:
+			verifier.Add(auth, messages[i])
+		}
+	}
+	verifier.Add(nil, messages[0])
+	results := verifier.Verify()
+	assert.Len(t, results, len(auths)+1)
+	for i := range auths {
+		assert.Equal(t, !invalid[i], results[i], "authenticator %d", i)
+		assert.Equal(t, auths[i].Verify(messages[i]), results[i] || invalid[i])
+	}
+	assert.False(t, results[len(auths)])
+
+	assert.Empty(t, NewBatchVerifier().Verify())
+}
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+//region Derivation
+
+// HardenedOffset is added to a path index to make it hardened, written as ' or H in a path
+const HardenedOffset = uint32(0x80000000)
+
+// Ed25519DerivationPath is the path of the Ed25519 key for a BIP-44 coin type and account index,
+// m/44'/coinType'/account'/0'/0'.  No SLIP-0044 coin type is assigned to Endless, so pass the one used by the wallet
+// whose accounts are being derived.
+func Ed25519DerivationPath(coinType uint32, account uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0'/0'", coinType, account)
+}
+
+// Secp256k1DerivationPath is the path of the Secp256k1 key for a BIP-44 coin type and account index,
+// m/44'/coinType'/account'/0/0.  As for [Ed25519DerivationPath], the coin type is the wallet's.
+func Secp256k1DerivationPath(coinType uint32, account uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0/0", coinType, account)
+}
+
+// ParseDerivationPath parses a BIP-32 path e.g. m/44'/1'/0'/0'/0' into indexes, with [HardenedOffset] added to
+// hardened indexes.  Hardened indexes may be marked with ' or H.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %s, must start with m", path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "H")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %s: %w", path, err)
+		}
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// IsHardenedPath is true if every index in the path is hardened, as needed for Ed25519
+func IsHardenedPath(path string) bool {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil || len(indexes) == 0 {
+		return false
+	}
+	for _, index := range indexes {
+		if index < HardenedOffset {
+			return false
+		}
+	}
+	return true
+}
+
+// extendedKey is a private key and chain code
+type extendedKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// masterKey derives the master extended key from a seed with the curve's HMAC key
+func masterKey(curve string, seed []byte) extendedKey {
+	mac := hmac.New(sha512.New, []byte(curve))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return extendedKey{key: sum[:32], chainCode: sum[32:]}
+}
+
+// childHmac is HMAC-SHA512(chainCode, data || index)
+func (parent extendedKey) childHmac(data []byte, index uint32) []byte {
+	mac := hmac.New(sha512.New, parent.chainCode)
+	mac.Write(data)
+	_ = binary.Write(mac, binary.BigEndian, index)
+	return mac.Sum(nil)
+}
+
+// DeriveEd25519PrivateKey derives an Ed25519 private key from a BIP-39 seed with SLIP-0010.  Every index in the
+// path must be hardened.
+func DeriveEd25519PrivateKey(seed []byte, path string) (*Ed25519PrivateKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := masterKey("ed25519 seed", seed)
+	for _, index := range indexes {
+		if index < HardenedOffset {
+			return nil, fmt.Errorf("invalid derivation path %s, ed25519 only supports hardened indexes", path)
+		}
+		sum := key.childHmac(append([]byte{0}, key.key...), index)
+		key = extendedKey{key: sum[:32], chainCode: sum[32:]}
+	}
+	privateKey := &Ed25519PrivateKey{}
+	if err = privateKey.FromBytes(key.key); err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// DeriveSecp256k1PrivateKey derives a Secp256k1 private key from a BIP-39 seed with BIP-32
+func DeriveSecp256k1PrivateKey(seed []byte, path string) (*Secp256k1PrivateKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := masterKey("Bitcoin seed", seed)
+	scalar := &secp256k1.ModNScalar{}
+	if overflow := scalar.SetByteSlice(key.key); overflow || scalar.IsZero() {
+		return nil, errors.New("invalid master key, use another seed")
+	}
+	for _, index := range indexes {
+		var data []byte
+		if index >= HardenedOffset {
+			data = append([]byte{0}, key.key...)
+		} else {
+			data = secp256k1.NewPrivateKey(scalar).PubKey().SerializeCompressed()
+		}
+		sum := key.childHmac(data, index)
+
+		// The child key is parent + IL mod n, which BIP-32 says to skip if IL >= n or the key is 0
+		tweak := &secp256k1.ModNScalar{}
+		if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+			return nil, fmt.Errorf("invalid child key at index %d, use the next index", index)
+		}
+		scalar.Add(tweak)
+		if scalar.IsZero() {
+			return nil, fmt.Errorf("invalid child key at index %d, use the next index", index)
+		}
+		keyBytes := scalar.Bytes()
+		key = extendedKey{key: keyBytes[:], chainCode: sum[32:]}
+	}
+	return &Secp256k1PrivateKey{Inner: secp256k1.NewPrivateKey(scalar)}, nil
+}
+
+//endregion
@@ -0,0 +1,332 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk/internal/util"
+)
+
+// keyShareVersion is the version of the [KeyShare] encoding
+const keyShareVersion = 1
+
+// keyShareFlagSingleKey marks a share of a [SingleSigner]'s key, rather than a legacy [Ed25519PrivateKey]
+const keyShareFlagSingleKey = 0x01
+
+// keyShareSecretLength is the length of the private keys shared, both [Ed25519PrivateKeyLength] and
+// [Secp256k1PrivateKeyLength]
+const keyShareSecretLength = 32
+
+// keyShareChecksumLength is the length of the checksum at the end of an encoded [KeyShare]
+const keyShareChecksumLength = 4
+
+// KeySharePrefixes contains the prefixes of encoded [KeyShare]s for each private key type, in the style of AIP-80
+var KeySharePrefixes = map[PrivateKeyVariant]string{
+	PrivateKeyVariantEd25519:   "ed25519-share-",
+	PrivateKeyVariantSecp256k1: "secp256k1-share-",
+}
+
+// ErrKeyShareAuthKey is returned when the private key recombined from [KeyShare]s doesn't derive the expected
+// authentication key, e.g. because shares of different keys were mixed
+var ErrKeyShareAuthKey = errors.New("recombined key does not match the authentication key")
+
+//region KeyShare
+
+// KeyShare is one of N shares of a private key, any K of which recombine into the key with [CombineKeyShares], using
+// Shamir's secret sharing over GF(256).  Fewer than K shares reveal nothing about the key.
+//
+// Shares are encoded as strings like "ed25519-share-0x...", with a checksum to catch transcription errors.  Each
+// includes the first bytes of the key's authentication key, which is also the start of the account's address unless
+// rotated, so the recombined key can be checked.
+//
+//	shares, err := crypto.SplitPrivateKey(account.Signer, 3, 5)
+//	encoded := shares[0].String() // for the first custodian
+//	...
+//	share, err := crypto.ParseKeyShare(encoded)
+//	signer, err := crypto.CombineKeyShares(share, share2, share3)
+type KeyShare struct {
+	Variant     PrivateKeyVariant // Variant is the type of the private key
+	SingleKey   bool              // SingleKey is true if the key is of a [SingleSigner], always true for Secp256k1
+	Fingerprint [4]byte           // Fingerprint is the first 4 bytes of the key's [AuthenticationKey]
+	Threshold   uint8             // Threshold is the number of shares needed to recombine the key
+	Index       uint8             // Index is the share's x coordinate, 1-255
+	Value       []byte            // Value is the share of each byte of the key
+}
+
+// SplitPrivateKey splits a signer's private key into shares, any threshold of which recombine into the signer.  The
+// signer may be an [Ed25519PrivateKey], or a [SingleSigner] of an Ed25519 or Secp256k1 key.  The threshold must be at
+// least 2, and at most shares, which is at most 255.
+func SplitPrivateKey(signer Signer, threshold uint8, shares uint8) ([]*KeyShare, error) {
+	if threshold < 2 || threshold > shares {
+		return nil, fmt.Errorf("threshold must be between 2 and %d shares, got %d", shares, threshold)
+	}
+	variant, singleKey, secret, err := keySharePrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) != keyShareSecretLength {
+		return nil, fmt.Errorf("invalid %s private key size %d", variant, len(secret))
+	}
+
+	out := make([]*KeyShare, shares)
+	for i := range out {
+		out[i] = &KeyShare{
+			Variant:   variant,
+			SingleKey: singleKey,
+			Threshold: threshold,
+			Index:     uint8(i + 1),
+			Value:     make([]byte, len(secret)),
+		}
+		copy(out[i].Fingerprint[:], signer.AuthKey()[:])
+	}
+
+	// Each byte of the secret is the constant of a random polynomial of degree threshold - 1, shared as its values at
+	// each index
+	coefficients := make([]byte, threshold)
+	for j, secretByte := range secret {
+		coefficients[0] = secretByte
+		if _, err = rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range out {
+			share.Value[j] = gf256Evaluate(coefficients, share.Index)
+		}
+	}
+	return out, nil
+}
+
+// CombineKeyShares recombines at least Threshold shares from [SplitPrivateKey] into the signer.  Shares beyond the
+// threshold are checked to be consistent, and the recombined key is checked against the shares' Fingerprint.
+func CombineKeyShares(shares ...*KeyShare) (Signer, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no key shares")
+	}
+	first := shares[0]
+	seen := make(map[uint8]bool)
+	for _, share := range shares {
+		if share.Variant != first.Variant || share.SingleKey != first.SingleKey || share.Fingerprint != first.Fingerprint || share.Threshold != first.Threshold {
+			return nil, errors.New("key shares are of different keys")
+		}
+		if share.Index == 0 || len(share.Value) != keyShareSecretLength {
+			return nil, fmt.Errorf("invalid key share %d", share.Index)
+		}
+		if seen[share.Index] {
+			return nil, fmt.Errorf("duplicate key share %d", share.Index)
+		}
+		seen[share.Index] = true
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, fmt.Errorf("not enough key shares, %d of %d required", len(shares), first.Threshold)
+	}
+
+	used := shares[:first.Threshold]
+	xs := make([]byte, len(used))
+	for i, share := range used {
+		xs[i] = share.Index
+	}
+	secret := make([]byte, keyShareSecretLength)
+	ys := make([]byte, len(used))
+	for j := range secret {
+		for i, share := range used {
+			ys[i] = share.Value[j]
+		}
+		secret[j] = gf256Interpolate(xs, ys, 0)
+		for _, extra := range shares[first.Threshold:] {
+			if gf256Interpolate(xs, ys, extra.Index) != extra.Value[j] {
+				return nil, fmt.Errorf("key share %d is inconsistent with the others", extra.Index)
+			}
+		}
+	}
+
+	signer, err := keyShareSigner(first.Variant, first.SingleKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signer.AuthKey()[:len(first.Fingerprint)], first.Fingerprint[:]) {
+		return nil, ErrKeyShareAuthKey
+	}
+	return signer, nil
+}
+
+// RecoverKeyFromShares recombines shares as [CombineKeyShares] does, and checks the signer derives the expected
+// authentication key, returning [ErrKeyShareAuthKey] if not
+func RecoverKeyFromShares(authKey *AuthenticationKey, shares ...*KeyShare) (Signer, error) {
+	signer, err := CombineKeyShares(shares...)
+	if err != nil {
+		return nil, err
+	}
+	if *signer.AuthKey() != *authKey {
+		return nil, ErrKeyShareAuthKey
+	}
+	return signer, nil
+}
+
+// Bytes is the encoding of the share, version, flags, fingerprint, threshold, index, value, and checksum
+func (share *KeyShare) Bytes() []byte {
+	flags := byte(0)
+	if share.SingleKey {
+		flags |= keyShareFlagSingleKey
+	}
+	out := []byte{keyShareVersion, flags}
+	out = append(out, share.Fingerprint[:]...)
+	out = append(out, share.Threshold, share.Index)
+	out = append(out, share.Value...)
+	return append(out, keyShareChecksum(share.Variant, out)...)
+}
+
+// String encodes the share with its type prefix, e.g. "ed25519-share-0x..."
+func (share *KeyShare) String() string {
+	return KeySharePrefixes[share.Variant] + util.BytesToHex(share.Bytes())
+}
+
+// ParseKeyShare decodes a share encoded with [KeyShare.String], checking its checksum
+func ParseKeyShare(encoded string) (*KeyShare, error) {
+	for variant, prefix := range KeySharePrefixes {
+		if !strings.HasPrefix(encoded, prefix) {
+			continue
+		}
+		shareBytes, err := util.ParseHex(strings.TrimPrefix(encoded, prefix))
+		if err != nil {
+			return nil, err
+		}
+		return parseKeyShareBytes(variant, shareBytes)
+	}
+	return nil, errors.New("unknown key share type")
+}
+
+// parseKeyShareBytes decodes the bytes of a share of the variant
+func parseKeyShareBytes(variant PrivateKeyVariant, shareBytes []byte) (*KeyShare, error) {
+	headerLength := 8
+	if len(shareBytes) != headerLength+keyShareSecretLength+keyShareChecksumLength {
+		return nil, fmt.Errorf("invalid key share size %d", len(shareBytes))
+	}
+	body, checksum := shareBytes[:len(shareBytes)-keyShareChecksumLength], shareBytes[len(shareBytes)-keyShareChecksumLength:]
+	if !bytes.Equal(keyShareChecksum(variant, body), checksum) {
+		return nil, errors.New("invalid key share checksum")
+	}
+	if body[0] != keyShareVersion {
+		return nil, fmt.Errorf("unknown key share version %d", body[0])
+	}
+	share := &KeyShare{
+		Variant:   variant,
+		SingleKey: body[1]&keyShareFlagSingleKey != 0,
+		Threshold: body[6],
+		Index:     body[7],
+		Value:     append([]byte{}, body[headerLength:]...),
+	}
+	copy(share.Fingerprint[:], body[2:6])
+	return share, nil
+}
+
+// keyShareChecksum is the first bytes of the SHA3-256 of the share's prefix and body
+func keyShareChecksum(variant PrivateKeyVariant, body []byte) []byte {
+	return util.Sha3256Hash([][]byte{[]byte(KeySharePrefixes[variant]), body})[:keyShareChecksumLength]
+}
+
+// keySharePrivateKey extracts the private key from a signer
+func keySharePrivateKey(signer Signer) (PrivateKeyVariant, bool, []byte, error) {
+	switch signer := signer.(type) {
+	case *Ed25519PrivateKey:
+		return PrivateKeyVariantEd25519, false, signer.Bytes(), nil
+	case *SingleSigner:
+		switch inner := signer.Signer.(type) {
+		case *Ed25519PrivateKey:
+			return PrivateKeyVariantEd25519, true, inner.Bytes(), nil
+		case *Secp256k1PrivateKey:
+			return PrivateKeyVariantSecp256k1, true, inner.Bytes(), nil
+		}
+		return "", false, nil, fmt.Errorf("unsupported key share single signer %T", signer.Signer)
+	default:
+		return "", false, nil, fmt.Errorf("unsupported key share signer %T", signer)
+	}
+}
+
+// keyShareSigner rebuilds the signer from the recombined private key
+func keyShareSigner(variant PrivateKeyVariant, singleKey bool, secret []byte) (Signer, error) {
+	switch variant {
+	case PrivateKeyVariantEd25519:
+		privateKey := &Ed25519PrivateKey{}
+		if err := privateKey.FromBytes(secret); err != nil {
+			return nil, err
+		}
+		if singleKey {
+			return NewSingleSigner(privateKey), nil
+		}
+		return privateKey, nil
+	case PrivateKeyVariantSecp256k1:
+		privateKey := &Secp256k1PrivateKey{}
+		if err := privateKey.FromBytes(secret); err != nil {
+			return nil, err
+		}
+		return NewSingleSigner(privateKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported key share variant %s", variant)
+	}
+}
+
+//endregion
+
+//region GF(256)
+
+// gf256Exp and gf256Log are the exponent and logarithm tables of GF(256) with the AES polynomial x^8 + x^4 + x^3 + x + 1
+// and generator 3
+var gf256Exp, gf256Log = func() (exp [255]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		log[x] = byte(i)
+		// Multiply by 3, i.e. x * 2 + x, reducing by the polynomial
+		doubled := x << 1
+		if x&0x80 != 0 {
+			doubled ^= 0x1b
+		}
+		x ^= doubled
+	}
+	return exp, log
+}()
+
+// gf256Mul multiplies in GF(256)
+func gf256Mul(a byte, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[(int(gf256Log[a])+int(gf256Log[b]))%255]
+}
+
+// gf256Div divides in GF(256), b must not be 0
+func gf256Div(a byte, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gf256Exp[(int(gf256Log[a])-int(gf256Log[b])+255)%255]
+}
+
+// gf256Evaluate evaluates the polynomial with the coefficients, lowest degree first, at x
+func gf256Evaluate(coefficients []byte, x byte) byte {
+	out := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		out = gf256Mul(out, x) ^ coefficients[i]
+	}
+	return out
+}
+
+// gf256Interpolate evaluates the polynomial through the points (xs, ys) at x, by Lagrange interpolation.  Addition
+// and subtraction are both XOR.
+func gf256Interpolate(xs []byte, ys []byte, x byte) byte {
+	out := byte(0)
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i != j {
+				basis = gf256Mul(basis, gf256Div(x^xs[j], xs[i]^xs[j]))
+			}
+		}
+		out ^= gf256Mul(ys[i], basis)
+	}
+	return out
+}
+
+//endregion
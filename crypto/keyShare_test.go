@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGF256(t *testing.T) {
+	// 0x53 and 0xca are inverses in the AES field
+	assert.Equal(t, byte(0x01), gf256Mul(0x53, 0xca))
+	assert.Equal(t, byte(0xc1), gf256Mul(0x57, 0x83))
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(a), gf256Div(gf256Mul(byte(a), 0x1d), 0x1d))
+	}
+
+	// Interpolating a polynomial's values gives back any other value
+	coefficients := []byte{0x42, 0x13, 0xfe}
+	xs := []byte{1, 2, 3}
+	ys := []byte{gf256Evaluate(coefficients, 1), gf256Evaluate(coefficients, 2), gf256Evaluate(coefficients, 3)}
+	assert.Equal(t, byte(0x42), gf256Interpolate(xs, ys, 0))
+	assert.Equal(t, gf256Evaluate(coefficients, 200), gf256Interpolate(xs, ys, 200))
+}
+
+func TestSplitPrivateKey(t *testing.T) {
+	ed25519Key, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	secp256k1Key, err := GenerateSecp256k1Key()
+	assert.NoError(t, err)
+
+	for _, signer := range []Signer{ed25519Key, NewSingleSigner(ed25519Key), NewSingleSigner(secp256k1Key)} {
+		shares, err := SplitPrivateKey(signer, 3, 5)
+		assert.NoError(t, err)
+		assert.Len(t, shares, 5)
+
+		// Any 3 shares, in any order, recombine into the same signer
+		for _, indices := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {3, 0, 4, 1}} {
+			subset := make([]*KeyShare, len(indices))
+			for i, index := range indices {
+				subset[i] = shares[index]
+			}
+			combined, err := CombineKeyShares(subset...)
+			assert.NoError(t, err)
+			assert.Equal(t, signer, combined)
+			recovered, err := RecoverKeyFromShares(signer.AuthKey(), subset...)
+			assert.NoError(t, err)
+			assert.Equal(t, signer.AuthKey(), recovered.AuthKey())
+		}
+
+		// Encoding round trips
+		encoded := shares[1].String()
+		parsed, err := ParseKeyShare(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, shares[1], parsed)
+
+		_, err = CombineKeyShares(shares[0], shares[1])
+		assert.Error(t, err)
+		_, err = CombineKeyShares(shares[0], shares[1], shares[1])
+		assert.Error(t, err)
+	}
+
+	_, err = SplitPrivateKey(ed25519Key, 1, 5)
+	assert.Error(t, err)
+	_, err = SplitPrivateKey(ed25519Key, 4, 3)
+	assert.Error(t, err)
+	_, err = SplitPrivateKey(NewSingleSigner(&Secp256r1PrivateKey{}), 2, 3)
+	assert.Error(t, err)
+}
+
+func TestCombineKeyShares_Invalid(t *testing.T) {
+	key, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	otherKey, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	shares, err := SplitPrivateKey(key, 2, 3)
+	assert.NoError(t, err)
+	otherShares, err := SplitPrivateKey(otherKey, 2, 3)
+	assert.NoError(t, err)
+
+	// A typo is caught by the checksum
+	encoded := []byte(shares[0].String())
+	if encoded[20] == '0' {
+		encoded[20] = '1'
+	} else {
+		encoded[20] = '0'
+	}
+	_, err = ParseKeyShare(string(encoded))
+	assert.Error(t, err)
+	_, err = ParseKeyShare("ed25519-priv-" + strings.TrimPrefix(shares[0].String(), KeySharePrefixes[PrivateKeyVariantEd25519]))
+	assert.Error(t, err)
+
+	// Shares of different keys, or inconsistent with the rest, don't recombine
+	_, err = CombineKeyShares(shares[0], otherShares[1])
+	assert.Error(t, err)
+	otherShares[1].Fingerprint = shares[0].Fingerprint
+	_, err = CombineKeyShares(shares[0], otherShares[1])
+	assert.ErrorIs(t, err, ErrKeyShareAuthKey)
+	tampered := *shares[2]
+	tampered.Value = append([]byte{tampered.Value[0] ^ 1}, tampered.Value[1:]...)
+	_, err = CombineKeyShares(shares[0], shares[1], &tampered)
+	assert.Error(t, err)
+
+	_, err = RecoverKeyFromShares(otherKey.AuthKey(), shares[0], shares[1])
+	assert.ErrorIs(t, err, ErrKeyShareAuthKey)
+}
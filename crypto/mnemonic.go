@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//region Mnemonic
+
+// MnemonicEntropyBits are the allowed entropy sizes for a BIP-39 mnemonic, giving 12, 15, 18, 21, or 24 words
+var MnemonicEntropyBits = []int{128, 160, 192, 224, 256}
+
+// ErrInvalidMnemonic is returned when a mnemonic phrase has an unknown word, the wrong length, or a bad checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// bip39WordIndex maps each BIP-39 word to its index
+var bip39WordIndex = func() map[string]int {
+	index := make(map[string]int, len(bip39EnglishWords))
+	for i, word := range bip39EnglishWords {
+		index[word] = i
+	}
+	return index
+}()
+
+// GenerateMnemonic generates a random BIP-39 English mnemonic phrase with the given entropy bits, defaulting to 256
+// bits (24 words).  See [MnemonicEntropyBits] for allowed sizes.
+func GenerateMnemonic(entropyBits ...int) (string, error) {
+	bits := 256
+	if len(entropyBits) > 1 {
+		return "", errors.New("only one entropy size may be given")
+	} else if len(entropyBits) == 1 {
+		bits = entropyBits[0]
+	}
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", fmt.Errorf("invalid mnemonic entropy size %d bits", bits)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes entropy as a BIP-39 English mnemonic phrase.  The entropy must be 16, 20, 24, 28, or 32
+// bytes.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", fmt.Errorf("invalid mnemonic entropy size %d bits", bits)
+	}
+	checksum := sha256.Sum256(entropy)
+	// The entropy is followed by the first bits/32 bits of its SHA-256, and split into 11 bit word indexes
+	data := append(append([]byte{}, entropy...), checksum[0])
+	words := make([]string, (bits+bits/32)/11)
+	for i := range words {
+		words[i] = bip39EnglishWords[readBits(data, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a BIP-39 English mnemonic phrase, checking its checksum
+func MnemonicToEntropy(phrase string) ([]byte, error) {
+	words := strings.Fields(phrase)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := bip39WordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %d %q", ErrInvalidMnemonic, i+1, word)
+		}
+		writeBits(data, i*11, 11, index)
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	checksum := sha256.Sum256(entropy)
+	if readBits(data, len(entropy)*8, checksumBits) != readBits(checksum[:], 0, checksumBits) {
+		return nil, fmt.Errorf("%w: bad checksum", ErrInvalidMnemonic)
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic checks that the phrase is a valid BIP-39 English mnemonic
+func ValidateMnemonic(phrase string) error {
+	_, err := MnemonicToEntropy(phrase)
+	return err
+}
+
+// MnemonicToSeed validates the phrase and derives the 64 byte BIP-39 seed, with an optional passphrase.
+//
+// BIP-39 normalizes the phrase and passphrase with NFKD.  English phrases are ASCII so are unchanged, but a non-ASCII
+// passphrase must already be NFKD normalized to match other wallets.
+func MnemonicToSeed(phrase string, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(phrase); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(strings.Fields(phrase), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New), nil
+}
+
+// readBits reads count bits big-endian from data, starting at bit offset
+func readBits(data []byte, offset int, count int) int {
+	value := 0
+	for i := offset; i < offset+count; i++ {
+		value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+	}
+	return value
+}
+
+// writeBits writes the low count bits of value big-endian into data, starting at bit offset
+func writeBits(data []byte, offset int, count int, value int) {
+	for i := 0; i < count; i++ {
+		if value>>(count-1-i)&1 == 1 {
+			bit := offset + i
+			data[bit/8] |= 1 << (7 - bit%8)
+		}
+	}
+}
+
+//endregion
@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from BIP-39, with the passphrase TREZOR
+var testMnemonicVectors = []struct {
+	entropy string
+	phrase  string
+	seed    string
+}{
+	{
+		"0x00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"0xc55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"0x7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"0x2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"0x80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"0xd71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"0xffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"0xac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"0x0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"0xbda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+}
+
+func TestMnemonic_Vectors(t *testing.T) {
+	for _, vector := range testMnemonicVectors {
+		entropy, err := util.ParseHex(vector.entropy)
+		assert.NoError(t, err)
+		phrase, err := EntropyToMnemonic(entropy)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.phrase, phrase)
+
+		decoded, err := MnemonicToEntropy(phrase)
+		assert.NoError(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		seed, err := MnemonicToSeed(phrase, "TREZOR")
+		assert.NoError(t, err)
+		assert.Equal(t, vector.seed, util.BytesToHex(seed))
+	}
+}
+
+func TestMnemonic_Generate(t *testing.T) {
+	for _, bits := range MnemonicEntropyBits {
+		phrase, err := GenerateMnemonic(bits)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(phrase), (bits+bits/32)/11)
+		assert.NoError(t, ValidateMnemonic(phrase))
+	}
+	phrase, err := GenerateMnemonic()
+	assert.NoError(t, err)
+	assert.Len(t, strings.Fields(phrase), 24)
+
+	_, err = GenerateMnemonic(100)
+	assert.Error(t, err)
+	_, err = EntropyToMnemonic(make([]byte, 15))
+	assert.Error(t, err)
+}
+
+func TestMnemonic_Invalid(t *testing.T) {
+	// Bad checksum
+	assert.ErrorIs(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"), ErrInvalidMnemonic)
+	// Unknown word
+	assert.ErrorIs(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abou"), ErrInvalidMnemonic)
+	// Wrong length
+	assert.ErrorIs(t, ValidateMnemonic("abandon abandon abandon"), ErrInvalidMnemonic)
+	_, err := MnemonicToSeed("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo", "")
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+}
+
+func TestDerivationPath(t *testing.T) {
+	indexes, err := ParseDerivationPath(Secp256k1DerivationPath(637, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{44 + HardenedOffset, 637 + HardenedOffset, HardenedOffset, 0, 0}, indexes)
+	indexes, err = ParseDerivationPath("m/0H/1")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{HardenedOffset, 1}, indexes)
+
+	assert.True(t, IsHardenedPath(Ed25519DerivationPath(637, 0)))
+	assert.False(t, IsHardenedPath(Secp256k1DerivationPath(637, 0)))
+	assert.Equal(t, "m/44'/637'/3'/0'/0'", Ed25519DerivationPath(637, 3))
+	assert.Equal(t, "m/44'/1'/0'/0/0", Secp256k1DerivationPath(1, 0))
+
+	for _, path := range []string{"", "44'/637'", "m/x", "m/2147483648", "m//1"} {
+		_, err = ParseDerivationPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+// Test vector 1 from SLIP-0010 for ed25519
+func TestDeriveEd25519PrivateKey(t *testing.T) {
+	seed, err := util.ParseHex("0x000102030405060708090a0b0c0d0e0f")
+	assert.NoError(t, err)
+	vectors := map[string]string{
+		"m":                         "0x2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"m/0H":                      "0x68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"m/0H/1H":                   "0xb1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"m/0H/1H/2H":                "0x92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+		"m/0H/1H/2H/2H":             "0x30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+		"m/0H/1H/2H/2H/1000000000H": "0x8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+	}
+	for path, expected := range vectors {
+		privateKey, err := DeriveEd25519PrivateKey(seed, path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, privateKey.ToHex(), path)
+	}
+
+	_, err = DeriveEd25519PrivateKey(seed, "m/0H/1")
+	assert.Error(t, err)
+}
+
+// Test vector 1 from BIP-32
+func TestDeriveSecp256k1PrivateKey(t *testing.T) {
+	seed, err := util.ParseHex("0x000102030405060708090a0b0c0d0e0f")
+	assert.NoError(t, err)
+	vectors := map[string]string{
+		"m":                      "0xe8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		"m/0H":                   "0xedb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		"m/0H/1":                 "0x3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		"m/0H/1/2H":              "0xcbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca",
+		"m/0H/1/2H/2":            "0x0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4",
+		"m/0H/1/2H/2/1000000000": "0x471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8",
+	}
+	for path, expected := range vectors {
+		privateKey, err := DeriveSecp256k1PrivateKey(seed, path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, privateKey.ToHex(), path)
+	}
+}
@@ -36,15 +36,24 @@ type MultiEd25519PublicKey struct {
 func (key *MultiEd25519PublicKey) Verify(msg []byte, signature Signature) bool {
 	switch sig := signature.(type) {
 	case *MultiEd25519Signature:
-		verified := uint8(0)
-		// TODO: Verify with bitmap, and check that this works properly
-		for i, pubKey := range key.PubKeys {
-			if pubKey.Verify(msg, sig.Signatures[i]) {
-				verified++
+		// The bitmap marks which keys signed, from the most significant bit of the first byte, and the signatures
+		// are in the same order
+		indices := make([]int, 0, len(sig.Signatures))
+		for i := 0; i < MultiEd25519BitmapLen*8; i++ {
+			if sig.Bitmap[i/8]&(128>>(i%8)) != 0 {
+				indices = append(indices, i)
 			}
 		}
+		if len(indices) != len(sig.Signatures) || len(indices) < int(key.SignaturesRequired) {
+			return false
+		}
 
-		return verified >= key.SignaturesRequired
+		for sigIndex, keyIndex := range indices {
+			if keyIndex >= len(key.PubKeys) || !key.PubKeys[keyIndex].Verify(msg, sig.Signatures[sigIndex]) {
+				return false
+			}
+		}
+		return true
 	default:
 		return false
 	}
@@ -355,3 +364,209 @@ func (e *MultiEd25519Signature) UnmarshalBCS(des *bcs.Deserializer) {
 
 //endregion
 //endregion
+
+//region MultiEd25519Signer
+
+// IndexedEd25519Signature is one key's signature for a [MultiEd25519PublicKey], with the index of the key
+//
+// Implements:
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type IndexedEd25519Signature struct {
+	Index     uint8
+	Signature *Ed25519Signature
+}
+
+// MarshalBCS serializes the signature to bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (e *IndexedEd25519Signature) MarshalBCS(ser *bcs.Serializer) {
+	ser.U8(e.Index)
+	ser.Struct(e.Signature)
+}
+
+// UnmarshalBCS deserializes the signature from bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (e *IndexedEd25519Signature) UnmarshalBCS(des *bcs.Deserializer) {
+	e.Index = des.U8()
+	e.Signature = &Ed25519Signature{}
+	des.Struct(e.Signature)
+}
+
+// MultiEd25519Signer signs for a legacy MultiEd25519 account, with the private keys held locally, and partial
+// signatures from the holders of other keys.  Each holder signs with [MultiEd25519Signer.SignPartial], and one of them
+// merges the signatures with [MultiEd25519Signer.CombineSignatures].  With SignaturesRequired keys held locally, it
+// can sign on its own, e.g. as the signer of an Account.
+//
+// Implements:
+//   - [Signer]
+type MultiEd25519Signer struct {
+	PublicKey   *MultiEd25519PublicKey // PublicKey is all keys of the account, in order, and the threshold
+	PrivateKeys []*Ed25519PrivateKey   // PrivateKeys are the keys held locally, any of PublicKey's keys
+}
+
+// NewMultiEd25519Signer creates a [MultiEd25519Signer] from the account's public keys in order, the number of
+// signatures required, and the private keys held locally, which may be none
+func NewMultiEd25519Signer(pubKeys []*Ed25519PublicKey, signaturesRequired uint8, privateKeys ...*Ed25519PrivateKey) (*MultiEd25519Signer, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > MultiEd25519BitmapLen*8 {
+		return nil, fmt.Errorf("multi ed25519 key must have between 1 and %d keys, got %d", MultiEd25519BitmapLen*8, len(pubKeys))
+	}
+	if signaturesRequired == 0 || int(signaturesRequired) > len(pubKeys) {
+		return nil, fmt.Errorf("signatures required must be between 1 and %d, got %d", len(pubKeys), signaturesRequired)
+	}
+	signer := &MultiEd25519Signer{PublicKey: &MultiEd25519PublicKey{PubKeys: pubKeys, SignaturesRequired: signaturesRequired}}
+	for _, privateKey := range privateKeys {
+		if signer.index(privateKey.PubKey()) < 0 {
+			return nil, fmt.Errorf("private key for %s is not a key of the multi ed25519 key", privateKey.PubKey().ToHex())
+		}
+		signer.PrivateKeys = append(signer.PrivateKeys, privateKey)
+	}
+	return signer, nil
+}
+
+// index is the key's position in PublicKey, or -1 if it isn't one of the keys
+func (signer *MultiEd25519Signer) index(pubKey PublicKey) int {
+	keyBytes := pubKey.Bytes()
+	for i, key := range signer.PublicKey.PubKeys {
+		if string(key.Bytes()) == string(keyBytes) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SignPartial signs the message with each local private key, for combining with [MultiEd25519Signer.CombineSignatures]
+func (signer *MultiEd25519Signer) SignPartial(msg []byte) ([]IndexedEd25519Signature, error) {
+	if len(signer.PrivateKeys) == 0 {
+		return nil, fmt.Errorf("multi ed25519 signer has no local private keys")
+	}
+	signatures := make([]IndexedEd25519Signature, 0, len(signer.PrivateKeys))
+	for _, privateKey := range signer.PrivateKeys {
+		index := signer.index(privateKey.PubKey())
+		if index < 0 {
+			return nil, fmt.Errorf("private key for %s is not a key of the multi ed25519 key", privateKey.PubKey().ToHex())
+		}
+		signature, err := privateKey.SignMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, IndexedEd25519Signature{Index: uint8(index), Signature: signature.(*Ed25519Signature)})
+	}
+	return signatures, nil
+}
+
+// CombineSignatures merges signatures in any order into a [MultiEd25519Authenticator], with the signatures in key
+// order and the bitmap marking each key from the most significant bit.  Each signature is checked against its key,
+// and repeated indices are only counted once.
+func (signer *MultiEd25519Signer) CombineSignatures(msg []byte, signatures ...IndexedEd25519Signature) (*AccountAuthenticator, error) {
+	byIndex := make([]*Ed25519Signature, len(signer.PublicKey.PubKeys))
+	count := 0
+	for _, signature := range signatures {
+		if int(signature.Index) >= len(byIndex) {
+			return nil, fmt.Errorf("signature index %d is out of range of %d keys", signature.Index, len(byIndex))
+		}
+		if signature.Signature == nil || !signer.PublicKey.PubKeys[signature.Index].Verify(msg, signature.Signature) {
+			return nil, fmt.Errorf("signature for key %d is invalid", signature.Index)
+		}
+		if byIndex[signature.Index] == nil {
+			byIndex[signature.Index] = signature.Signature
+			count++
+		}
+	}
+	if count < int(signer.PublicKey.SignaturesRequired) {
+		return nil, fmt.Errorf("not enough signatures, %d of %d required", count, signer.PublicKey.SignaturesRequired)
+	}
+	return signer.authenticator(byIndex), nil
+}
+
+// authenticator puts the signatures given by key index into a [MultiEd25519Authenticator]
+func (signer *MultiEd25519Signer) authenticator(byIndex []*Ed25519Signature) *AccountAuthenticator {
+	multiSignature := &MultiEd25519Signature{Signatures: make([]*Ed25519Signature, 0, signer.PublicKey.SignaturesRequired)}
+	for i, signature := range byIndex {
+		if signature != nil {
+			multiSignature.Signatures = append(multiSignature.Signatures, signature)
+			multiSignature.Bitmap[i/8] |= 128 >> (i % 8)
+		}
+	}
+	return &AccountAuthenticator{
+		Variant: AccountAuthenticatorMultiEd25519,
+		Auth:    &MultiEd25519Authenticator{PubKey: signer.PublicKey, Sig: multiSignature},
+	}
+}
+
+//region MultiEd25519Signer Signer implementation
+
+// Sign signs the message with SignaturesRequired local private keys
+//
+// Implements:
+//   - [Signer]
+func (signer *MultiEd25519Signer) Sign(msg []byte) (*AccountAuthenticator, error) {
+	required := int(signer.PublicKey.SignaturesRequired)
+	if len(signer.PrivateKeys) < required {
+		return nil, fmt.Errorf("not enough local private keys, %d of %d required", len(signer.PrivateKeys), required)
+	}
+	local := &MultiEd25519Signer{PublicKey: signer.PublicKey, PrivateKeys: signer.PrivateKeys[:required]}
+	signatures, err := local.SignPartial(msg)
+	if err != nil {
+		return nil, err
+	}
+	return signer.CombineSignatures(msg, signatures...)
+}
+
+// SignMessage signs the message with SignaturesRequired local private keys, returning a [MultiEd25519Signature]
+//
+// Implements:
+//   - [Signer]
+func (signer *MultiEd25519Signer) SignMessage(msg []byte) (Signature, error) {
+	auth, err := signer.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	return auth.Signature(), nil
+}
+
+// SimulationAuthenticator creates an authenticator with empty signatures for SignaturesRequired keys, the local keys
+// first
+//
+// Implements:
+//   - [Signer]
+func (signer *MultiEd25519Signer) SimulationAuthenticator() *AccountAuthenticator {
+	byIndex := make([]*Ed25519Signature, len(signer.PublicKey.PubKeys))
+	count := 0
+	for _, privateKey := range signer.PrivateKeys {
+		if index := signer.index(privateKey.PubKey()); index >= 0 && byIndex[index] == nil && count < int(signer.PublicKey.SignaturesRequired) {
+			byIndex[index] = &Ed25519Signature{}
+			count++
+		}
+	}
+	for i := range byIndex {
+		if byIndex[i] == nil && count < int(signer.PublicKey.SignaturesRequired) {
+			byIndex[i] = &Ed25519Signature{}
+			count++
+		}
+	}
+	return signer.authenticator(byIndex)
+}
+
+// AuthKey gives the [AuthenticationKey] of the [MultiEd25519PublicKey]
+//
+// Implements:
+//   - [Signer]
+func (signer *MultiEd25519Signer) AuthKey() *AuthenticationKey {
+	return signer.PublicKey.AuthKey()
+}
+
+// PubKey returns the [MultiEd25519PublicKey]
+//
+// Implements:
+//   - [Signer]
+func (signer *MultiEd25519Signer) PubKey() PublicKey {
+	return signer.PublicKey
+}
+
+//endregion
+//endregion
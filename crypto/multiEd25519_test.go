@@ -89,6 +89,91 @@ func createMultiEd25519Signature(t *testing.T, key1 *Ed25519PrivateKey, key2 *Ed
 			sig1.(*Ed25519Signature),
 			sig2.(*Ed25519Signature),
 		},
-		Bitmap: [4]byte([]byte("c0000000")),
+		Bitmap: [4]byte{0xc0, 0x00, 0x00, 0x00},
 	}
 }
+
+func TestMultiEd25519Verify_Bitmap(t *testing.T) {
+	key1, key2, pubkey1, pubkey2, _ := createMultiEd25519Key(t)
+	key3, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	publicKey := &MultiEd25519PublicKey{
+		PubKeys:            []*Ed25519PublicKey{pubkey1, key3.PubKey().(*Ed25519PublicKey), pubkey2},
+		SignaturesRequired: 2,
+	}
+	message := []byte("hello world")
+
+	// Keys 0 and 2 of 3 signed
+	signature := createMultiEd25519Signature(t, key1, key2, message)
+	signature.Bitmap = [4]byte{0xa0, 0x00, 0x00, 0x00}
+	assert.True(t, publicKey.Verify(message, signature))
+
+	// Bitmap claiming the wrong keys, or too few keys
+	signature.Bitmap = [4]byte{0xc0, 0x00, 0x00, 0x00}
+	assert.False(t, publicKey.Verify(message, signature))
+	signature.Bitmap = [4]byte{0x80, 0x00, 0x00, 0x00}
+	assert.False(t, publicKey.Verify(message, signature))
+	signature.Bitmap = [4]byte{0xa0, 0x00, 0x00, 0x01}
+	assert.False(t, publicKey.Verify(message, signature))
+}
+
+func TestMultiEd25519Signer(t *testing.T) {
+	key1, key2, pubkey1, pubkey2, _ := createMultiEd25519Key(t)
+	key3, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	pubKeys := []*Ed25519PublicKey{pubkey1, pubkey2, key3.PubKey().(*Ed25519PublicKey)}
+	message := []byte("hello world")
+
+	_, err = NewMultiEd25519Signer(pubKeys, 4)
+	assert.Error(t, err)
+	other, err := GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	_, err = NewMultiEd25519Signer(pubKeys, 2, other)
+	assert.Error(t, err)
+
+	// Key 3 is held elsewhere, and its signature is sent as BCS
+	remote, err := NewMultiEd25519Signer(pubKeys, 2, key3)
+	assert.NoError(t, err)
+	partial, err := remote.SignPartial(message)
+	assert.NoError(t, err)
+	partialBytes, err := bcs.Serialize(&partial[0])
+	assert.NoError(t, err)
+	received := IndexedEd25519Signature{}
+	assert.NoError(t, bcs.Deserialize(&received, partialBytes))
+	assert.Equal(t, uint8(2), received.Index)
+
+	signer, err := NewMultiEd25519Signer(pubKeys, 2, key1)
+	assert.NoError(t, err)
+	_, err = signer.Sign(message)
+	assert.Error(t, err)
+	local, err := signer.SignPartial(message)
+	assert.NoError(t, err)
+	_, err = signer.CombineSignatures(message, received, received)
+	assert.Error(t, err)
+	_, err = signer.CombineSignatures(message, IndexedEd25519Signature{Index: 1, Signature: received.Signature}, local[0])
+	assert.Error(t, err)
+	auth, err := signer.CombineSignatures(message, received, local[0])
+	assert.NoError(t, err)
+	assert.Equal(t, [4]byte{0xa0, 0x00, 0x00, 0x00}, auth.Auth.(*MultiEd25519Authenticator).Sig.Bitmap)
+	assert.True(t, auth.Verify(message))
+	assert.Equal(t, signer.AuthKey(), auth.PubKey().AuthKey())
+
+	authBytes, err := bcs.Serialize(auth)
+	assert.NoError(t, err)
+	authDeserialized := &AccountAuthenticator{}
+	assert.NoError(t, bcs.Deserialize(authDeserialized, authBytes))
+	assert.True(t, authDeserialized.Verify(message))
+
+	// Enough keys held locally to sign alone
+	signer, err = NewMultiEd25519Signer(pubKeys, 2, key3, key2)
+	assert.NoError(t, err)
+	auth, err = signer.Sign(message)
+	assert.NoError(t, err)
+	assert.Equal(t, [4]byte{0x60, 0x00, 0x00, 0x00}, auth.Auth.(*MultiEd25519Authenticator).Sig.Bitmap)
+	assert.True(t, auth.Verify(message))
+
+	// Simulation marks the local keys first
+	simulation := remote.SimulationAuthenticator().Auth.(*MultiEd25519Authenticator)
+	assert.Equal(t, [4]byte{0xa0, 0x00, 0x00, 0x00}, simulation.Sig.Bitmap)
+	assert.Len(t, simulation.Sig.Signatures, 2)
+}
@@ -37,8 +37,17 @@ func (key *MultiKey) Verify(msg []byte, signature Signature) bool {
 			return false
 		}
 
+		// Every signature must have a key in the bitmap
+		indices := sig.Bitmap.Indices()
+		if len(indices) != len(sig.Signatures) {
+			return false
+		}
+
 		// Convert to individual authenticators, and verify
-		for sigIndex, keyIndex := range sig.Bitmap.Indices() {
+		for sigIndex, keyIndex := range indices {
+			if int(keyIndex) >= len(key.PubKeys) {
+				return false
+			}
 			authenticator := AccountAuthenticator{}
 			err := authenticator.FromKeyAndSignature(key.PubKeys[keyIndex], sig.Signatures[sigIndex])
 			if err != nil {
@@ -371,7 +380,7 @@ func (bm *MultiKeyBitmap) ContainsKey(index uint8) bool {
 	if int(numByte) >= len(bm.inner) {
 		return false
 	}
-	return (bm.inner[numByte] & (128 >> numBit)) == 1
+	return (bm.inner[numByte] & (128 >> numBit)) != 0
 }
 
 // AddKey adds the value to the map, returning an error if it is already added
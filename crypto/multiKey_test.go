@@ -160,3 +160,28 @@ func createMultiKeySignature(t *testing.T, index1 uint8, key1 *SingleSigner, ind
 
 	return sig
 }
+
+func TestMultiKeyBitmap(t *testing.T) {
+	bitmap := MultiKeyBitmap{}
+	assert.NoError(t, bitmap.AddKey(0))
+	assert.NoError(t, bitmap.AddKey(9))
+	assert.True(t, bitmap.ContainsKey(0))
+	assert.True(t, bitmap.ContainsKey(9))
+	assert.False(t, bitmap.ContainsKey(1))
+	assert.Error(t, bitmap.AddKey(9))
+	assert.Equal(t, []uint8{0, 9}, bitmap.Indices())
+}
+
+func TestMultiKeyVerify_WrongIndex(t *testing.T) {
+	key1, key2, _, _, _, _, publicKey := createMultiKey(t)
+	message := []byte("hello world")
+
+	// Signatures claimed for the wrong keys
+	signature := createMultiKeySignature(t, 1, key1, 2, key2, message)
+	assert.False(t, publicKey.Verify(message, signature))
+
+	// Signatures without keys in the bitmap
+	signature = createMultiKeySignature(t, 0, key1, 1, key2, message)
+	signature.Bitmap = MultiKeyBitmap{}
+	assert.False(t, publicKey.Verify(message, signature))
+}
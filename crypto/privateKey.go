@@ -14,12 +14,14 @@ type PrivateKeyVariant string
 const (
 	PrivateKeyVariantEd25519   PrivateKeyVariant = "ed25519"
 	PrivateKeyVariantSecp256k1 PrivateKeyVariant = "secp256k1"
+	PrivateKeyVariantSecp256r1 PrivateKeyVariant = "secp256r1"
 )
 
 // AIP80Prefixes contains the AIP-80 compliant prefixes for each private key type
 var AIP80Prefixes = map[PrivateKeyVariant]string{
 	PrivateKeyVariantEd25519:   "ed25519-priv-",
 	PrivateKeyVariantSecp256k1: "secp256k1-priv-",
+	PrivateKeyVariantSecp256r1: "secp256r1-priv-",
 }
 
 // FormatPrivateKey formats a hex input to an AIP-80 compliant string
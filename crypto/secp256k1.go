@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
@@ -20,6 +21,9 @@ const Secp256k1PublicKeyLength = 65
 // Secp256k1SignatureLength is the [Secp256k1Signature] length in bytes.  It is a signature without the recovery bit.
 const Secp256k1SignatureLength = 64
 
+// Secp256k1RecoverableSignatureLength is the [Secp256k1RecoverableSignature] length in bytes, in compact form r, s, v
+const Secp256k1RecoverableSignatureLength = 65
+
 // Secp256k1PrivateKey is a private key that can be used with [SingleSigner].  It cannot stand on its own.
 //
 // Implements:
@@ -72,6 +76,19 @@ func (key *Secp256k1PrivateKey) SignMessage(msg []byte) (sig Signature, err erro
 	return &Secp256k1Signature{signature}, nil
 }
 
+// SignRecoverable signs a message as [Secp256k1PrivateKey.SignMessage] does, and includes the recovery id, so the
+// public key can be recovered with [Secp256k1RecoverableSignature.RecoverPublicKey]
+func (key *Secp256k1PrivateKey) SignRecoverable(msg []byte) (*Secp256k1RecoverableSignature, error) {
+	hash := util.Sha3256Hash([][]byte{msg})
+	// Compact is the recovery code 27 + v, then r and s
+	compact := ecdsa.SignCompact(key.Inner, hash, false)
+	signature := &Secp256k1Signature{}
+	if err := signature.FromBytes(compact[1:]); err != nil {
+		return nil, err
+	}
+	return &Secp256k1RecoverableSignature{Signature: signature, RecoveryId: compact[0] - 27}, nil
+}
+
 //endregion
 
 //region Secp256k1PrivateKey CryptoMaterial
@@ -160,6 +177,10 @@ type Secp256k1PublicKey struct {
 func (key *Secp256k1PublicKey) Verify(msg []byte, sig Signature) bool {
 	switch sig := sig.(type) {
 	case *Secp256k1Signature:
+		// Only low-S signatures are valid on-chain, the high-S twin of a signature is rejected
+		if s := sig.Inner.S(); s.IsOverHalfOrder() {
+			return false
+		}
 		// Verification requires to pass the SHA-256 hash of the message
 		hash := util.Sha3256Hash([][]byte{msg})
 		return sig.Inner.Verify(hash, key.Inner)
@@ -330,7 +351,8 @@ type Secp256k1Signature struct {
 
 // RecoverPublicKey recovers the public key from the signature and message
 //
-// If you know the recovery bit (0-4), please provide it, otherwise, use [RecoverSecp256k1PublicKeyWithAuthenticationKey]
+// If you know the recovery bit (0-3, or 27-30 as Ethereum encodes it), please provide it, otherwise, use
+// [RecoverSecp256k1PublicKeyWithAuthenticationKey]
 //
 // Note that this only applies to an [Secp256k1Signature], all other signatures are not recoverable
 func (e *Secp256k1Signature) RecoverPublicKey(message []byte, recoveryBit byte) (pubKey *Secp256k1PublicKey, err error) {
@@ -338,6 +360,25 @@ func (e *Secp256k1Signature) RecoverPublicKey(message []byte, recoveryBit byte)
 	return e.recoverSecp256k1PublicKey(hash, recoveryBit)
 }
 
+// RecoverSecp256k1PublicKey recovers the public key that signed the message from the signature and its recovery id v,
+// 0-3 or 27-30.  The message is hashed with SHA3-256, as [Secp256k1PrivateKey.SignMessage] does.
+func RecoverSecp256k1PublicKey(message []byte, signature *Secp256k1Signature, v byte) (*Secp256k1PublicKey, error) {
+	return signature.RecoverPublicKey(message, v)
+}
+
+// ToRecoverable finds the recovery id of the signature of the message by the public key, e.g. to relay the signature
+// in compact form to systems that recover the signer
+func (e *Secp256k1Signature) ToRecoverable(message []byte, pubKey *Secp256k1PublicKey) (*Secp256k1RecoverableSignature, error) {
+	hash := util.Sha3256Hash([][]byte{message})
+	for v := byte(0); v < 4; v++ {
+		key, err := e.recoverSecp256k1PublicKey(hash, v)
+		if err == nil && key.Inner.IsEqual(pubKey.Inner) {
+			return &Secp256k1RecoverableSignature{Signature: e, RecoveryId: v}, nil
+		}
+	}
+	return nil, errors.New("signature is not of the message by the public key")
+}
+
 // RecoverSecp256k1PublicKeyWithAuthenticationKey recovers the public key from the signature and message, and checks if it matches the authentication key
 //
 // Note that, the authentication key may be an address, but if the authentication key was rotated it will differ from the address
@@ -366,6 +407,13 @@ func (e *Secp256k1Signature) RecoverSecp256k1PublicKeyWithAuthenticationKey(mess
 
 // / recoverSecp256k1PublicKey recovers the public key from the signature and message by building up the magic byte
 func (e *Secp256k1Signature) recoverSecp256k1PublicKey(messageHash []byte, recoveryBit byte) (pubKey *Secp256k1PublicKey, err error) {
+	recoveryBit, err = secp256k1RecoveryId(recoveryBit)
+	if err != nil {
+		return nil, err
+	}
+	if s := e.Inner.S(); s.IsOverHalfOrder() {
+		return nil, errors.New("invalid secp256k1 signature: s is over half order")
+	}
 	// Append magic 27 because of bitcoin, and the recovery byte in front
 	sigWithRecovery := append([]byte{byte(recoveryBit) + 27}, e.Bytes()...)
 	publicKey, _, err := ecdsa.RecoverCompact(sigWithRecovery, messageHash)
@@ -474,3 +522,147 @@ func (e *Secp256k1Signature) UnmarshalBCS(des *bcs.Deserializer) {
 
 //endregion
 //endregion
+
+//region Secp256k1RecoverableSignature
+
+// Secp256k1RecoverableSignature is a [Secp256k1Signature] with its recovery id v, so the signer's public key can be
+// recovered from the signature and message.  Its compact form is r, s, then v, as Ethereum and other systems relay
+// signatures.
+//
+// Implements:
+//   - [CryptoMaterial]
+type Secp256k1RecoverableSignature struct {
+	Signature  *Secp256k1Signature // Signature is the low-S signature, r and s
+	RecoveryId byte                // RecoveryId is v, 0-3
+}
+
+// secp256k1RecoveryId normalizes the recovery id v to 0-3, from either 0-3 or 27-30
+func secp256k1RecoveryId(v byte) (byte, error) {
+	switch {
+	case v < 4:
+		return v, nil
+	case v >= 27 && v < 31:
+		return v - 27, nil
+	default:
+		return 0, fmt.Errorf("invalid secp256k1 recovery id %d", v)
+	}
+}
+
+// R returns the 32 bytes of r
+func (e *Secp256k1RecoverableSignature) R() []byte {
+	return e.Signature.Bytes()[:32]
+}
+
+// S returns the 32 bytes of s
+func (e *Secp256k1RecoverableSignature) S() []byte {
+	return e.Signature.Bytes()[32:]
+}
+
+// V returns the recovery id, 0-3
+func (e *Secp256k1RecoverableSignature) V() byte {
+	return e.RecoveryId
+}
+
+// RecoverPublicKey recovers the public key that signed the message
+func (e *Secp256k1RecoverableSignature) RecoverPublicKey(message []byte) (*Secp256k1PublicKey, error) {
+	return e.Signature.RecoverPublicKey(message, e.RecoveryId)
+}
+
+// IsLowS returns true if s is at most half the curve order, the only form accepted on-chain
+func (e *Secp256k1RecoverableSignature) IsLowS() bool {
+	s := e.Signature.Inner.S()
+	return !s.IsOverHalfOrder()
+}
+
+// NormalizeS replaces s with n - s if s is over half the curve order, and flips the parity of v to match.  Both are
+// valid ECDSA signatures of the message by the same key, but only the low-S one is accepted on-chain.
+func (e *Secp256k1RecoverableSignature) NormalizeS() {
+	if e.IsLowS() {
+		return
+	}
+	r, s := e.Signature.Inner.R(), e.Signature.Inner.S()
+	e.Signature = &Secp256k1Signature{Inner: ecdsa.NewSignature(&r, s.Negate())}
+	e.RecoveryId ^= 1
+}
+
+//region Secp256k1RecoverableSignature CryptoMaterial
+
+// Bytes returns the compact form of the [Secp256k1RecoverableSignature], r, s, then v as 0-3
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256k1RecoverableSignature) Bytes() []byte {
+	return append(e.Signature.Bytes(), e.RecoveryId)
+}
+
+// FromBytes sets the [Secp256k1RecoverableSignature] from its compact form, r, s, then v as 0-3 or 27-30
+//
+// Returns an error if the bytes length is not [Secp256k1RecoverableSignatureLength], s isn't low, or v is invalid, use
+// [Secp256k1RecoverableSignature.FromBytesNormalized] to accept a high s
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256k1RecoverableSignature) FromBytes(bytes []byte) (err error) {
+	if len(bytes) != Secp256k1RecoverableSignatureLength {
+		return fmt.Errorf("invalid secp256k1 recoverable signature size %d, expected %d", len(bytes), Secp256k1RecoverableSignatureLength)
+	}
+	recoveryId, err := secp256k1RecoveryId(bytes[Secp256k1SignatureLength])
+	if err != nil {
+		return err
+	}
+	signature := &Secp256k1Signature{}
+	if err = signature.FromBytes(bytes[:Secp256k1SignatureLength]); err != nil {
+		return err
+	}
+	e.Signature = signature
+	e.RecoveryId = recoveryId
+	return nil
+}
+
+// FromBytesNormalized sets the [Secp256k1RecoverableSignature] from its compact form as
+// [Secp256k1RecoverableSignature.FromBytes] does, but normalizes a high s with [Secp256k1RecoverableSignature.NormalizeS]
+// instead of rejecting it, e.g. for signatures from systems that don't enforce low-S
+//
+// Returns an error if the bytes length is not [Secp256k1RecoverableSignatureLength], or v is invalid
+func (e *Secp256k1RecoverableSignature) FromBytesNormalized(bytes []byte) (err error) {
+	if len(bytes) != Secp256k1RecoverableSignatureLength {
+		return fmt.Errorf("invalid secp256k1 recoverable signature size %d, expected %d", len(bytes), Secp256k1RecoverableSignatureLength)
+	}
+	recoveryId, err := secp256k1RecoveryId(bytes[Secp256k1SignatureLength])
+	if err != nil {
+		return err
+	}
+	var rBytes, sBytes [32]byte
+	copy(rBytes[:], bytes[0:32])
+	copy(sBytes[:], bytes[32:64])
+	r, s := &secp256k1.ModNScalar{}, &secp256k1.ModNScalar{}
+	r.SetBytes(&rBytes)
+	s.SetBytes(&sBytes)
+	e.Signature = &Secp256k1Signature{Inner: ecdsa.NewSignature(r, s)}
+	e.RecoveryId = recoveryId
+	e.NormalizeS()
+	return nil
+}
+
+// ToHex returns the hex string representation of the compact form, with a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256k1RecoverableSignature) ToHex() string {
+	return util.BytesToHex(e.Bytes())
+}
+
+// FromHex sets the [Secp256k1RecoverableSignature] from the hex of its compact form, with or without a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256k1RecoverableSignature) FromHex(hexStr string) (err error) {
+	bytes, err := util.ParseHex(hexStr)
+	if err != nil {
+		return err
+	}
+	return e.FromBytes(bytes)
+}
+
+//endregion
+//endregion
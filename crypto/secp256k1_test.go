@@ -3,6 +3,7 @@ package crypto
 import (
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/endless-labs/endless-go-sdk/bcs"
 	"github.com/endless-labs/endless-go-sdk/internal/util"
 	"github.com/stretchr/testify/assert"
@@ -181,3 +182,91 @@ func TestSecp256k1Signature_RecoverPublicKeyFromSignature(t *testing.T) {
 	assert.True(t, recoveredKey.Verify(message, signature))
 	assert.Equal(t, publicKey.ToHex(), recoveredKey.ToHex())
 }
+
+func TestSecp256k1PrivateKey_SignRecoverable(t *testing.T) {
+	privateKey := &Secp256k1PrivateKey{}
+	err := privateKey.FromHex(testSecp256k1PrivateKey)
+	assert.NoError(t, err)
+	publicKey := privateKey.VerifyingKey().(*Secp256k1PublicKey)
+	message, err := util.ParseHex(testSecp256k1MessageEncoded)
+	assert.NoError(t, err)
+
+	// The signature is the same as SignMessage's, with the recovery id
+	signature, err := privateKey.SignRecoverable(message)
+	assert.NoError(t, err)
+	assert.Equal(t, testSecp256k1Signature, signature.Signature.ToHex())
+	assert.Equal(t, byte(0), signature.V())
+	assert.Equal(t, signature.Signature.Bytes(), append(signature.R(), signature.S()...))
+	assert.True(t, publicKey.Verify(message, signature.Signature))
+
+	recovered, err := signature.RecoverPublicKey(message)
+	assert.NoError(t, err)
+	assert.Equal(t, testSecp256k1PublicKey, recovered.ToHex())
+	recovered, err = RecoverSecp256k1PublicKey(message, signature.Signature, signature.V())
+	assert.NoError(t, err)
+	assert.Equal(t, testSecp256k1PublicKey, recovered.ToHex())
+
+	// Compact form is r, s, v, and v may be 27 based
+	compact := signature.Bytes()
+	assert.Len(t, compact, Secp256k1RecoverableSignatureLength)
+	assert.Equal(t, append(signature.Signature.Bytes(), signature.V()), compact)
+	decoded := &Secp256k1RecoverableSignature{}
+	assert.NoError(t, decoded.FromHex(signature.ToHex()))
+	assert.Equal(t, signature, decoded)
+	compact[64] += 27
+	assert.NoError(t, decoded.FromBytes(compact))
+	assert.Equal(t, signature, decoded)
+	compact[64] = 4
+	assert.Error(t, decoded.FromBytes(compact))
+	assert.Error(t, decoded.FromBytes(compact[:64]))
+
+	// A signature without its recovery id can be converted with the public key
+	recoverable, err := signature.Signature.ToRecoverable(message, publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, signature, recoverable)
+	_, err = signature.Signature.ToRecoverable([]byte("another message"), publicKey)
+	assert.Error(t, err)
+}
+
+func TestSecp256k1Signature_HighS(t *testing.T) {
+	privateKey := &Secp256k1PrivateKey{}
+	err := privateKey.FromHex(testSecp256k1PrivateKey)
+	assert.NoError(t, err)
+	message, err := util.ParseHex(testSecp256k1MessageEncoded)
+	assert.NoError(t, err)
+	signature, err := privateKey.SignRecoverable(message)
+	assert.NoError(t, err)
+
+	// The high-S twin is a valid ECDSA signature, but isn't accepted
+	r, s := signature.Signature.Inner.R(), signature.Signature.Inner.S()
+	high := &Secp256k1Signature{Inner: ecdsa.NewSignature(&r, s.Negate())}
+	assert.False(t, privateKey.VerifyingKey().Verify(message, high))
+	_, err = high.RecoverPublicKey(message, signature.V()^1)
+	assert.Error(t, err)
+	highCompact := append(high.Bytes(), signature.V()^1)
+	assert.Error(t, (&Secp256k1RecoverableSignature{}).FromBytes(highCompact))
+
+	// Normalizing flips s and the parity of v, recovering the same key
+	highRecoverable := &Secp256k1RecoverableSignature{Signature: high, RecoveryId: signature.V() ^ 1}
+	assert.False(t, highRecoverable.IsLowS())
+	highRecoverable.NormalizeS()
+	assert.True(t, highRecoverable.IsLowS())
+	assert.Equal(t, signature, highRecoverable)
+	highRecoverable.NormalizeS()
+	assert.Equal(t, signature, highRecoverable)
+
+	normalized := &Secp256k1RecoverableSignature{}
+	assert.NoError(t, normalized.FromBytesNormalized(highCompact))
+	assert.Equal(t, signature, normalized)
+	recovered, err := normalized.RecoverPublicKey(message)
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey.VerifyingKey(), recovered)
+	assert.NoError(t, normalized.FromBytesNormalized(signature.Bytes()))
+	assert.Equal(t, signature, normalized)
+	highCompact[64] += 27
+	assert.NoError(t, normalized.FromBytesNormalized(highCompact))
+	assert.Equal(t, signature, normalized)
+	highCompact[64] = 4
+	assert.Error(t, normalized.FromBytesNormalized(highCompact))
+	assert.Error(t, normalized.FromBytesNormalized(highCompact[:64]))
+}
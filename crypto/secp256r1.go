@@ -0,0 +1,416 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/internal/util"
+)
+
+//region Secp256r1PrivateKey
+
+// Secp256r1PrivateKeyLength is the [Secp256r1PrivateKey] length in bytes
+const Secp256r1PrivateKeyLength = 32
+
+// Secp256r1PublicKeyLength is the [Secp256r1PublicKey] length in bytes.  We use the uncompressed version.
+const Secp256r1PublicKeyLength = 65
+
+// Secp256r1SignatureLength is the [Secp256r1Signature] length in bytes, r followed by s
+const Secp256r1SignatureLength = 64
+
+// Secp256r1PrivateKey is a P-256 ECDSA private key, as used by passkeys.  Its signatures of raw messages can't be used
+// in a [SingleSigner], on-chain it signs as a [WebAuthnSigner].
+//
+// Implements:
+//   - [MessageSigner]
+//   - [CryptoMaterial]
+type Secp256r1PrivateKey struct {
+	Inner *ecdsa.PrivateKey // Inner is the actual private key
+}
+
+// GenerateSecp256r1Key generates a new [Secp256r1PrivateKey]
+func GenerateSecp256r1Key() (*Secp256r1PrivateKey, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Secp256r1PrivateKey{priv}, nil
+}
+
+//region Secp256r1PrivateKey MessageSigner
+
+// VerifyingKey returns the corresponding public key for the private key
+//
+// Implements:
+//   - [MessageSigner]
+func (key *Secp256r1PrivateKey) VerifyingKey() VerifyingKey {
+	return &Secp256r1PublicKey{&key.Inner.PublicKey}
+}
+
+// EmptySignature creates an empty signature for use in simulation
+//
+// Implements:
+//   - [MessageSigner]
+func (key *Secp256r1PrivateKey) EmptySignature() Signature {
+	return &Secp256r1Signature{}
+}
+
+// SignMessage signs the SHA-256 of the message, returning a low-S [Secp256r1Signature]
+//
+// Implements:
+//   - [MessageSigner]
+func (key *Secp256r1PrivateKey) SignMessage(msg []byte) (sig Signature, err error) {
+	hash := sha256.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, key.Inner, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return newSecp256r1Signature(r, s), nil
+}
+
+//endregion
+
+//region Secp256r1PrivateKey CryptoMaterial
+
+// Bytes outputs the raw byte representation of the [Secp256r1PrivateKey]
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PrivateKey) Bytes() []byte {
+	return key.Inner.D.FillBytes(make([]byte, Secp256r1PrivateKeyLength))
+}
+
+// FromBytes populates the [Secp256r1PrivateKey] from bytes
+//
+// Returns an error if the bytes length is not [Secp256r1PrivateKeyLength] or the scalar is out of range
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PrivateKey) FromBytes(bytes []byte) (err error) {
+	bytes, err = ParsePrivateKey(bytes, PrivateKeyVariantSecp256r1, false)
+	if err != nil {
+		return err
+	}
+	if len(bytes) != Secp256r1PrivateKeyLength {
+		return fmt.Errorf("invalid secp256r1 private key size %d", len(bytes))
+	}
+	// ecdh validates the scalar is in range
+	if _, err = ecdh.P256().NewPrivateKey(bytes); err != nil {
+		return fmt.Errorf("invalid secp256r1 private key: %w", err)
+	}
+	curve := elliptic.P256()
+	priv := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(bytes)}
+	priv.PublicKey.Curve = curve
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(bytes)
+	key.Inner = priv
+	return nil
+}
+
+// ToHex serializes the private key to a hex string
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PrivateKey) ToHex() string {
+	return util.BytesToHex(key.Bytes())
+}
+
+// ToAIP80 formats the private key to AIP-80 compliant string
+func (key *Secp256r1PrivateKey) ToAIP80() (formattedString string, err error) {
+	return FormatPrivateKey(key.ToHex(), PrivateKeyVariantSecp256r1)
+}
+
+// FromHex populates the [Secp256r1PrivateKey] from a hex string
+//
+// Returns an error if the hex string is invalid or is not [Secp256r1PrivateKeyLength] bytes
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PrivateKey) FromHex(hexStr string) (err error) {
+	bytes, err := ParsePrivateKey(hexStr, PrivateKeyVariantSecp256r1)
+	if err != nil {
+		return err
+	}
+	return key.FromBytes(bytes)
+}
+
+//endregion
+//endregion
+
+//region Secp256r1PublicKey
+
+// Secp256r1PublicKey is the corresponding public key for [Secp256r1PrivateKey], it cannot be used on its own
+//
+// Implements:
+//   - [VerifyingKey]
+//   - [CryptoMaterial]
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type Secp256r1PublicKey struct {
+	Inner *ecdsa.PublicKey // Inner is the actual public key
+}
+
+//region Secp256r1PublicKey VerifyingKey
+
+// Verify verifies the signature of a message
+//
+// Returns true if the signature is a low-S [Secp256r1Signature] of the SHA-256 of the message, or a
+// [WebAuthnSignature] whose challenge is the message, false otherwise
+//
+// Implements:
+//   - [VerifyingKey]
+func (key *Secp256r1PublicKey) Verify(msg []byte, sig Signature) bool {
+	switch sig := sig.(type) {
+	case *Secp256r1Signature:
+		if !sig.IsLowS() {
+			return false
+		}
+		r, s := sig.rs()
+		hash := sha256.Sum256(msg)
+		return ecdsa.Verify(key.Inner, hash[:], r, s)
+	case *WebAuthnSignature:
+		return sig.verify(msg, key)
+	default:
+		return false
+	}
+}
+
+//endregion
+
+//region Secp256r1PublicKey CryptoMaterial
+
+// Bytes returns the raw bytes of the [Secp256r1PublicKey], uncompressed
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PublicKey) Bytes() []byte {
+	out := make([]byte, Secp256r1PublicKeyLength)
+	out[0] = 0x04
+	key.Inner.X.FillBytes(out[1:33])
+	key.Inner.Y.FillBytes(out[33:])
+	return out
+}
+
+// FromBytes sets the [Secp256r1PublicKey] to the given uncompressed bytes
+//
+// Returns an error if the bytes aren't [Secp256r1PublicKeyLength] or the point isn't on the curve
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PublicKey) FromBytes(bytes []byte) (err error) {
+	if len(bytes) != Secp256r1PublicKeyLength {
+		return fmt.Errorf("invalid secp256r1 public key size %d, expected %d", len(bytes), Secp256r1PublicKeyLength)
+	}
+	// ecdh validates the point is on the curve
+	if _, err = ecdh.P256().NewPublicKey(bytes); err != nil {
+		return fmt.Errorf("invalid secp256r1 public key: %w", err)
+	}
+	key.Inner = &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(bytes[1:33]),
+		Y:     new(big.Int).SetBytes(bytes[33:]),
+	}
+	return nil
+}
+
+// ToHex returns the hex string representation of the [Secp256r1PublicKey], with a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PublicKey) ToHex() string {
+	return util.BytesToHex(key.Bytes())
+}
+
+// FromHex sets the [Secp256r1PublicKey] to the bytes represented by the hex string, with or without a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (key *Secp256r1PublicKey) FromHex(hexStr string) (err error) {
+	bytes, err := util.ParseHex(hexStr)
+	if err != nil {
+		return err
+	}
+	return key.FromBytes(bytes)
+}
+
+//endregion
+
+//region Secp256r1PublicKey bcs.Struct
+
+// MarshalBCS serializes the [Secp256r1PublicKey] to BCS bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (key *Secp256r1PublicKey) MarshalBCS(ser *bcs.Serializer) {
+	ser.WriteBytes(key.Bytes())
+}
+
+// UnmarshalBCS deserializes the [Secp256r1PublicKey] from BCS bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (key *Secp256r1PublicKey) UnmarshalBCS(des *bcs.Deserializer) {
+	kb := des.ReadBytes()
+	if des.Error() != nil {
+		return
+	}
+	if err := key.FromBytes(kb); err != nil {
+		des.SetError(err)
+	}
+}
+
+//endregion
+//endregion
+
+//region Secp256r1Signature
+
+// Secp256r1Signature is a P-256 ECDSA signature, r followed by s.  Only low-S signatures are valid, use
+// [Secp256r1SignatureFromDER] for signatures from an authenticator, which may not be.
+//
+// Implements:
+//   - [Signature]
+//   - [CryptoMaterial]
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type Secp256r1Signature struct {
+	Inner [Secp256r1SignatureLength]byte // Inner is the actual signature
+}
+
+// newSecp256r1Signature creates a low-S signature from r and s
+func newSecp256r1Signature(r *big.Int, s *big.Int) *Secp256r1Signature {
+	sig := &Secp256r1Signature{}
+	r.FillBytes(sig.Inner[:32])
+	s.FillBytes(sig.Inner[32:])
+	sig.NormalizeS()
+	return sig
+}
+
+// Secp256r1SignatureFromDER converts an ASN.1 DER signature, as returned by WebAuthn authenticators, to a low-S
+// [Secp256r1Signature]
+func Secp256r1SignatureFromDER(der []byte) (*Secp256r1Signature, error) {
+	var parsed struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secp256r1 DER signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("invalid secp256r1 DER signature: trailing bytes")
+	}
+	n := elliptic.P256().Params().N
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 || parsed.R.Cmp(n) >= 0 || parsed.S.Cmp(n) >= 0 {
+		return nil, errors.New("invalid secp256r1 DER signature: r or s out of range")
+	}
+	return newSecp256r1Signature(parsed.R, parsed.S), nil
+}
+
+// rs returns r and s of the signature
+func (e *Secp256r1Signature) rs() (*big.Int, *big.Int) {
+	return new(big.Int).SetBytes(e.Inner[:32]), new(big.Int).SetBytes(e.Inner[32:])
+}
+
+// IsLowS returns true if s is at most half the curve order, the only form accepted on-chain
+func (e *Secp256r1Signature) IsLowS() bool {
+	_, s := e.rs()
+	halfOrder := new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+	return s.Cmp(halfOrder) <= 0
+}
+
+// NormalizeS replaces s with n - s if s is over half the curve order.  Both are valid ECDSA signatures of the
+// message, but only the low-S one is accepted on-chain.
+func (e *Secp256r1Signature) NormalizeS() {
+	if e.IsLowS() {
+		return
+	}
+	_, s := e.rs()
+	s.Sub(elliptic.P256().Params().N, s)
+	s.FillBytes(e.Inner[32:])
+}
+
+//region Secp256r1Signature CryptoMaterial
+
+// Bytes returns the raw bytes of the [Secp256r1Signature]
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256r1Signature) Bytes() []byte {
+	return e.Inner[:]
+}
+
+// FromBytes sets the [Secp256r1Signature] to the given bytes
+//
+// Returns an error if the bytes length is not [Secp256r1SignatureLength], or s isn't low
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256r1Signature) FromBytes(bytes []byte) (err error) {
+	if len(bytes) != Secp256r1SignatureLength {
+		return fmt.Errorf("invalid secp256r1 signature size %d, expected %d", len(bytes), Secp256r1SignatureLength)
+	}
+	sig := Secp256r1Signature{}
+	copy(sig.Inner[:], bytes)
+	if !sig.IsLowS() {
+		return errors.New("invalid secp256r1 signature: s is over half order")
+	}
+	e.Inner = sig.Inner
+	return nil
+}
+
+// ToHex returns the hex string representation of the [Secp256r1Signature], with a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256r1Signature) ToHex() string {
+	return util.BytesToHex(e.Bytes())
+}
+
+// FromHex sets the [Secp256r1Signature] to the bytes represented by the hex string, with or without a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *Secp256r1Signature) FromHex(hexStr string) (err error) {
+	bytes, err := util.ParseHex(hexStr)
+	if err != nil {
+		return err
+	}
+	return e.FromBytes(bytes)
+}
+
+//endregion
+
+//region Secp256r1Signature bcs.Struct
+
+// MarshalBCS serializes the [Secp256r1Signature] to BCS bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (e *Secp256r1Signature) MarshalBCS(ser *bcs.Serializer) {
+	ser.WriteBytes(e.Bytes())
+}
+
+// UnmarshalBCS deserializes the [Secp256r1Signature] from BCS bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (e *Secp256r1Signature) UnmarshalBCS(des *bcs.Deserializer) {
+	bytes := des.ReadBytes()
+	if des.Error() != nil {
+		return
+	}
+	if err := e.FromBytes(bytes); err != nil {
+		des.SetError(err)
+	}
+}
+
+//endregion
+//endregion
@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSecp256r1PrivateKey    = "secp256r1-priv-0x0000000000000000000000000000000000000000000000000000000000000001"
+	testSecp256r1PrivateKeyHex = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	// testSecp256r1PublicKey is the generator of P-256, the public key of private key 1
+	testSecp256r1PublicKey = "0x046b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c2964fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"
+)
+
+func TestSecp256r1Keys(t *testing.T) {
+	privateKey := &Secp256r1PrivateKey{}
+	assert.NoError(t, privateKey.FromHex(testSecp256r1PrivateKey))
+	privateKey2 := &Secp256r1PrivateKey{}
+	assert.NoError(t, privateKey2.FromHex(testSecp256r1PrivateKeyHex))
+	assert.Equal(t, privateKey.Bytes(), privateKey2.Bytes())
+	assert.Equal(t, testSecp256r1PrivateKeyHex, privateKey.ToHex())
+	formattedString, err := privateKey.ToAIP80()
+	assert.NoError(t, err)
+	assert.Equal(t, testSecp256r1PrivateKey, formattedString)
+	assert.Equal(t, testSecp256r1PublicKey, privateKey.VerifyingKey().ToHex())
+
+	// Out of range scalars and points off the curve are rejected
+	assert.Error(t, (&Secp256r1PrivateKey{}).FromBytes(make([]byte, Secp256r1PrivateKeyLength)))
+	publicKey := &Secp256r1PublicKey{}
+	assert.NoError(t, publicKey.FromHex(testSecp256r1PublicKey))
+	offCurve := publicKey.Bytes()
+	offCurve[64] ^= 1
+	assert.Error(t, (&Secp256r1PublicKey{}).FromBytes(offCurve))
+
+	// Serialization is the uncompressed key with a length prefix
+	publicKeyBytes, err := bcs.Serialize(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{Secp256r1PublicKeyLength}, publicKey.Bytes()...), publicKeyBytes)
+	decoded := &Secp256r1PublicKey{}
+	assert.NoError(t, bcs.Deserialize(decoded, publicKeyBytes))
+	assert.Equal(t, publicKey.Bytes(), decoded.Bytes())
+
+	anyPublicKey, err := ToAnyPublicKey(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, AnyPublicKeyVariantSecp256r1, anyPublicKey.Variant)
+	decodedAny := &AnyPublicKey{}
+	assert.NoError(t, decodedAny.FromBytes(anyPublicKey.Bytes()))
+	assert.Equal(t, anyPublicKey.Bytes(), decodedAny.Bytes())
+}
+
+func TestSecp256r1Signature_LowS(t *testing.T) {
+	privateKey, err := GenerateSecp256r1Key()
+	assert.NoError(t, err)
+	publicKey := privateKey.VerifyingKey()
+	message := []byte("hello world")
+
+	for i := 0; i < 8; i++ {
+		signature, err := privateKey.SignMessage(message)
+		assert.NoError(t, err)
+		assert.True(t, signature.(*Secp256r1Signature).IsLowS())
+		assert.True(t, publicKey.Verify(message, signature))
+	}
+
+	// A high-S signature is valid ECDSA, but is rejected until normalized
+	hash := sha256.Sum256(message)
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey.Inner, hash[:])
+	assert.NoError(t, err)
+	n := elliptic.P256().Params().N
+	if s.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+		s.Sub(n, s)
+	}
+	high := &Secp256r1Signature{}
+	r.FillBytes(high.Inner[:32])
+	s.FillBytes(high.Inner[32:])
+	assert.False(t, high.IsLowS())
+	assert.False(t, publicKey.Verify(message, high))
+	assert.Error(t, (&Secp256r1Signature{}).FromBytes(high.Bytes()))
+
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.NoError(t, err)
+	fromDER, err := Secp256r1SignatureFromDER(der)
+	assert.NoError(t, err)
+	assert.True(t, fromDER.IsLowS())
+	assert.True(t, publicKey.Verify(message, fromDER))
+	high.NormalizeS()
+	assert.Equal(t, fromDER, high)
+
+	_, err = Secp256r1SignatureFromDER(append(der, 0))
+	assert.Error(t, err)
+}
+
+func TestWebAuthnSigner(t *testing.T) {
+	privateKey, err := GenerateSecp256r1Key()
+	assert.NoError(t, err)
+	signer := NewSingleSigner(NewWebAuthnSigner(privateKey, "example.com", "https://example.com"))
+	message := []byte("transaction signing message")
+
+	authenticator, err := signer.Sign(message)
+	assert.NoError(t, err)
+	assert.True(t, authenticator.Verify(message))
+	assert.False(t, authenticator.Verify([]byte("another message")))
+	assert.Equal(t, AnyPublicKeyVariantSecp256r1, authenticator.PubKey().(*AnyPublicKey).Variant)
+	anySignature := authenticator.Signature().(*AnySignature)
+	assert.Equal(t, AnySignatureVariantWebAuthn, anySignature.Variant)
+
+	// The challenge is the SHA3-256 of the message
+	signature := anySignature.Signature.(*WebAuthnSignature)
+	challenge, err := signature.Challenge()
+	assert.NoError(t, err)
+	assert.Equal(t, WebAuthnChallenge(message), challenge)
+
+	// Round trip through BCS
+	authenticatorBytes, err := bcs.Serialize(authenticator)
+	assert.NoError(t, err)
+	decoded := &AccountAuthenticator{}
+	assert.NoError(t, bcs.Deserialize(decoded, authenticatorBytes))
+	assert.True(t, decoded.Verify(message))
+	assert.Equal(t, signer.PubKey(), decoded.PubKey())
+
+	// An assertion from a browser, with the authenticator's DER signature
+	clientData := map[string]any{}
+	assert.NoError(t, json.Unmarshal(signature.ClientDataJSON, &clientData))
+	clientData["crossOrigin"] = false
+	clientDataJSON, err := json.Marshal(clientData)
+	assert.NoError(t, err)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	hash := sha256.Sum256(append(append([]byte{}, signature.AuthenticatorData...), clientDataHash[:]...))
+	der, err := ecdsa.SignASN1(rand.Reader, privateKey.Inner, hash[:])
+	assert.NoError(t, err)
+	assembled, err := NewWebAuthnSignature(signature.AuthenticatorData, clientDataJSON, der)
+	assert.NoError(t, err)
+	assert.True(t, signer.PubKey().Verify(message, assembled))
+
+	// Tampering with the client data or authenticator data fails verification
+	assembled.ClientDataJSON = append([]byte{}, signature.ClientDataJSON...)
+	assert.False(t, signer.PubKey().Verify(message, assembled))
+	tampered := &WebAuthnSignature{Signature: signature.Signature, AuthenticatorData: append([]byte{1}, signature.AuthenticatorData[1:]...), ClientDataJSON: signature.ClientDataJSON}
+	assert.False(t, signer.PubKey().Verify(message, tampered))
+
+	simulation := signer.SimulationAuthenticator()
+	assert.Equal(t, AnySignatureVariantWebAuthn, simulation.Signature().(*AnySignature).Variant)
+	_, err = bcs.Serialize(simulation)
+	assert.NoError(t, err)
+}
+
+func TestSingleSigner_Unsupported(t *testing.T) {
+	// Raw P-256 signatures can't be used on-chain, only as WebAuthn assertions
+	privateKey, err := GenerateSecp256r1Key()
+	assert.NoError(t, err)
+	signer := NewSingleSigner(privateKey)
+	_, err = signer.SignatureVariantE()
+	assert.Error(t, err)
+	assert.Equal(t, AnySignatureVariantEd25519, signer.SignatureVariant())
+	_, err = signer.Sign([]byte("hello"))
+	assert.Error(t, err)
+	assert.Nil(t, signer.SimulationAuthenticator())
+	assert.Equal(t, NewSingleSigner(NewWebAuthnSigner(privateKey, "", "")).AuthKey(), signer.AuthKey())
+
+	// Keys without an AnyPublicKey variant have no authentication key
+	unsupported := NewSingleSigner(&testUnsupportedSigner{privateKey})
+	assert.Nil(t, unsupported.PubKey())
+	assert.Nil(t, unsupported.AuthKey())
+}
+
+// testUnsupportedSigner signs with a key that has no [AnyPublicKey] variant
+type testUnsupportedSigner struct {
+	*Secp256r1PrivateKey
+}
+
+func (signer *testUnsupportedSigner) VerifyingKey() VerifyingKey {
+	return &MultiEd25519PublicKey{}
+}
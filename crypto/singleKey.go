@@ -23,33 +23,58 @@ func NewSingleSigner(input MessageSigner) *SingleSigner {
 	return &SingleSigner{Signer: input}
 }
 
+// SignatureVariant is the [AnySignatureVariant] of the signer's signatures, defaulting to
+// [AnySignatureVariantEd25519] if the signer is unsupported, use [SingleSigner.SignatureVariantE] to detect that
 func (key *SingleSigner) SignatureVariant() AnySignatureVariant {
-	sigType := AnySignatureVariantEd25519
+	variant, err := key.SignatureVariantE()
+	if err != nil {
+		return AnySignatureVariantEd25519
+	}
+	return variant
+}
+
+// SignatureVariantE is the [AnySignatureVariant] of the signer's signatures
+//
+// Returns an error if the signer's signatures can't be used in an [AnySignature], e.g. a [Secp256r1PrivateKey], which
+// signs on-chain as a [WebAuthnSigner]
+func (key *SingleSigner) SignatureVariantE() (AnySignatureVariant, error) {
 	switch key.Signer.(type) {
 	case *Ed25519PrivateKey:
-		sigType = AnySignatureVariantEd25519
+		return AnySignatureVariantEd25519, nil
 	case *Secp256k1PrivateKey:
-		sigType = AnySignatureVariantSecp256k1
+		return AnySignatureVariantSecp256k1, nil
+	case *WebAuthnSigner:
+		return AnySignatureVariantWebAuthn, nil
+	default:
+		return 0, fmt.Errorf("unsupported single signer %T", key.Signer)
 	}
-	return sigType
 }
 
 // SignMessage similar, but doesn't implement [MessageSigner] so there's no circular usage
 func (key *SingleSigner) SignMessage(msg []byte) (Signature, error) {
+	variant, err := key.SignatureVariantE()
+	if err != nil {
+		return nil, err
+	}
 	signature, err := key.Signer.SignMessage(msg)
 	if err != nil {
 		return nil, err
 	}
 
 	return &AnySignature{
-		Variant:   key.SignatureVariant(),
+		Variant:   variant,
 		Signature: signature,
 	}, nil
 }
 
+// EmptySignature creates an empty [AnySignature] for use in simulation, nil if the signer is unsupported
 func (key *SingleSigner) EmptySignature() *AnySignature {
+	variant, err := key.SignatureVariantE()
+	if err != nil {
+		return nil
+	}
 	return &AnySignature{
-		Variant:   key.SignatureVariant(),
+		Variant:   variant,
 		Signature: key.Signer.EmptySignature(),
 	}
 }
@@ -65,9 +90,13 @@ func (key *SingleSigner) Sign(msg []byte) (authenticator *AccountAuthenticator,
 	if err != nil {
 		return nil, err
 	}
+	pubKey, ok := key.PubKey().(*AnyPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported single signer key %T", key.Signer.VerifyingKey())
+	}
 
 	auth := &SingleKeyAuthenticator{}
-	auth.PubKey = key.PubKey().(*AnyPublicKey)
+	auth.PubKey = pubKey
 	auth.Sig = signature.(*AnySignature)
 
 	return &AccountAuthenticator{
@@ -76,48 +105,51 @@ func (key *SingleSigner) Sign(msg []byte) (authenticator *AccountAuthenticator,
 	}, nil
 }
 
-// SimulationAuthenticator creates a new [AccountAuthenticator] for simulation purposes
+// SimulationAuthenticator creates a new [AccountAuthenticator] for simulation purposes, nil if the signer is
+// unsupported
 //
 // Implements:
 //   - [Signer]
 func (key *SingleSigner) SimulationAuthenticator() *AccountAuthenticator {
+	pubKey, ok := key.PubKey().(*AnyPublicKey)
+	signature := key.EmptySignature()
+	if !ok || signature == nil {
+		return nil
+	}
 	return &AccountAuthenticator{
 		Variant: AccountAuthenticatorSingleSender,
 		Auth: &SingleKeyAuthenticator{
-			PubKey: key.PubKey().(*AnyPublicKey),
-			Sig:    key.EmptySignature(),
+			PubKey: pubKey,
+			Sig:    signature,
 		},
 	}
 }
 
-// AuthKey gives the [AuthenticationKey] associated with the [Signer]
+// AuthKey gives the [AuthenticationKey] associated with the [Signer], or nil if the signer's key is unsupported
 //
 // Implements:
 //   - [Signer]
 func (key *SingleSigner) AuthKey() *AuthenticationKey {
+	pubKey := key.PubKey()
+	if pubKey == nil {
+		return nil
+	}
 	out := &AuthenticationKey{}
-	out.FromPublicKey(key.PubKey())
+	out.FromPublicKey(pubKey)
 	return out
 }
 
-// PubKey Retrieve the [PublicKey] for [Signature] verification
+// PubKey Retrieve the [PublicKey] for [Signature] verification, an [AnyPublicKey], or nil if the signer's key is
+// unsupported
 //
 // Implements:
 //   - [Signer]
 func (key *SingleSigner) PubKey() PublicKey {
-	innerPubKey := key.Signer.VerifyingKey()
-	keyType := AnyPublicKeyVariantEd25519
-	switch key.Signer.(type) {
-	case *Ed25519PrivateKey:
-		keyType = AnyPublicKeyVariantEd25519
-	case *Secp256k1PrivateKey:
-		keyType = AnyPublicKeyVariantSecp256k1
-	}
-
-	return &AnyPublicKey{
-		Variant: keyType,
-		PubKey:  innerPubKey,
+	pubKey, err := ToAnyPublicKey(key.Signer.VerifyingKey())
+	if err != nil {
+		return nil
 	}
+	return pubKey
 }
 
 //endregion
@@ -131,6 +163,7 @@ type AnyPublicKeyVariant uint32
 const (
 	AnyPublicKeyVariantEd25519   AnyPublicKeyVariant = 0 // AnyPublicKeyVariantEd25519 is the variant for [Ed25519PublicKey]
 	AnyPublicKeyVariantSecp256k1 AnyPublicKeyVariant = 1 // AnyPublicKeyVariantSecp256k1 is the variant for [Secp256k1PublicKey]
+	AnyPublicKeyVariantSecp256r1 AnyPublicKeyVariant = 2 // AnyPublicKeyVariantSecp256r1 is the variant for [Secp256r1PublicKey]
 )
 
 // AnyPublicKey is used by SingleSigner and MultiKey to allow for using different keys with the same structs
@@ -148,6 +181,8 @@ func ToAnyPublicKey(key VerifyingKey) (*AnyPublicKey, error) {
 		out.Variant = AnyPublicKeyVariantEd25519
 	case *Secp256k1PublicKey:
 		out.Variant = AnyPublicKeyVariantSecp256k1
+	case *Secp256r1PublicKey:
+		out.Variant = AnyPublicKeyVariantSecp256r1
 	case *AnyPublicKey:
 		// Passthrough for conversion
 		return key.(*AnyPublicKey), nil
@@ -158,6 +193,19 @@ func ToAnyPublicKey(key VerifyingKey) (*AnyPublicKey, error) {
 	return out, nil
 }
 
+// EmptySignature creates an empty [AnySignature] of the key's type, for simulating transactions without the
+// private key
+func (key *AnyPublicKey) EmptySignature() *AnySignature {
+	switch key.Variant {
+	case AnyPublicKeyVariantSecp256k1:
+		return &AnySignature{Variant: AnySignatureVariantSecp256k1, Signature: (&Secp256k1PrivateKey{}).EmptySignature()}
+	case AnyPublicKeyVariantSecp256r1:
+		return &AnySignature{Variant: AnySignatureVariantWebAuthn, Signature: (&WebAuthnSigner{}).EmptySignature()}
+	default:
+		return &AnySignature{Variant: AnySignatureVariantEd25519, Signature: &Ed25519Signature{}}
+	}
+}
+
 //region AnyPublicKey VerifyingKey implementation
 
 // Verify verifies the signature against the message
@@ -172,6 +220,8 @@ func (key *AnyPublicKey) Verify(msg []byte, sig Signature) bool {
 		return key.PubKey.Verify(msg, sig)
 	case *Secp256k1Signature:
 		return key.PubKey.Verify(msg, sig)
+	case *WebAuthnSignature:
+		return key.PubKey.Verify(msg, sig)
 	default:
 		return false
 	}
@@ -264,6 +314,8 @@ func (key *AnyPublicKey) UnmarshalBCS(des *bcs.Deserializer) {
 		key.PubKey = &Ed25519PublicKey{}
 	case AnyPublicKeyVariantSecp256k1:
 		key.PubKey = &Secp256k1PublicKey{}
+	case AnyPublicKeyVariantSecp256r1:
+		key.PubKey = &Secp256r1PublicKey{}
 	default:
 		des.SetError(fmt.Errorf("unknown public key variant: %d", key.Variant))
 		return
@@ -282,6 +334,7 @@ type AnySignatureVariant uint32
 const (
 	AnySignatureVariantEd25519   AnySignatureVariant = 0 // AnySignatureVariantEd25519 is the variant for [Ed25519Signature]
 	AnySignatureVariantSecp256k1 AnySignatureVariant = 1 // AnySignatureVariantSecp256k1 is the variant for [Secp256k1Signature]
+	AnySignatureVariantWebAuthn  AnySignatureVariant = 2 // AnySignatureVariantWebAuthn is the variant for [WebAuthnSignature], signed by a [Secp256r1PublicKey]
 )
 
 // AnySignature is a wrapper around signatures signed with SingleSigner and verified with AnyPublicKey
@@ -360,6 +413,8 @@ func (e *AnySignature) UnmarshalBCS(des *bcs.Deserializer) {
 		e.Signature = &Ed25519Signature{}
 	case AnySignatureVariantSecp256k1:
 		e.Signature = &Secp256k1Signature{}
+	case AnySignatureVariantWebAuthn:
+		e.Signature = &WebAuthnSignature{}
 	default:
 		des.SetError(fmt.Errorf("unknown signature variant: %d", e.Variant))
 		return
@@ -0,0 +1,249 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/internal/util"
+)
+
+// WebAuthnAssertionType is the type of the client data of a WebAuthn assertion, i.e. navigator.credentials.get
+const WebAuthnAssertionType = "webauthn.get"
+
+// WebAuthnChallenge is the challenge a passkey must sign for the message, e.g. a transaction's signing message.  It's
+// the SHA3-256 of the message, and is base64url encoded in the client data.
+func WebAuthnChallenge(msg []byte) []byte {
+	return util.Sha3256Hash([][]byte{msg})
+}
+
+//region WebAuthnSignature
+
+// webAuthnAssertionSignatureSecp256r1 is the only AssertionSignature variant, a P-256 ECDSA signature
+const webAuthnAssertionSignatureSecp256r1 = 0
+
+// WebAuthnSignature is the signature of a passkey, a [Secp256r1Signature] of the authenticator data and SHA-256 of the
+// client data JSON, whose challenge is the [WebAuthnChallenge] of the message.  It's a
+// PartialAuthenticatorAssertionResponse on-chain.
+//
+// Assemble one from a browser's assertion response with [NewWebAuthnSignature].
+//
+// Implements:
+//   - [Signature]
+//   - [CryptoMaterial]
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type WebAuthnSignature struct {
+	Signature         *Secp256r1Signature // Signature is the low-S signature of the authenticator data and client data hash
+	AuthenticatorData []byte              // AuthenticatorData is the authenticator's data, as returned by the authenticator
+	ClientDataJSON    []byte              // ClientDataJSON is the client data, as returned by the browser
+}
+
+// NewWebAuthnSignature creates a [WebAuthnSignature] from the fields of an AuthenticatorAssertionResponse.  The
+// signature is ASN.1 DER as returned by the authenticator, and is normalized to low-S.
+func NewWebAuthnSignature(authenticatorData []byte, clientDataJSON []byte, derSignature []byte) (*WebAuthnSignature, error) {
+	signature, err := Secp256r1SignatureFromDER(derSignature)
+	if err != nil {
+		return nil, err
+	}
+	return &WebAuthnSignature{Signature: signature, AuthenticatorData: authenticatorData, ClientDataJSON: clientDataJSON}, nil
+}
+
+// webAuthnClientData is the fields of the client data used for verification
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// Challenge returns the challenge signed, decoded from the client data
+func (e *WebAuthnSignature) Challenge() ([]byte, error) {
+	clientData := webAuthnClientData{}
+	if err := json.Unmarshal(e.ClientDataJSON, &clientData); err != nil {
+		return nil, fmt.Errorf("invalid webauthn client data: %w", err)
+	}
+	if clientData.Type != WebAuthnAssertionType {
+		return nil, fmt.Errorf("invalid webauthn client data type %s, expected %s", clientData.Type, WebAuthnAssertionType)
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webauthn challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// verificationData is the data signed by the authenticator, the authenticator data followed by the SHA-256 of the
+// client data JSON
+func (e *WebAuthnSignature) verificationData() []byte {
+	clientDataHash := sha256.Sum256(e.ClientDataJSON)
+	return append(append([]byte{}, e.AuthenticatorData...), clientDataHash[:]...)
+}
+
+// verify checks the challenge is of the message, and the signature is of the authenticator data and client data
+func (e *WebAuthnSignature) verify(msg []byte, key *Secp256r1PublicKey) bool {
+	challenge, err := e.Challenge()
+	if err != nil || !bytes.Equal(challenge, WebAuthnChallenge(msg)) {
+		return false
+	}
+	return e.Signature != nil && key.Verify(e.verificationData(), e.Signature)
+}
+
+//region WebAuthnSignature CryptoMaterial
+
+// Bytes returns the BCS bytes of the [WebAuthnSignature]
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *WebAuthnSignature) Bytes() []byte {
+	val, _ := bcs.Serialize(e)
+	return val
+}
+
+// FromBytes sets the [WebAuthnSignature] to the given BCS bytes
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *WebAuthnSignature) FromBytes(bytes []byte) (err error) {
+	return bcs.Deserialize(e, bytes)
+}
+
+// ToHex returns the hex string representation of the [WebAuthnSignature], with a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *WebAuthnSignature) ToHex() string {
+	return util.BytesToHex(e.Bytes())
+}
+
+// FromHex sets the [WebAuthnSignature] to the bytes represented by the hex string, with or without a leading 0x
+//
+// Implements:
+//   - [CryptoMaterial]
+func (e *WebAuthnSignature) FromHex(hexStr string) (err error) {
+	bytes, err := util.ParseHex(hexStr)
+	if err != nil {
+		return err
+	}
+	return e.FromBytes(bytes)
+}
+
+//endregion
+
+//region WebAuthnSignature bcs.Struct
+
+// MarshalBCS serializes the [WebAuthnSignature] to BCS bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (e *WebAuthnSignature) MarshalBCS(ser *bcs.Serializer) {
+	if e.Signature == nil {
+		ser.SetError(errors.New("webauthn signature has no signature"))
+		return
+	}
+	ser.Uleb128(webAuthnAssertionSignatureSecp256r1)
+	ser.Struct(e.Signature)
+	ser.WriteBytes(e.AuthenticatorData)
+	ser.WriteBytes(e.ClientDataJSON)
+}
+
+// UnmarshalBCS deserializes the [WebAuthnSignature] from BCS bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (e *WebAuthnSignature) UnmarshalBCS(des *bcs.Deserializer) {
+	variant := des.Uleb128()
+	if des.Error() != nil {
+		return
+	}
+	if variant != webAuthnAssertionSignatureSecp256r1 {
+		des.SetError(fmt.Errorf("unknown webauthn assertion signature variant: %d", variant))
+		return
+	}
+	e.Signature = &Secp256r1Signature{}
+	des.Struct(e.Signature)
+	e.AuthenticatorData = des.ReadBytes()
+	e.ClientDataJSON = des.ReadBytes()
+}
+
+//endregion
+//endregion
+
+//region WebAuthnSigner
+
+// webAuthnFlagsUserPresentVerified are the authenticator data flags for a user present and verified
+const webAuthnFlagsUserPresentVerified = 0x05
+
+// WebAuthnSigner is a software passkey, signing WebAuthn assertions with a [Secp256r1PrivateKey] as a browser and
+// authenticator would.  It's for tests, and for services holding the key of a passkey-style account.  Use it with
+// [SingleSigner] to sign transactions.
+//
+//	signer := crypto.NewSingleSigner(crypto.NewWebAuthnSigner(privateKey, "example.com", "https://example.com"))
+//
+// Implements:
+//   - [MessageSigner]
+type WebAuthnSigner struct {
+	PrivateKey     *Secp256r1PrivateKey // PrivateKey is the passkey's private key
+	RelyingPartyId string               // RelyingPartyId is the domain the passkey is for, hashed in the authenticator data
+	Origin         string               // Origin is the origin of the page requesting the assertion, in the client data
+}
+
+// NewWebAuthnSigner creates a [WebAuthnSigner] for the relying party and origin
+func NewWebAuthnSigner(privateKey *Secp256r1PrivateKey, relyingPartyId string, origin string) *WebAuthnSigner {
+	return &WebAuthnSigner{PrivateKey: privateKey, RelyingPartyId: relyingPartyId, Origin: origin}
+}
+
+// SignMessage signs an assertion whose challenge is the [WebAuthnChallenge] of the message, returning a
+// [WebAuthnSignature]
+//
+// Implements:
+//   - [MessageSigner]
+func (key *WebAuthnSigner) SignMessage(msg []byte) (Signature, error) {
+	clientDataJSON, err := json.Marshal(webAuthnClientData{
+		Type:      WebAuthnAssertionType,
+		Challenge: base64.RawURLEncoding.EncodeToString(WebAuthnChallenge(msg)),
+		Origin:    key.Origin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rpIdHash := sha256.Sum256([]byte(key.RelyingPartyId))
+	authenticatorData := append(rpIdHash[:], webAuthnFlagsUserPresentVerified)
+	authenticatorData = binary.BigEndian.AppendUint32(authenticatorData, 0)
+
+	signature := &WebAuthnSignature{AuthenticatorData: authenticatorData, ClientDataJSON: clientDataJSON}
+	hash := sha256.Sum256(signature.verificationData())
+	der, err := ecdsa.SignASN1(rand.Reader, key.PrivateKey.Inner, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if signature.Signature, err = Secp256r1SignatureFromDER(der); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// EmptySignature creates an empty signature for use in simulation
+//
+// Implements:
+//   - [MessageSigner]
+func (key *WebAuthnSigner) EmptySignature() Signature {
+	return &WebAuthnSignature{Signature: &Secp256r1Signature{}}
+}
+
+// VerifyingKey returns the [Secp256r1PublicKey] of the passkey
+//
+// Implements:
+//   - [MessageSigner]
+func (key *WebAuthnSigner) VerifyingKey() VerifyingKey {
+	return key.PrivateKey.VerifyingKey()
+}
+
+//endregion
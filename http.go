@@ -1,10 +1,14 @@
 package endless
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/endless-labs/endless-go-sdk/api"
 )
 
 // HttpErrSummaryLength is the maximum length of the body to include in the error message
@@ -53,3 +57,27 @@ func (he *HttpError) Error() string {
 		)
 	}
 }
+
+// ApiError parses the body of the HttpError as an [api.Error], returns false if the body is not one
+func (he *HttpError) ApiError() (*api.Error, bool) {
+	apiError := &api.Error{}
+	err := json.Unmarshal(he.Body, apiError)
+	if err != nil || (apiError.Message == "" && apiError.ErrorCode == "") {
+		return nil, false
+	}
+	return apiError, true
+}
+
+// ApiErrorFromError unwraps an error returned by the client to the [api.Error] returned by the node, returns false if
+// the error did not come from the node
+func ApiErrorFromError(err error) (*api.Error, bool) {
+	var submissionError *TransactionSubmissionError
+	if errors.As(err, &submissionError) {
+		return &submissionError.ApiError, true
+	}
+	var httpError *HttpError
+	if !errors.As(err, &httpError) {
+		return nil, false
+	}
+	return httpError.ApiError()
+}
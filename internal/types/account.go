@@ -34,7 +34,11 @@ func NewAccountFromSigner(signer crypto.Signer, authKey ...crypto.Authentication
 	} else {
 		//log.Printf("out.Address[:] = %#v \n", out.Address[:])
 		//log.Printf("signer.AuthKey()[:]] = %#v \n", signer.AuthKey()[:])
-		copy(out.Address[:], signer.AuthKey()[:])
+		signerAuthKey := signer.AuthKey()
+		if signerAuthKey == nil {
+			return nil, errors.New("signer has no authentication key, its key is unsupported")
+		}
+		copy(out.Address[:], signerAuthKey[:])
 	}
 
 	out.Signer = signer
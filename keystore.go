@@ -0,0 +1,510 @@
+package endless
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedKeyVersion is the version of the [EncryptedKey] file format
+const EncryptedKeyVersion = 1
+
+// Keystore KDF and cipher names, as written in [EncryptedKeyCrypto]
+const (
+	KeystoreKdfScrypt   = "scrypt"
+	KeystoreKdfArgon2id = "argon2id"
+	KeystoreCipher      = "aes-256-gcm"
+)
+
+// ErrKeystorePassword is returned when an [EncryptedKey] can't be decrypted, either from a wrong password or a modified file
+var ErrKeystorePassword = errors.New("could not decrypt key, wrong password or corrupted keystore")
+
+// ErrKeystoreNotFound is returned by [Keystore] when there is no key for the address
+var ErrKeystoreNotFound = errors.New("key not found in keystore")
+
+// ErrKeystoreExists is returned by [Keystore.Import] when there is already a key for the address
+var ErrKeystoreExists = errors.New("key already exists in keystore")
+
+// KeystoreScrypt is an option to [EncryptKey] and [NewKeystore] to use scrypt as the KDF.  This is the default, with
+// N=262144, R=8, P=1.  N is at most 2^20, R at most 32, P at most 16, and the memory used, 128*N*R bytes, at most 1 GiB.
+type KeystoreScrypt struct {
+	N int // N is the CPU/memory cost, a power of 2
+	R int // R is the block size
+	P int // P is the parallelization
+}
+
+// KeystoreArgon2id is an option to [EncryptKey] and [NewKeystore] to use argon2id as the KDF.  Zero fields default to
+// Time=3, Memory=65536 (64 MiB), Threads=4.  Time is at most 64, and Memory at most 1048576 (1 GiB).
+type KeystoreArgon2id struct {
+	Time    uint32 // Time is the number of passes
+	Memory  uint32 // Memory is in KiB
+	Threads uint8  // Threads is the parallelism
+}
+
+// EncryptedKey is a password encrypted private key, in a JSON keystore file.
+//
+// The private key is encrypted with AES-256-GCM, using a key derived from the password with scrypt or argon2id.  The
+// key variant, public key, and address are in the clear so keys can be listed without a password, and are
+// authenticated by the cipher so they can't be changed without the password.
+//
+// Example:
+//
+//	{
+//	  "version": 1,
+//	  "id": "0x3c5d...",
+//	  "address": "8R8cEYe5...",
+//	  "variant": "ed25519",
+//	  "single_key": false,
+//	  "public_key": "0x1a2b...",
+//	  "crypto": {
+//	    "cipher": "aes-256-gcm",
+//	    "ciphertext": "0x...",
+//	    "nonce": "0x...",
+//	    "kdf": "scrypt",
+//	    "kdf_params": {"salt": "0x...", "n": 262144, "r": 8, "p": 1}
+//	  }
+//	}
+type EncryptedKey struct {
+	Version   uint8                    `json:"version"`
+	Id        string                   `json:"id"`         // Id is a random identifier for the key file
+	Address   string                   `json:"address"`    // Address is the account address, which may differ from the derived address after key rotation
+	Variant   crypto.PrivateKeyVariant `json:"variant"`    // Variant is the type of private key
+	SingleKey bool                     `json:"single_key"` // SingleKey is true for an Ed25519 key used as a single key account, rather than a legacy Ed25519 account
+	PublicKey string                   `json:"public_key"` // PublicKey is the hex public key of the private key
+	Crypto    EncryptedKeyCrypto       `json:"crypto"`
+}
+
+// EncryptedKeyCrypto is the encryption parameters and ciphertext of an [EncryptedKey]
+type EncryptedKeyCrypto struct {
+	Cipher     string            `json:"cipher"`
+	Ciphertext string            `json:"ciphertext"`
+	Nonce      string            `json:"nonce"`
+	Kdf        string            `json:"kdf"`
+	KdfParams  KeystoreKdfParams `json:"kdf_params"`
+}
+
+// KeystoreKdfParams are the parameters of the KDF, only those for the KDF are set
+type KeystoreKdfParams struct {
+	Salt string `json:"salt"`
+	// scrypt
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+	// argon2id
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// Maximum KDF parameters, so a crafted keystore file can't make decryption allocate or run without bound
+const (
+	keystoreMaxScryptN      = 1 << 20
+	keystoreMaxScryptR      = 32
+	keystoreMaxScryptP      = 16
+	keystoreMaxScryptMemory = 1 << 30 // keystoreMaxScryptMemory is in bytes, scrypt uses 128*N*R
+	keystoreMaxArgon2Time   = 64
+	keystoreMaxArgon2Memory = 1 << 20 // keystoreMaxArgon2Memory is in KiB
+)
+
+// keystoreKdf picks the KDF parameters from the options
+func keystoreKdf(options ...any) (string, KeystoreKdfParams, error) {
+	kdf := KeystoreKdfScrypt
+	params := KeystoreKdfParams{N: 1 << 18, R: 8, P: 1}
+	for i, arg := range options {
+		switch value := arg.(type) {
+		case KeystoreScrypt:
+			kdf = KeystoreKdfScrypt
+			params = KeystoreKdfParams{N: value.N, R: value.R, P: value.P}
+		case KeystoreArgon2id:
+			kdf = KeystoreKdfArgon2id
+			params = KeystoreKdfParams{Time: value.Time, Memory: value.Memory, Threads: value.Threads}
+			if params.Time == 0 {
+				params.Time = 3
+			}
+			if params.Memory == 0 {
+				params.Memory = 64 * 1024
+			}
+			if params.Threads == 0 {
+				params.Threads = 4
+			}
+		default:
+			return "", params, fmt.Errorf("EncryptKey arg [%d] unknown option type %T", i+3, arg)
+		}
+	}
+	return kdf, params, nil
+}
+
+// deriveKey derives the AES key from the password
+func (c *EncryptedKeyCrypto) deriveKey(password string) ([]byte, error) {
+	salt, err := ParseHex(c.KdfParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore salt: %w", err)
+	}
+	params := c.KdfParams
+	switch c.Kdf {
+	case KeystoreKdfScrypt:
+		if params.N <= 0 || params.R <= 0 || params.P <= 0 {
+			return nil, errors.New("invalid scrypt parameters")
+		}
+		if params.N > keystoreMaxScryptN || params.R > keystoreMaxScryptR || params.P > keystoreMaxScryptP || 128*params.N*params.R > keystoreMaxScryptMemory {
+			return nil, fmt.Errorf("scrypt parameters n=%d r=%d p=%d are over the maximum", params.N, params.R, params.P)
+		}
+		return scrypt.Key([]byte(password), salt, params.N, params.R, params.P, 32)
+	case KeystoreKdfArgon2id:
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+		if params.Time > keystoreMaxArgon2Time || params.Memory > keystoreMaxArgon2Memory {
+			return nil, fmt.Errorf("argon2id parameters time=%d memory=%d are over the maximum", params.Time, params.Memory)
+		}
+		return argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported keystore kdf %s", c.Kdf)
+	}
+}
+
+// additionalData is the clear text authenticated along with the private key
+func (key *EncryptedKey) additionalData() []byte {
+	return []byte(fmt.Sprintf("endless-keystore:%d:%s:%s:%t:%s", key.Version, key.Address, key.Variant, key.SingleKey, key.PublicKey))
+}
+
+// keystorePrivateKey extracts the private key from an account's signer
+func keystorePrivateKey(signer crypto.Signer) (crypto.PrivateKeyVariant, bool, []byte, error) {
+	switch signer := signer.(type) {
+	case *crypto.Ed25519PrivateKey:
+		return crypto.PrivateKeyVariantEd25519, false, signer.Bytes(), nil
+	case *crypto.SingleSigner:
+		switch inner := signer.Signer.(type) {
+		case *crypto.Ed25519PrivateKey:
+			return crypto.PrivateKeyVariantEd25519, true, inner.Bytes(), nil
+		case *crypto.Secp256k1PrivateKey:
+			return crypto.PrivateKeyVariantSecp256k1, true, inner.Bytes(), nil
+		}
+		return "", false, nil, fmt.Errorf("unsupported keystore single signer %T", signer.Signer)
+	default:
+		return "", false, nil, fmt.Errorf("unsupported keystore signer %T", signer)
+	}
+}
+
+// keystoreSigner rebuilds the signer from the decrypted private key
+func keystoreSigner(variant crypto.PrivateKeyVariant, singleKey bool, keyBytes []byte) (crypto.Signer, error) {
+	switch variant {
+	case crypto.PrivateKeyVariantEd25519:
+		privateKey := &crypto.Ed25519PrivateKey{}
+		if err := privateKey.FromBytes(keyBytes); err != nil {
+			return nil, err
+		}
+		if singleKey {
+			return crypto.NewSingleSigner(privateKey), nil
+		}
+		return privateKey, nil
+	case crypto.PrivateKeyVariantSecp256k1:
+		privateKey := &crypto.Secp256k1PrivateKey{}
+		if err := privateKey.FromBytes(keyBytes); err != nil {
+			return nil, err
+		}
+		return crypto.NewSingleSigner(privateKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported keystore key variant %s", variant)
+	}
+}
+
+// EncryptKey encrypts an account's private key with a password.
+//
+// The account's signer must be a [crypto.Ed25519PrivateKey], or a [crypto.SingleSigner] of a
+// [crypto.Ed25519PrivateKey] or [crypto.Secp256k1PrivateKey].
+//
+// Options:
+//   - [KeystoreScrypt] to set the scrypt parameters, the default
+//   - [KeystoreArgon2id] to use argon2id instead
+func EncryptKey(account *Account, password string, options ...any) (*EncryptedKey, error) {
+	kdf, params, err := keystoreKdf(options...)
+	if err != nil {
+		return nil, err
+	}
+	variant, singleKey, keyBytes, err := keystorePrivateKey(account.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	random := make([]byte, 16+12+16)
+	if _, err = rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, nonce, id := random[:16], random[16:28], random[28:]
+	params.Salt = BytesToHex(salt)
+	key := &EncryptedKey{
+		Version:   EncryptedKeyVersion,
+		Id:        BytesToHex(id),
+		Address:   account.Address.String(),
+		Variant:   variant,
+		SingleKey: singleKey,
+		PublicKey: account.PubKey().ToHex(),
+		Crypto: EncryptedKeyCrypto{
+			Cipher:    KeystoreCipher,
+			Nonce:     BytesToHex(nonce),
+			Kdf:       kdf,
+			KdfParams: params,
+		},
+	}
+
+	derivedKey, err := key.Crypto.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newKeystoreCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	key.Crypto.Ciphertext = BytesToHex(aead.Seal(nil, nonce, keyBytes, key.additionalData()))
+	return key, nil
+}
+
+func newKeystoreCipher(derivedKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ParseEncryptedKey parses a JSON keystore file
+func ParseEncryptedKey(data []byte) (*EncryptedKey, error) {
+	key := &EncryptedKey{}
+	if err := json.Unmarshal(data, key); err != nil {
+		return nil, fmt.Errorf("invalid keystore file: %w", err)
+	}
+	if key.Version != EncryptedKeyVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", key.Version)
+	}
+	if key.Crypto.Cipher != KeystoreCipher {
+		return nil, fmt.Errorf("unsupported keystore cipher %s", key.Crypto.Cipher)
+	}
+	if _, err := key.AccountAddress(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AccountAddress is the parsed address of the key
+func (key *EncryptedKey) AccountAddress() (AccountAddress, error) {
+	address := AccountAddress{}
+	if err := address.ParseStringRelaxed(key.Address); err != nil {
+		return address, fmt.Errorf("invalid keystore address: %w", err)
+	}
+	return address, nil
+}
+
+// DecryptSigner decrypts the private key, returning a [crypto.Signer] of the same type that was encrypted.
+//
+// Returns [ErrKeystorePassword] if the password is wrong, or the file has been modified.
+func (key *EncryptedKey) DecryptSigner(password string) (crypto.Signer, error) {
+	if key.Crypto.Cipher != KeystoreCipher {
+		return nil, fmt.Errorf("unsupported keystore cipher %s", key.Crypto.Cipher)
+	}
+	nonce, err := ParseHex(key.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore nonce: %w", err)
+	}
+	ciphertext, err := ParseHex(key.Crypto.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore ciphertext: %w", err)
+	}
+	derivedKey, err := key.Crypto.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newKeystoreCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid keystore nonce length %d", len(nonce))
+	}
+	keyBytes, err := aead.Open(nil, nonce, ciphertext, key.additionalData())
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+
+	signer, err := keystoreSigner(key.Variant, key.SingleKey, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if signer.PubKey().ToHex() != key.PublicKey {
+		return nil, errors.New("keystore public key does not match the private key")
+	}
+	return signer, nil
+}
+
+// Decrypt decrypts the private key into an [Account] with the key's address
+//
+// Returns [ErrKeystorePassword] if the password is wrong, or the file has been modified.
+func (key *EncryptedKey) Decrypt(password string) (*Account, error) {
+	signer, err := key.DecryptSigner(password)
+	if err != nil {
+		return nil, err
+	}
+	address, err := key.AccountAddress()
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromSigner(signer, crypto.AuthenticationKey(address))
+}
+
+// Keystore is a directory of [EncryptedKey] files, one per account, named by address
+type Keystore struct {
+	dir     string
+	options []any
+}
+
+// NewKeystore opens a keystore directory, creating it if it doesn't exist.
+//
+// Options are the KDF options for imported keys, see [EncryptKey].
+func NewKeystore(dir string, options ...any) (*Keystore, error) {
+	if _, _, err := keystoreKdf(options...); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Keystore{dir: dir, options: options}, nil
+}
+
+func (ks *Keystore) path(address AccountAddress) string {
+	return filepath.Join(ks.dir, address.String()+".json")
+}
+
+// List returns the keys in the keystore, sorted by address.  This doesn't need a password.
+func (ks *Keystore) List() ([]*EncryptedKey, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*EncryptedKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(ks.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseEncryptedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b *EncryptedKey) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return keys, nil
+}
+
+// Get returns the encrypted key for the address, or [ErrKeystoreNotFound]
+func (ks *Keystore) Get(address AccountAddress) (*EncryptedKey, error) {
+	data, err := os.ReadFile(ks.path(address))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeystoreNotFound, address.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseEncryptedKey(data)
+}
+
+// Import encrypts the account's key and adds it to the keystore.  Returns [ErrKeystoreExists] if the address is
+// already in the keystore.
+func (ks *Keystore) Import(account *Account, password string) (*EncryptedKey, error) {
+	key, err := EncryptKey(account, password, ks.options...)
+	if err != nil {
+		return nil, err
+	}
+	return key, ks.write(account.Address, key)
+}
+
+// ImportEncrypted adds an already encrypted key to the keystore.  Returns [ErrKeystoreExists] if the address is
+// already in the keystore.
+func (ks *Keystore) ImportEncrypted(key *EncryptedKey) error {
+	address, err := key.AccountAddress()
+	if err != nil {
+		return err
+	}
+	return ks.write(address, key)
+}
+
+func (ks *Keystore) write(address AccountAddress, key *EncryptedKey) error {
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(ks.path(address), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrKeystoreExists, address.String())
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Unlock decrypts the key for the address into an [Account]
+func (ks *Keystore) Unlock(address AccountAddress, password string) (*Account, error) {
+	key, err := ks.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	return key.Decrypt(password)
+}
+
+// UnlockSigner decrypts the key for the address into a [crypto.Signer]
+func (ks *Keystore) UnlockSigner(address AccountAddress, password string) (crypto.Signer, error) {
+	key, err := ks.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	return key.DecryptSigner(password)
+}
+
+// Export decrypts the key for the address, and returns it as an AIP-80 private key string
+func (ks *Keystore) Export(address AccountAddress, password string) (string, error) {
+	signer, err := ks.UnlockSigner(address, password)
+	if err != nil {
+		return "", err
+	}
+	if single, ok := signer.(*crypto.SingleSigner); ok {
+		switch privateKey := single.Signer.(type) {
+		case *crypto.Ed25519PrivateKey:
+			return privateKey.ToAIP80()
+		case *crypto.Secp256k1PrivateKey:
+			return privateKey.ToAIP80()
+		}
+	}
+	if privateKey, ok := signer.(*crypto.Ed25519PrivateKey); ok {
+		return privateKey.ToAIP80()
+	}
+	return "", fmt.Errorf("unsupported keystore signer %T", signer)
+}
+
+// Delete removes the key for the address.  The password is required, so a key can't be removed by mistake.
+func (ks *Keystore) Delete(address AccountAddress, password string) error {
+	if _, err := ks.UnlockSigner(address, password); err != nil {
+		return err
+	}
+	return os.Remove(ks.path(address))
+}
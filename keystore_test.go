@@ -0,0 +1,176 @@
+package endless
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// testScrypt keeps the KDF fast for tests
+var testScrypt = KeystoreScrypt{N: 1 << 10, R: 8, P: 1}
+
+func testKeystoreAccounts(t *testing.T) map[string]*Account {
+	legacy, err := NewEd25519Account()
+	assert.NoError(t, err)
+	single, err := NewEd25519SingleSenderAccount()
+	assert.NoError(t, err)
+	secp256k1, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	return map[string]*Account{"ed25519": legacy, "ed25519 single key": single, "secp256k1": secp256k1}
+}
+
+func TestEncryptKey_RoundTrip(t *testing.T) {
+	message := []byte("keystore")
+	for name, account := range testKeystoreAccounts(t) {
+		for _, kdf := range []any{testScrypt, KeystoreArgon2id{Time: 1, Memory: 1024, Threads: 1}} {
+			t.Run(name, func(t *testing.T) {
+				key, err := EncryptKey(account, "password", kdf)
+				assert.NoError(t, err)
+				assert.Equal(t, account.Address.String(), key.Address)
+				assert.Equal(t, account.PubKey().ToHex(), key.PublicKey)
+
+				data, err := json.Marshal(key)
+				assert.NoError(t, err)
+				parsed, err := ParseEncryptedKey(data)
+				assert.NoError(t, err)
+
+				decrypted, err := parsed.Decrypt("password")
+				assert.NoError(t, err)
+				assert.Equal(t, account.Address, decrypted.Address)
+				assert.Equal(t, account.AuthKey(), decrypted.AuthKey())
+				auth, err := decrypted.Sign(message)
+				assert.NoError(t, err)
+				assert.True(t, auth.Verify(message))
+
+				_, err = parsed.Decrypt("wrong")
+				assert.ErrorIs(t, err, ErrKeystorePassword)
+			})
+		}
+	}
+}
+
+func TestEncryptKey_Tampered(t *testing.T) {
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	other, err := NewEd25519Account()
+	assert.NoError(t, err)
+	key, err := EncryptKey(account, "password", testScrypt)
+	assert.NoError(t, err)
+
+	// The clear text fields are authenticated, so they can't be swapped for another account's
+	tampered := *key
+	tampered.Address = other.Address.String()
+	_, err = tampered.Decrypt("password")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	tampered = *key
+	tampered.SingleKey = true
+	_, err = tampered.Decrypt("password")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	// KDF parameters that would exhaust memory are rejected before deriving the key
+	for _, params := range []KeystoreKdfParams{
+		{Salt: key.Crypto.KdfParams.Salt, N: 1 << 30, R: 8, P: 1},
+		{Salt: key.Crypto.KdfParams.Salt, N: 1 << 20, R: 32, P: 1},
+		{Salt: key.Crypto.KdfParams.Salt, N: 1 << 10, R: 8, P: 1 << 20},
+		{Salt: key.Crypto.KdfParams.Salt, N: -1, R: 8, P: 1},
+	} {
+		tampered = *key
+		tampered.Crypto.KdfParams = params
+		_, err = tampered.Decrypt("password")
+		assert.ErrorContains(t, err, "scrypt parameters")
+	}
+	tampered = *key
+	tampered.Crypto.Kdf = KeystoreKdfArgon2id
+	tampered.Crypto.KdfParams = KeystoreKdfParams{Salt: key.Crypto.KdfParams.Salt, Time: 1, Memory: 4294967295, Threads: 1}
+	_, err = tampered.Decrypt("password")
+	assert.ErrorContains(t, err, "argon2id parameters")
+	_, err = EncryptKey(account, "password", KeystoreArgon2id{Time: 1000})
+	assert.Error(t, err)
+
+	_, err = EncryptKey(account, "password", "unknown")
+	assert.Error(t, err)
+	_, err = ParseEncryptedKey([]byte(`{"version":2}`))
+	assert.Error(t, err)
+}
+
+func TestEncryptKey_RotatedAddress(t *testing.T) {
+	privateKey, err := crypto.GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	account, err := NewAccountFromSigner(privateKey, *AccountTwo.AuthKey())
+	assert.NoError(t, err)
+
+	key, err := EncryptKey(account, "password", testScrypt)
+	assert.NoError(t, err)
+	decrypted, err := key.Decrypt("password")
+	assert.NoError(t, err)
+	assert.Equal(t, AccountTwo, decrypted.Address)
+	assert.Equal(t, privateKey.PubKey().ToHex(), decrypted.PubKey().ToHex())
+}
+
+func TestKeystore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	keystore, err := NewKeystore(dir, testScrypt)
+	assert.NoError(t, err)
+
+	accounts := testKeystoreAccounts(t)
+	for _, account := range accounts {
+		_, err = keystore.Import(account, "password")
+		assert.NoError(t, err)
+	}
+	legacy := accounts["ed25519"]
+	_, err = keystore.Import(legacy, "password")
+	assert.ErrorIs(t, err, ErrKeystoreExists)
+
+	info, err := os.Stat(filepath.Join(dir, legacy.Address.String()+".json"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	keys, err := keystore.List()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 3)
+	for i := 1; i < len(keys); i++ {
+		assert.Less(t, keys[i-1].Address, keys[i].Address)
+	}
+
+	for name, account := range accounts {
+		unlocked, err := keystore.Unlock(account.Address, "password")
+		assert.NoError(t, err, name)
+		assert.Equal(t, account.Address, unlocked.Address)
+
+		exported, err := keystore.Export(account.Address, "password")
+		assert.NoError(t, err, name)
+		var expected string
+		switch signer := account.Signer.(type) {
+		case *crypto.Ed25519PrivateKey:
+			expected, err = signer.ToAIP80()
+		case *crypto.SingleSigner:
+			expected, err = signer.Signer.(interface{ ToAIP80() (string, error) }).ToAIP80()
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, expected, exported, name)
+	}
+
+	_, err = keystore.Unlock(AccountOne, "password")
+	assert.True(t, errors.Is(err, ErrKeystoreNotFound))
+	_, err = keystore.UnlockSigner(legacy.Address, "wrong")
+	assert.ErrorIs(t, err, ErrKeystorePassword)
+
+	assert.ErrorIs(t, keystore.Delete(legacy.Address, "wrong"), ErrKeystorePassword)
+	assert.NoError(t, keystore.Delete(legacy.Address, "password"))
+	keys, err = keystore.List()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	// Keys encrypted elsewhere can be added as is
+	key, err := EncryptKey(legacy, "other password", testScrypt)
+	assert.NoError(t, err)
+	assert.NoError(t, keystore.ImportEncrypted(key))
+	_, err = keystore.Unlock(legacy.Address, "other password")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,358 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// -- Multi auth key payloads --
+
+// AddAuthenticationKeysPayload creates a payload adding the secondary signers' authentication keys to the sender, and
+// setting the number of signatures required.  It must be sent as a multi-agent transaction, signed by each new key.
+func AddAuthenticationKeysPayload(numSignaturesRequired uint64) (*EntryFunction, error) {
+	numSignaturesRequiredBytes, err := bcs.SerializeU64(numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	return authenticationKeyPayloadCommon("batch_add_authentication_key", numSignaturesRequiredBytes), nil
+}
+
+// RemoveAuthenticationKeysPayload creates a payload removing authentication keys from the sender, and setting the
+// number of signatures required
+func RemoveAuthenticationKeysPayload(authKeys []crypto.AuthenticationKey, numSignaturesRequired uint64) (*EntryFunction, error) {
+	authKeysBytes, err := bcs.SerializeSingle(func(ser *bcs.Serializer) {
+		bcs.SerializeSequenceWithFunction(authKeys, ser, func(ser *bcs.Serializer, authKey crypto.AuthenticationKey) {
+			ser.WriteBytes(authKey[:])
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	numSignaturesRequiredBytes, err := bcs.SerializeU64(numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	return authenticationKeyPayloadCommon("batch_remove_authentication_key", authKeysBytes, numSignaturesRequiredBytes), nil
+}
+
+// SetNumSignaturesRequiredPayload creates a payload changing the number of signatures required by the sender
+func SetNumSignaturesRequiredPayload(numSignaturesRequired uint64) (*EntryFunction, error) {
+	numSignaturesRequiredBytes, err := bcs.SerializeU64(numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	return authenticationKeyPayloadCommon("set_num_signatures_required", numSignaturesRequiredBytes), nil
+}
+
+func authenticationKeyPayloadCommon(functionName string, args ...[]byte) *EntryFunction {
+	return &EntryFunction{
+		Module: ModuleId{
+			Address: AccountOne,
+			Name:    "account",
+		},
+		Function: functionName,
+		ArgTypes: []TypeTag{},
+		Args:     args,
+	}
+}
+
+//region MultiAuthKeyAccount
+
+var errMultiAuthKeyAccountClient = errors.New("multi auth key account has no client, create it with NewMultiAuthKeyAccount")
+
+// MultiAuthKeyAccount is an account with several authentication keys, needing NumSignaturesRequired of them to sign
+// each transaction.  Owners are the owner keys held locally, which sign in order.
+//
+//	account, err := endless.UpgradeToMultiAuthKeyAccount(client, owner, []endless.TransactionSigner{owner2, owner3}, 2)
+//	// Once committed, owner and owner2 can send transactions for the account
+//	account.Owners = []crypto.Signer{owner, owner2}
+//	response, err := client.BuildSignAndSubmitTransaction(account, payload)
+//
+// Implements:
+//   - [TransactionSigner]
+//   - [crypto.Signer]
+type MultiAuthKeyAccount struct {
+	Address               AccountAddress
+	NumSignaturesRequired uint64
+	Owners                []crypto.Signer            // Owners are the locally held owner keys, the first NumSignaturesRequired sign
+	AuthKeys              []crypto.AuthenticationKey // AuthKeys are the account's authentication keys, from Refresh or set for an offline account
+
+	client *NodeClient
+}
+
+// NewMultiAuthKeyAccount loads a multi auth key account's authentication keys and number of signatures required from
+// on-chain.  Every owner must be an authentication key of the account.
+func NewMultiAuthKeyAccount(client *NodeClient, address AccountAddress, owners ...crypto.Signer) (*MultiAuthKeyAccount, error) {
+	account := &MultiAuthKeyAccount{Address: address, Owners: owners, client: client}
+	if err := account.Refresh(); err != nil {
+		return nil, err
+	}
+	for _, owner := range owners {
+		if !account.HasAuthKey(owner.AuthKey()) {
+			return nil, fmt.Errorf("owner %s is not an authentication key of %s", owner.AuthKey().ToHex(), address.String())
+		}
+	}
+	return account, nil
+}
+
+// Refresh reloads the authentication keys and number of signatures required from on-chain
+func (account *MultiAuthKeyAccount) Refresh() error {
+	if account.client == nil {
+		return errMultiAuthKeyAccountClient
+	}
+	info, err := account.client.Account(account.Address)
+	if err != nil {
+		return err
+	}
+	keys, err := info.AuthenticationKey()
+	if err != nil {
+		return err
+	}
+	authKeys := make([]crypto.AuthenticationKey, len(keys))
+	for i, key := range keys {
+		if err = authKeys[i].FromBytes(key); err != nil {
+			return fmt.Errorf("invalid authentication key %d for %s: %w", i, account.Address.String(), err)
+		}
+	}
+	account.AuthKeys = authKeys
+	account.NumSignaturesRequired = uint64(info.NumSignaturesRequired)
+	return nil
+}
+
+// HasAuthKey is true if the authentication key is one of the account's.  It's false for every key if the account's
+// keys haven't been loaded.
+func (account *MultiAuthKeyAccount) HasAuthKey(authKey *crypto.AuthenticationKey) bool {
+	for _, key := range account.AuthKeys {
+		if key == *authKey {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWith signs the message with a subset of the owners, which must meet NumSignaturesRequired, returning a
+// [crypto.MultiAuthKeyAuthenticator].  The signers don't need to be in Owners.
+func (account *MultiAuthKeyAccount) SignWith(message []byte, signers ...crypto.Signer) (*crypto.AccountAuthenticator, error) {
+	auths := make([]*crypto.AccountAuthenticator, len(signers))
+	for i, signer := range signers {
+		auth, err := signer.Sign(message)
+		if err != nil {
+			return nil, err
+		}
+		auths[i] = auth
+	}
+	return account.CombineAuthenticators(auths...)
+}
+
+// CombineAuthenticators combines authenticators collected from each owner into a [crypto.MultiAuthKeyAuthenticator].
+// There must be at least NumSignaturesRequired authenticators from different keys of the account.
+func (account *MultiAuthKeyAccount) CombineAuthenticators(auths ...*crypto.AccountAuthenticator) (*crypto.AccountAuthenticator, error) {
+	if account.AuthKeys == nil {
+		return nil, fmt.Errorf("authentication keys of %s are not loaded, call Refresh or set AuthKeys", account.Address.String())
+	}
+	seen := make(map[crypto.AuthenticationKey]bool, len(auths))
+	for _, auth := range auths {
+		if auth == nil || auth.Auth == nil || auth.Variant == crypto.AccountAuthenticatorMultiAuthKey {
+			return nil, errors.New("authenticator must be a single signer's authenticator")
+		}
+		authKey := auth.PubKey().AuthKey()
+		if !account.HasAuthKey(authKey) {
+			return nil, fmt.Errorf("%s is not an authentication key of %s", authKey.ToHex(), account.Address.String())
+		}
+		if seen[*authKey] {
+			return nil, fmt.Errorf("%s signed more than once", authKey.ToHex())
+		}
+		seen[*authKey] = true
+	}
+	if uint64(len(auths)) < account.NumSignaturesRequired {
+		return nil, fmt.Errorf("not enough signatures, %d of %d required", len(auths), account.NumSignaturesRequired)
+	}
+
+	multiAuthKey := &crypto.MultiAuthKeyAuthenticator{}
+	if err := multiAuthKey.FromAuthenticators(auths); err != nil {
+		return nil, err
+	}
+	return &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiAuthKey, Auth: multiAuthKey}, nil
+}
+
+// signers are the owners that sign, the first NumSignaturesRequired
+func (account *MultiAuthKeyAccount) signers() ([]crypto.Signer, error) {
+	required := max(account.NumSignaturesRequired, 1)
+	if uint64(len(account.Owners)) < required {
+		return nil, fmt.Errorf("not enough owners, %d of %d required", len(account.Owners), required)
+	}
+	return account.Owners[:required], nil
+}
+
+//region MultiAuthKeyAccount TransactionSigner
+
+// Sign signs the message with the first NumSignaturesRequired owners
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiAuthKeyAccount) Sign(message []byte) (*crypto.AccountAuthenticator, error) {
+	signers, err := account.signers()
+	if err != nil {
+		return nil, err
+	}
+	return account.SignWith(message, signers...)
+}
+
+// SignMessage isn't supported, as there isn't a single signature for a multi auth key account.  Use Sign instead.
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiAuthKeyAccount) SignMessage([]byte) (crypto.Signature, error) {
+	return nil, errors.New("multi auth key accounts can't sign a single signature, use Sign")
+}
+
+// SimulationAuthenticator is an authenticator with empty signatures from the first NumSignaturesRequired owners, or
+// nil if there aren't enough owners
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiAuthKeyAccount) SimulationAuthenticator() *crypto.AccountAuthenticator {
+	signers, err := account.signers()
+	if err != nil {
+		return nil
+	}
+	auths := make([]*crypto.AccountAuthenticator, len(signers))
+	for i, signer := range signers {
+		auths[i] = signer.SimulationAuthenticator()
+		if auths[i] == nil {
+			return nil
+		}
+	}
+	multiAuthKey := &crypto.MultiAuthKeyAuthenticator{}
+	if err = multiAuthKey.FromAuthenticators(auths); err != nil {
+		return nil
+	}
+	return &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiAuthKey, Auth: multiAuthKey}
+}
+
+// AuthKey is the account's original authentication key, which is its address
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiAuthKeyAccount) AuthKey() *crypto.AuthenticationKey {
+	authKey := crypto.AuthenticationKey(account.Address)
+	return &authKey
+}
+
+// PubKey is nil, as a multi auth key account has a public key for each owner
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiAuthKeyAccount) PubKey() crypto.PublicKey {
+	return nil
+}
+
+// AccountAddress is the address of the account
+//
+// Implements:
+//   - [TransactionSigner]
+func (account *MultiAuthKeyAccount) AccountAddress() AccountAddress {
+	return account.Address
+}
+
+//endregion
+
+// AddOwners adds the new owners' authentication keys to the account, and sets the number of signatures required.  Each
+// new owner signs as a secondary signer, proving they hold the key.  Refresh the account once it's committed.
+func (account *MultiAuthKeyAccount) AddOwners(newOwners []TransactionSigner, numSignaturesRequired uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	if account.client == nil {
+		return nil, errMultiAuthKeyAccountClient
+	}
+	return addAuthenticationKeys(account.client, account, newOwners, numSignaturesRequired, options...)
+}
+
+// RemoveOwners removes authentication keys from the account, and sets the number of signatures required.  Refresh
+// the account once it's committed.
+func (account *MultiAuthKeyAccount) RemoveOwners(authKeys []crypto.AuthenticationKey, numSignaturesRequired uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	if account.client == nil {
+		return nil, errMultiAuthKeyAccountClient
+	}
+	payload, err := RemoveAuthenticationKeysPayload(authKeys, numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	return account.client.BuildSignAndSubmitTransaction(account, TransactionPayload{Payload: payload}, options...)
+}
+
+// SetNumSignaturesRequired changes the number of signatures required.  Refresh the account once it's committed.
+func (account *MultiAuthKeyAccount) SetNumSignaturesRequired(numSignaturesRequired uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	if account.client == nil {
+		return nil, errMultiAuthKeyAccountClient
+	}
+	payload, err := SetNumSignaturesRequiredPayload(numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	return account.client.BuildSignAndSubmitTransaction(account, TransactionPayload{Payload: payload}, options...)
+}
+
+//endregion
+
+// UpgradeToMultiAuthKeyAccount adds the new owners' authentication keys to a single key account, making it a
+// [MultiAuthKeyAccount] needing numSignaturesRequired signatures.  The returned account has the original key and new
+// owners as Owners, and can be used once the transaction is committed.
+func UpgradeToMultiAuthKeyAccount(client *NodeClient, account *Account, newOwners []TransactionSigner, numSignaturesRequired uint64, options ...any) (*MultiAuthKeyAccount, *api.SubmitTransactionResponse, error) {
+	response, err := addAuthenticationKeys(client, account, newOwners, numSignaturesRequired, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	owners := []crypto.Signer{account.Signer}
+	authKeys := []crypto.AuthenticationKey{*account.AuthKey()}
+	for _, owner := range newOwners {
+		owners = append(owners, owner)
+		authKeys = append(authKeys, *owner.AuthKey())
+	}
+	return &MultiAuthKeyAccount{
+		Address:               account.Address,
+		NumSignaturesRequired: numSignaturesRequired,
+		Owners:                owners,
+		AuthKeys:              authKeys,
+		client:                client,
+	}, response, nil
+}
+
+// addAuthenticationKeys sends a multi-agent transaction from the sender, adding the new owners' keys
+func addAuthenticationKeys(client *NodeClient, sender TransactionSigner, newOwners []TransactionSigner, numSignaturesRequired uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	if len(newOwners) == 0 {
+		return nil, errors.New("no new owners to add")
+	}
+	payload, err := AddAuthenticationKeysPayload(numSignaturesRequired)
+	if err != nil {
+		return nil, err
+	}
+	additionalSigners := make(AdditionalSigners, len(newOwners))
+	for i, owner := range newOwners {
+		additionalSigners[i] = owner.AccountAddress()
+	}
+	rawTxn, err := client.BuildTransactionMultiAgent(sender.AccountAddress(), TransactionPayload{Payload: payload}, append([]any{additionalSigners}, options...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	senderAuth, err := rawTxn.Sign(sender)
+	if err != nil {
+		return nil, err
+	}
+	ownerAuths := make([]crypto.AccountAuthenticator, len(newOwners))
+	for i, owner := range newOwners {
+		auth, err := rawTxn.Sign(owner)
+		if err != nil {
+			return nil, err
+		}
+		ownerAuths[i] = *auth
+	}
+	signedTxn, ok := rawTxn.ToMultiAgentSignedTransaction(senderAuth, ownerAuths)
+	if !ok {
+		return nil, errors.New("failed to build multi-agent transaction")
+	}
+	return client.SubmitTransaction(signedTxn)
+}
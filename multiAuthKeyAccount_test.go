@@ -0,0 +1,137 @@
+package endless
+
+import (
+	"testing"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMultiAuthKeyOptions() []any {
+	return []any{GasUnitPrice(100), MaxGasAmount(1000), ExpirationSeconds(time.Now().Unix() + 60)}
+}
+
+func TestUpgradeToMultiAuthKeyAccount(t *testing.T) {
+	node := newTestNode(t)
+	owner, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner3, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	node.SetAccount(owner.Address, 0, owner.AuthKey().ToHex())
+
+	account, response, err := UpgradeToMultiAuthKeyAccount(node.client, owner, []TransactionSigner{owner2, owner3}, 2, testMultiAuthKeyOptions()...)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Hash)
+	assert.Equal(t, owner.Address, account.Address)
+	assert.Equal(t, uint64(2), account.NumSignaturesRequired)
+	assert.Len(t, account.Owners, 3)
+	assert.Equal(t, []crypto.AuthenticationKey{*owner.AuthKey(), *owner2.AuthKey(), *owner3.AuthKey()}, account.AuthKeys)
+
+	// The new owners co-sign the transaction adding their keys
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.NoError(t, submitted[0].Verify())
+	multiAgent, ok := submitted[0].Authenticator.Auth.(*MultiAgentTransactionAuthenticator)
+	assert.True(t, ok)
+	assert.Equal(t, []AccountAddress{owner2.Address, owner3.Address}, multiAgent.SecondarySignerAddresses)
+	payload := submitted[0].Transaction.Payload.Payload.(*EntryFunction)
+	assert.Equal(t, "batch_add_authentication_key", payload.Function)
+	threshold, _ := bcs.SerializeU64(2)
+	assert.Equal(t, [][]byte{threshold}, payload.Args)
+}
+
+func TestMultiAuthKeyAccount_Sign(t *testing.T) {
+	node := newTestNode(t)
+	owner, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewEd25519SingleSenderAccount()
+	assert.NoError(t, err)
+	owner3, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	outsider, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(owner.Address, 1, owner.AuthKey().ToHex(), owner2.AuthKey().ToHex(), owner3.AuthKey().ToHex())
+	node.accounts[owner.Address].NumSignaturesRequired = 2
+
+	_, err = NewMultiAuthKeyAccount(node.client, owner.Address, outsider)
+	assert.Error(t, err)
+
+	// Any two owners can sign
+	account, err := NewMultiAuthKeyAccount(node.client, owner.Address, owner3, owner2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), account.NumSignaturesRequired)
+	response, err := account.SetNumSignaturesRequired(3, testMultiAuthKeyOptions()...)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Hash)
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.NoError(t, submitted[0].Verify())
+	assert.Equal(t, uint64(1), submitted[0].Transaction.SequenceNumber)
+	auth := submitted[0].Authenticator.Auth.(*SingleSenderTransactionAuthenticator).Sender
+	assert.Equal(t, crypto.AccountAuthenticatorMultiAuthKey, auth.Variant)
+	assert.Len(t, auth.Auth.(*crypto.MultiAuthKeyAuthenticator).PubKeys, 2)
+	assert.Equal(t, "set_num_signatures_required", submitted[0].Transaction.Payload.Payload.(*EntryFunction).Function)
+
+	// Authenticators collected separately, in any order
+	message := []byte("message")
+	auth1, err := owner.Sign(message)
+	assert.NoError(t, err)
+	auth3, err := owner3.Sign(message)
+	assert.NoError(t, err)
+	combined, err := account.CombineAuthenticators(auth3, auth1)
+	assert.NoError(t, err)
+	assert.True(t, combined.Verify(message))
+
+	_, err = account.CombineAuthenticators(auth1)
+	assert.Error(t, err)
+	_, err = account.CombineAuthenticators(auth1, auth1)
+	assert.Error(t, err)
+	outsiderAuth, err := outsider.Sign(message)
+	assert.NoError(t, err)
+	_, err = account.CombineAuthenticators(auth1, outsiderAuth)
+	assert.Error(t, err)
+
+	assert.NotNil(t, account.SimulationAuthenticator())
+
+	// Not enough owners held locally
+	account.Owners = account.Owners[:1]
+	_, err = account.Sign(message)
+	assert.Error(t, err)
+	assert.Nil(t, account.SimulationAuthenticator())
+
+	// Without the account's keys loaded, no owner is accepted
+	unloaded := &MultiAuthKeyAccount{Address: owner.Address, NumSignaturesRequired: 1}
+	assert.False(t, unloaded.HasAuthKey(owner.AuthKey()))
+	_, err = unloaded.CombineAuthenticators(auth1)
+	assert.Error(t, err)
+}
+
+func TestMultiAuthKeyAccount_RemoveOwners(t *testing.T) {
+	node := newTestNode(t)
+	owner, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(owner.Address, 0, owner.AuthKey().ToHex(), owner2.AuthKey().ToHex())
+
+	account, err := NewMultiAuthKeyAccount(node.client, owner.Address, owner)
+	assert.NoError(t, err)
+	_, err = account.RemoveOwners([]crypto.AuthenticationKey{*owner2.AuthKey()}, 1, testMultiAuthKeyOptions()...)
+	assert.NoError(t, err)
+
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.NoError(t, submitted[0].Verify())
+	payload := submitted[0].Transaction.Payload.Payload.(*EntryFunction)
+	assert.Equal(t, "batch_remove_authentication_key", payload.Function)
+	assert.Equal(t, append([]byte{1, 32}, owner2.AuthKey()[:]...), payload.Args[0])
+
+	// Offline accounts have no client
+	offline := &MultiAuthKeyAccount{Address: owner.Address, NumSignaturesRequired: 1, Owners: []crypto.Signer{owner}}
+	_, err = offline.SetNumSignaturesRequired(1)
+	assert.Error(t, err)
+}
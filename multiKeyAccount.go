@@ -0,0 +1,227 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+//region MultiKeyAccount
+
+// MultiKeyAccount is a k-of-n [crypto.MultiKey] account, whose keys can be any mix of Ed25519 and Secp256k1 keys.
+//
+// With SignaturesRequired keys held locally, it's a [TransactionSigner] like an [Account].  Otherwise, each co-signer
+// creates a MultiKeyAccount with the same keys and their own private key, signs with [MultiKeyAccount.SignPartial],
+// and one of them merges the signatures with [MultiKeyAccount.CombineSignatures].
+//
+//	account, err := endless.NewMultiKeyAccount([]crypto.VerifyingKey{key1, key2, key3}, 2, mySigner)
+//	message, err := rawTxn.SigningMessage()
+//	mine, err := account.SignPartial(message)
+//	auth, err := account.CombineSignatures(message, append(mine, theirs...)...)
+//	signedTxn, err := rawTxn.SignedTransactionWithAuthenticator(auth)
+//
+// Implements:
+//   - [TransactionSigner]
+//   - [crypto.Signer]
+type MultiKeyAccount struct {
+	Address  AccountAddress         // Address is derived from MultiKey, set it if the account's key was rotated to MultiKey
+	MultiKey *crypto.MultiKey       // MultiKey is all keys of the account, in order, and the threshold
+	Signers  []*crypto.SingleSigner // Signers are the private keys held locally, any of MultiKey's keys
+}
+
+// NewMultiKeyAccount creates a [MultiKeyAccount] from its public keys in order, and the number of signatures
+// required.  The address is derived from both, so the same keys in a different order or with a different threshold
+// are a different account.  Signers are the private keys held locally, and may be none.
+func NewMultiKeyAccount(pubKeys []crypto.VerifyingKey, signaturesRequired uint8, signers ...*crypto.SingleSigner) (*MultiKeyAccount, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > int(crypto.MaxMultiKeySignatures) {
+		return nil, fmt.Errorf("multi key must have between 1 and %d keys, got %d", crypto.MaxMultiKeySignatures, len(pubKeys))
+	}
+	if signaturesRequired == 0 || int(signaturesRequired) > len(pubKeys) {
+		return nil, fmt.Errorf("signatures required must be between 1 and %d, got %d", len(pubKeys), signaturesRequired)
+	}
+	multiKey := &crypto.MultiKey{PubKeys: make([]*crypto.AnyPublicKey, len(pubKeys)), SignaturesRequired: signaturesRequired}
+	seen := make(map[string]bool)
+	for i, pubKey := range pubKeys {
+		anyPubKey, err := crypto.ToAnyPublicKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		if seen[string(anyPubKey.Bytes())] {
+			return nil, fmt.Errorf("duplicate key %s", anyPubKey.ToHex())
+		}
+		seen[string(anyPubKey.Bytes())] = true
+		multiKey.PubKeys[i] = anyPubKey
+	}
+
+	account := &MultiKeyAccount{MultiKey: multiKey}
+	account.Address.FromAuthKey(multiKey.AuthKey())
+	for _, signer := range signers {
+		if account.index(signer) < 0 {
+			return nil, fmt.Errorf("signer %s is not a key of the multi key", signer.PubKey().ToHex())
+		}
+		account.Signers = append(account.Signers, signer)
+	}
+	return account, nil
+}
+
+// index is the signer's position in MultiKey, or -1 if it isn't one of the keys
+func (account *MultiKeyAccount) index(signer *crypto.SingleSigner) int {
+	signerKey := signer.PubKey().Bytes()
+	for i, pubKey := range account.MultiKey.PubKeys {
+		if string(pubKey.Bytes()) == string(signerKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SignPartial signs the message with each local signer, for another co-signer to combine with
+// [MultiKeyAccount.CombineSignatures].  Each [crypto.IndexedAnySignature] can be sent on its own, e.g. as BCS.
+func (account *MultiKeyAccount) SignPartial(message []byte) ([]crypto.IndexedAnySignature, error) {
+	if len(account.Signers) == 0 {
+		return nil, errors.New("multi key account has no local signers")
+	}
+	signatures := make([]crypto.IndexedAnySignature, 0, len(account.Signers))
+	for _, signer := range account.Signers {
+		index := account.index(signer)
+		if index < 0 {
+			return nil, fmt.Errorf("signer %s is not a key of the multi key", signer.PubKey().ToHex())
+		}
+		signature, err := signer.SignMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, crypto.IndexedAnySignature{Index: uint8(index), Signature: signature.(*crypto.AnySignature)})
+	}
+	return signatures, nil
+}
+
+// CombineSignatures merges signatures from [MultiKeyAccount.SignPartial] into a [crypto.MultiKeyAuthenticator], in
+// any order.  Each signature is checked against its key, so an invalid one is reported here, rather than by the
+// network.  Repeated indices are only counted once.
+func (account *MultiKeyAccount) CombineSignatures(message []byte, signatures ...crypto.IndexedAnySignature) (*crypto.AccountAuthenticator, error) {
+	unique := make([]crypto.IndexedAnySignature, 0, len(signatures))
+	seen := make(map[uint8]bool)
+	for _, signature := range signatures {
+		if int(signature.Index) >= len(account.MultiKey.PubKeys) {
+			return nil, fmt.Errorf("signature index %d is out of range of %d keys", signature.Index, len(account.MultiKey.PubKeys))
+		}
+		if signature.Signature == nil || !account.MultiKey.PubKeys[signature.Index].Verify(message, signature.Signature) {
+			return nil, fmt.Errorf("signature for key %d is invalid", signature.Index)
+		}
+		if seen[signature.Index] {
+			continue
+		}
+		seen[signature.Index] = true
+		unique = append(unique, signature)
+	}
+	if len(unique) < int(account.MultiKey.SignaturesRequired) {
+		return nil, fmt.Errorf("not enough signatures, %d of %d required", len(unique), account.MultiKey.SignaturesRequired)
+	}
+	multiKeySignature, err := crypto.NewMultiKeySignature(unique)
+	if err != nil {
+		return nil, err
+	}
+	return &crypto.AccountAuthenticator{
+		Variant: crypto.AccountAuthenticatorMultiKey,
+		Auth:    &crypto.MultiKeyAuthenticator{PubKey: account.MultiKey, Sig: multiKeySignature},
+	}, nil
+}
+
+// localSigners are the local signers used to sign on their own, only as many as are required
+func (account *MultiKeyAccount) localSigners() (*MultiKeyAccount, error) {
+	required := int(account.MultiKey.SignaturesRequired)
+	if len(account.Signers) < required {
+		return nil, fmt.Errorf("not enough local signers, %d of %d required", len(account.Signers), required)
+	}
+	return &MultiKeyAccount{Address: account.Address, MultiKey: account.MultiKey, Signers: account.Signers[:required]}, nil
+}
+
+//region MultiKeyAccount TransactionSigner implementation
+
+// Sign signs the message with SignaturesRequired local signers
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiKeyAccount) Sign(message []byte) (*crypto.AccountAuthenticator, error) {
+	signers, err := account.localSigners()
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := signers.SignPartial(message)
+	if err != nil {
+		return nil, err
+	}
+	return account.CombineSignatures(message, signatures...)
+}
+
+// SignMessage signs the message with SignaturesRequired local signers, returning a [crypto.MultiKeySignature]
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiKeyAccount) SignMessage(message []byte) (crypto.Signature, error) {
+	auth, err := account.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+	return auth.Signature(), nil
+}
+
+// SimulationAuthenticator creates an authenticator with empty signatures for SignaturesRequired keys, the local
+// signers' keys first
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiKeyAccount) SimulationAuthenticator() *crypto.AccountAuthenticator {
+	indices := make([]int, 0, account.MultiKey.SignaturesRequired)
+	used := make(map[int]bool)
+	for _, signer := range account.Signers {
+		if index := account.index(signer); index >= 0 && !used[index] {
+			indices = append(indices, index)
+			used[index] = true
+		}
+	}
+	for i := range account.MultiKey.PubKeys {
+		if !used[i] {
+			indices = append(indices, i)
+		}
+	}
+	signatures := make([]crypto.IndexedAnySignature, account.MultiKey.SignaturesRequired)
+	for i := range signatures {
+		index := indices[i]
+		signatures[i] = crypto.IndexedAnySignature{Index: uint8(index), Signature: account.MultiKey.PubKeys[index].EmptySignature()}
+	}
+	multiKeySignature, _ := crypto.NewMultiKeySignature(signatures)
+	return &crypto.AccountAuthenticator{
+		Variant: crypto.AccountAuthenticatorMultiKey,
+		Auth:    &crypto.MultiKeyAuthenticator{PubKey: account.MultiKey, Sig: multiKeySignature},
+	}
+}
+
+// AuthKey is the authentication key of the [crypto.MultiKey]
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiKeyAccount) AuthKey() *crypto.AuthenticationKey {
+	return account.MultiKey.AuthKey()
+}
+
+// PubKey is the [crypto.MultiKey]
+//
+// Implements:
+//   - [crypto.Signer]
+func (account *MultiKeyAccount) PubKey() crypto.PublicKey {
+	return account.MultiKey
+}
+
+// AccountAddress is the address of the account
+//
+// Implements:
+//   - [TransactionSigner]
+func (account *MultiKeyAccount) AccountAddress() AccountAddress {
+	return account.Address
+}
+
+//endregion
+//endregion
@@ -0,0 +1,124 @@
+package endless
+
+import (
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMultiKeySigners(t *testing.T) ([]*crypto.SingleSigner, []crypto.VerifyingKey) {
+	signers := make([]*crypto.SingleSigner, 3)
+	pubKeys := make([]crypto.VerifyingKey, 3)
+	for i := range signers {
+		var privateKey crypto.MessageSigner
+		var err error
+		if i == 1 {
+			privateKey, err = crypto.GenerateSecp256k1Key()
+		} else {
+			privateKey, err = crypto.GenerateEd25519PrivateKey()
+		}
+		assert.NoError(t, err)
+		signers[i] = crypto.NewSingleSigner(privateKey)
+		pubKeys[i] = privateKey.VerifyingKey()
+	}
+	return signers, pubKeys
+}
+
+func TestMultiKeyAccount_Address(t *testing.T) {
+	signers, pubKeys := testMultiKeySigners(t)
+	account, err := NewMultiKeyAccount(pubKeys, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountAddress(*account.AuthKey()), account.Address)
+
+	// The order and threshold are part of the address
+	reordered, err := NewMultiKeyAccount([]crypto.VerifyingKey{pubKeys[1], pubKeys[0], pubKeys[2]}, 2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, account.Address, reordered.Address)
+	threshold, err := NewMultiKeyAccount(pubKeys, 3)
+	assert.NoError(t, err)
+	assert.NotEqual(t, account.Address, threshold.Address)
+
+	_, err = NewMultiKeyAccount(pubKeys, 0)
+	assert.Error(t, err)
+	_, err = NewMultiKeyAccount(pubKeys, 4)
+	assert.Error(t, err)
+	_, err = NewMultiKeyAccount([]crypto.VerifyingKey{pubKeys[0], pubKeys[0]}, 1)
+	assert.Error(t, err)
+	_, err = NewMultiKeyAccount(pubKeys[:2], 1, signers[2])
+	assert.Error(t, err)
+}
+
+func TestMultiKeyAccount_PartialSigning(t *testing.T) {
+	node := newTestNode(t)
+	signers, pubKeys := testMultiKeySigners(t)
+	coordinator, err := NewMultiKeyAccount(pubKeys, 2)
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransaction(coordinator.Address, envelopeTestPayload(t), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+	message, err := rawTxn.SigningMessage()
+	assert.NoError(t, err)
+
+	// Each co-signer signs on their own, and sends their signature as BCS
+	var received []crypto.IndexedAnySignature
+	for _, signer := range []*crypto.SingleSigner{signers[2], signers[1]} {
+		cosigner, err := NewMultiKeyAccount(pubKeys, 2, signer)
+		assert.NoError(t, err)
+		assert.Equal(t, coordinator.Address, cosigner.Address)
+		_, err = cosigner.Sign(message)
+		assert.Error(t, err)
+		partial, err := cosigner.SignPartial(message)
+		assert.NoError(t, err)
+		assert.Len(t, partial, 1)
+		partialBytes, err := bcs.Serialize(&partial[0])
+		assert.NoError(t, err)
+		decoded := crypto.IndexedAnySignature{}
+		assert.NoError(t, bcs.Deserialize(&decoded, partialBytes))
+		received = append(received, decoded)
+	}
+	assert.Equal(t, uint8(2), received[0].Index)
+	assert.Equal(t, uint8(1), received[1].Index)
+
+	_, err = coordinator.CombineSignatures(message, received[0])
+	assert.Error(t, err)
+	_, err = coordinator.CombineSignatures(message, received[0], received[0])
+	assert.Error(t, err)
+	wrongIndex := crypto.IndexedAnySignature{Index: 0, Signature: received[0].Signature}
+	_, err = coordinator.CombineSignatures(message, received[1], wrongIndex)
+	assert.Error(t, err)
+
+	auth, err := coordinator.CombineSignatures(message, received...)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{1, 2}, auth.Auth.(*crypto.MultiKeyAuthenticator).Sig.Bitmap.Indices())
+	signedTxn, err := rawTxn.SignedTransactionWithAuthenticator(auth)
+	assert.NoError(t, err)
+	assert.NoError(t, signedTxn.Verify())
+}
+
+func TestMultiKeyAccount_TransactionSigner(t *testing.T) {
+	node := newTestNode(t)
+	signers, pubKeys := testMultiKeySigners(t)
+	account, err := NewMultiKeyAccount(pubKeys, 2, signers[1], signers[0])
+	assert.NoError(t, err)
+	node.SetAccount(account.Address, 0, account.AuthKey().ToHex())
+
+	var signer TransactionSigner = account
+	_, err = node.client.BuildSignAndSubmitTransaction(signer, envelopeTestPayload(t), GasUnitPrice(100), MaxGasAmount(1000))
+	assert.NoError(t, err)
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.NoError(t, submitted[0].Verify())
+	verification, err := node.client.VerifyTransaction(submitted[0])
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Err())
+
+	// Simulation uses the local keys first, with empty signatures of each key's type
+	simulation := account.SimulationAuthenticator().Auth.(*crypto.MultiKeyAuthenticator)
+	assert.Equal(t, []uint8{0, 1}, simulation.Sig.Bitmap.Indices())
+	assert.Equal(t, crypto.AnySignatureVariantEd25519, simulation.Sig.Signatures[0].Variant)
+	assert.Equal(t, crypto.AnySignatureVariantSecp256k1, simulation.Sig.Signatures[1].Variant)
+	remote, err := NewMultiKeyAccount(pubKeys, 2, signers[2])
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{0, 2}, remote.SimulationAuthenticator().Auth.(*crypto.MultiKeyAuthenticator).Sig.Bitmap.Indices())
+}
@@ -0,0 +1,292 @@
+package endless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+)
+
+const (
+	// MultisigAccountResourceType is the resource holding an on-chain multisig account's owners and proposals
+	MultisigAccountResourceType = "0x1::multisig_account::MultisigAccount"
+	// MultisigTransactionType is the type of proposals in the multisig account's transactions table
+	MultisigTransactionType = "0x1::multisig_account::MultisigTransaction"
+)
+
+var (
+	// ErrMultisigPayloadMismatch is returned when a payload isn't the one a multisig proposal was created with
+	ErrMultisigPayloadMismatch = errors.New("payload does not match the multisig proposal")
+	// ErrMultisigNotExecutable is returned when executing a multisig proposal that isn't next or doesn't have enough
+	// approvals
+	ErrMultisigNotExecutable = errors.New("multisig proposal is not executable")
+)
+
+//region MultisigAccount
+
+// MultisigAccount reads the state and proposals of an on-chain multisig account, created with
+// [MultisigCreateAccountPayload], and executes approved proposals.  Proposals are created and voted on with
+// [MultisigCreateTransactionPayload], [MultisigApprovePayload], and [MultisigRejectPayload].
+//
+//	multisig := endless.NewMultisigAccount(client, multisigAddress)
+//	pending, err := multisig.PendingTransactions()
+//	if err == nil && len(pending) > 0 && pending[0].Executable {
+//		response, err = multisig.Execute(owner, nil)
+//	}
+type MultisigAccount struct {
+	Address AccountAddress
+	client  *NodeClient
+}
+
+// NewMultisigAccount returns a [MultisigAccount] for the multisig at address
+func NewMultisigAccount(client *NodeClient, address AccountAddress) *MultisigAccount {
+	return &MultisigAccount{Address: address, client: client}
+}
+
+// MultisigAccountInfo is the on-chain state of a multisig account
+type MultisigAccountInfo struct {
+	Owners                     []AccountAddress
+	NumSignaturesRequired      uint64
+	Metadata                   map[string][]byte // Metadata values are BCS encoded
+	NextSequenceNumber         uint64            // NextSequenceNumber is the sequence number the next proposal will get
+	LastExecutedSequenceNumber uint64            // LastExecutedSequenceNumber is the last proposal executed or rejected
+	TransactionsHandle         string            // TransactionsHandle is the handle of the table of proposals
+}
+
+// IsOwner returns true if the address is an owner of the multisig
+func (info *MultisigAccountInfo) IsOwner(address AccountAddress) bool {
+	for _, owner := range info.Owners {
+		if owner == address {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON deserializes the data of a [MultisigAccountResourceType] resource
+func (info *MultisigAccountInfo) UnmarshalJSON(b []byte) error {
+	type inner struct {
+		Owners                     []AccountAddress `json:"owners"`
+		NumSignaturesRequired      api.U64          `json:"num_signatures_required"`
+		NextSequenceNumber         api.U64          `json:"next_sequence_number"`
+		LastExecutedSequenceNumber api.U64          `json:"last_executed_sequence_number"`
+		Metadata                   struct {
+			Data []struct {
+				Key   string       `json:"key"`
+				Value api.HexBytes `json:"value"`
+			} `json:"data"`
+		} `json:"metadata"`
+		Transactions struct {
+			Handle string `json:"handle"`
+		} `json:"transactions"`
+	}
+	data := &inner{}
+	if err := json.Unmarshal(b, data); err != nil {
+		return err
+	}
+	info.Owners = data.Owners
+	info.NumSignaturesRequired = data.NumSignaturesRequired.ToUint64()
+	info.NextSequenceNumber = data.NextSequenceNumber.ToUint64()
+	info.LastExecutedSequenceNumber = data.LastExecutedSequenceNumber.ToUint64()
+	info.Metadata = make(map[string][]byte, len(data.Metadata.Data))
+	for _, entry := range data.Metadata.Data {
+		info.Metadata[entry.Key] = entry.Value
+	}
+	info.TransactionsHandle = data.Transactions.Handle
+	return nil
+}
+
+// Info fetches the owners, threshold, metadata, and sequence numbers of the multisig.
+// Optionally, a ledgerVersion can be given to get the state at a specific ledger version
+func (ma *MultisigAccount) Info(ledgerVersion ...uint64) (*MultisigAccountInfo, error) {
+	au := ma.client.baseUrl.JoinPath("accounts", ma.Address.String(), "resource", MultisigAccountResourceType)
+	if len(ledgerVersion) > 0 {
+		params := url.Values{}
+		params.Set("ledger_version", strconv.FormatUint(ledgerVersion[0], 10))
+		au.RawQuery = params.Encode()
+	}
+	resource, err := Get[struct {
+		Data *MultisigAccountInfo `json:"data"`
+	}](ma.client, au.String())
+	if err != nil {
+		return nil, fmt.Errorf("get multisig account err: %w", err)
+	}
+	if resource.Data == nil {
+		return nil, errors.New("multisig account resource has no data")
+	}
+	return resource.Data, nil
+}
+
+// Transaction fetches the proposal with the sequence number from the multisig's transactions table
+func (ma *MultisigAccount) Transaction(sequenceNumber uint64, ledgerVersion ...uint64) (*MultisigTransaction, error) {
+	info, err := ma.Info(ledgerVersion...)
+	if err != nil {
+		return nil, err
+	}
+	return ma.transaction(info, sequenceNumber, ledgerVersion...)
+}
+
+// PendingTransactions fetches the proposals not yet executed or rejected, in order of sequence number.  All are
+// read at the same ledger version.
+func (ma *MultisigAccount) PendingTransactions(ledgerVersion ...uint64) ([]*MultisigTransaction, error) {
+	if len(ledgerVersion) == 0 {
+		nodeInfo, err := ma.client.Info()
+		if err != nil {
+			return nil, err
+		}
+		ledgerVersion = []uint64{nodeInfo.LedgerVersion()}
+	}
+	info, err := ma.Info(ledgerVersion...)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*MultisigTransaction, 0)
+	for sequenceNumber := info.LastExecutedSequenceNumber + 1; sequenceNumber < info.NextSequenceNumber; sequenceNumber++ {
+		txn, err := ma.transaction(info, sequenceNumber, ledgerVersion...)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, txn)
+	}
+	return pending, nil
+}
+
+// Execute executes the next pending proposal, after checking it has enough approvals.  The payload can be nil if the
+// proposal stored its payload on-chain, and is required if it was created with
+// [MultisigCreateTransactionPayloadWithHash], in which case it's checked against the hash first.  The sender must
+// be an owner of the multisig.
+func (ma *MultisigAccount) Execute(sender TransactionSigner, payload *MultisigTransactionPayload, options ...any) (*api.SubmitTransactionResponse, error) {
+	info, err := ma.Info()
+	if err != nil {
+		return nil, err
+	}
+	txn, err := ma.transaction(info, info.LastExecutedSequenceNumber+1)
+	if err != nil {
+		return nil, err
+	}
+	if !txn.Executable {
+		return nil, fmt.Errorf("%w: %d of %d approvals", ErrMultisigNotExecutable, len(txn.Approvals), info.NumSignaturesRequired)
+	}
+	if payload == nil && txn.Payload == nil {
+		return nil, errors.New("multisig proposal only has a payload hash, the payload must be given")
+	}
+	if payload != nil {
+		if err = txn.VerifyPayload(payload); err != nil {
+			return nil, err
+		}
+	}
+	return ma.client.BuildSignAndSubmitTransaction(sender, TransactionPayload{
+		Payload: &Multisig{MultisigAddress: ma.Address, Payload: payload},
+	}, options...)
+}
+
+// transaction fetches a proposal, counting the votes of current owners as the framework does
+func (ma *MultisigAccount) transaction(info *MultisigAccountInfo, sequenceNumber uint64, ledgerVersion ...uint64) (*MultisigTransaction, error) {
+	item, err := TableItem[*multisigTransactionJson](ma.client, info.TransactionsHandle, "u64", MultisigTransactionType, strconv.FormatUint(sequenceNumber, 10), ledgerVersion...)
+	if err != nil {
+		return nil, fmt.Errorf("get multisig transaction %d err: %w", sequenceNumber, err)
+	}
+	txn := &MultisigTransaction{
+		SequenceNumber:   sequenceNumber,
+		Creator:          item.Creator,
+		CreationTimeSecs: item.CreationTimeSecs.ToUint64(),
+		Approvals:        make([]AccountAddress, 0),
+		Rejections:       make([]AccountAddress, 0),
+	}
+	if len(item.Payload.Vec) > 0 {
+		txn.Payload = item.Payload.Vec[0]
+	}
+	if len(item.PayloadHash.Vec) > 0 {
+		txn.PayloadHash = item.PayloadHash.Vec[0]
+	}
+	for _, vote := range item.Votes.Data {
+		if !info.IsOwner(vote.Key) {
+			continue
+		}
+		if vote.Value {
+			txn.Approvals = append(txn.Approvals, vote.Key)
+		} else {
+			txn.Rejections = append(txn.Rejections, vote.Key)
+		}
+	}
+	next := sequenceNumber == info.LastExecutedSequenceNumber+1
+	txn.Executable = next && uint64(len(txn.Approvals)) >= info.NumSignaturesRequired
+	txn.Rejectable = next && uint64(len(txn.Rejections)) >= info.NumSignaturesRequired
+	return txn, nil
+}
+
+//endregion
+
+//region MultisigTransaction
+
+// MultisigTransaction is a proposal on a multisig account
+type MultisigTransaction struct {
+	SequenceNumber   uint64
+	Payload          []byte           // Payload is the BCS of the [MultisigTransactionPayload], nil if only the hash is stored
+	PayloadHash      []byte           // PayloadHash is the SHA3-256 of the payload, nil if the payload is stored
+	Approvals        []AccountAddress // Approvals by current owners
+	Rejections       []AccountAddress // Rejections by current owners
+	Creator          AccountAddress
+	CreationTimeSecs uint64
+	Executable       bool // Executable is true if the proposal is next and approved by enough owners
+	Rejectable       bool // Rejectable is true if the proposal is next and rejected by enough owners
+}
+
+// DecodePayload deserializes the stored payload, returning nil if only the hash is stored
+func (txn *MultisigTransaction) DecodePayload() (*MultisigTransactionPayload, error) {
+	if txn.Payload == nil {
+		return nil, nil
+	}
+	payload := &MultisigTransactionPayload{}
+	if err := bcs.Deserialize(payload, txn.Payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// VerifyPayload checks the payload is the one the proposal was created with, against the stored payload or its hash.
+// Returns [ErrMultisigPayloadMismatch] if not.
+func (txn *MultisigTransaction) VerifyPayload(payload *MultisigTransactionPayload) error {
+	payloadBytes, err := bcs.Serialize(payload)
+	if err != nil {
+		return err
+	}
+	switch {
+	case txn.Payload != nil:
+		if !bytes.Equal(txn.Payload, payloadBytes) {
+			return ErrMultisigPayloadMismatch
+		}
+	case txn.PayloadHash != nil:
+		if !bytes.Equal(txn.PayloadHash, Sha3256Hash([][]byte{payloadBytes})) {
+			return ErrMultisigPayloadMismatch
+		}
+	default:
+		return fmt.Errorf("%w: proposal has no payload or hash", ErrMultisigPayloadMismatch)
+	}
+	return nil
+}
+
+// multisigTransactionJson is the JSON of a [MultisigTransactionType] table item
+type multisigTransactionJson struct {
+	Payload struct {
+		Vec []api.HexBytes `json:"vec"`
+	} `json:"payload"`
+	PayloadHash struct {
+		Vec []api.HexBytes `json:"vec"`
+	} `json:"payload_hash"`
+	Votes struct {
+		Data []struct {
+			Key   AccountAddress `json:"key"`
+			Value bool           `json:"value"`
+		} `json:"data"`
+	} `json:"votes"`
+	Creator          AccountAddress `json:"creator"`
+	CreationTimeSecs api.U64        `json:"creation_time_secs"`
+}
+
+//endregion
@@ -0,0 +1,153 @@
+package endless
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMultisig serves a multisig account resource and its transactions table from a testNode
+type testMultisig struct {
+	address      AccountAddress
+	owners       []AccountAddress
+	lastExecuted uint64
+	transactions map[string]map[string]any
+}
+
+func newTestMultisig(t *testing.T, node *testNode, owners []AccountAddress) *testMultisig {
+	multisig := &testMultisig{address: AccountAddress{0xaa, 0xbb}, owners: owners, transactions: make(map[string]map[string]any)}
+	name, err := bcs.SerializeSingle(func(ser *bcs.Serializer) { ser.WriteString("treasury") })
+	assert.NoError(t, err)
+	node.Handlers["GET /accounts/"+multisig.address.String()+"/resource/"+MultisigAccountResourceType] = func(w http.ResponseWriter, r *http.Request) {
+		owners := make([]string, len(multisig.owners))
+		for i, owner := range multisig.owners {
+			owners[i] = owner.String()
+		}
+		node.writeJson(w, http.StatusOK, map[string]any{
+			"type": MultisigAccountResourceType,
+			"data": map[string]any{
+				"owners":                        owners,
+				"num_signatures_required":       "2",
+				"next_sequence_number":          "3",
+				"last_executed_sequence_number": strconv.FormatUint(multisig.lastExecuted, 10),
+				"metadata":                      map[string]any{"data": []any{map[string]any{"key": "name", "value": BytesToHex(name)}}},
+				"transactions":                  map[string]any{"handle": "0x7ab1e"},
+			},
+		})
+	}
+	node.Handlers["POST /tables/0x7ab1e/item"] = func(w http.ResponseWriter, r *http.Request) {
+		request := map[string]any{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "u64", request["key_type"])
+		assert.Equal(t, MultisigTransactionType, request["value_type"])
+		item, ok := multisig.transactions[request["key"].(string)]
+		if !ok {
+			node.writeError(w, http.StatusNotFound, "Table Item not found", "table_item_not_found", 0)
+			return
+		}
+		node.writeJson(w, http.StatusOK, item)
+	}
+	return multisig
+}
+
+func testMultisigTransaction(creator AccountAddress, payload []byte, hash []byte, votes map[AccountAddress]bool) map[string]any {
+	payloadVec, hashVec := []string{}, []string{}
+	if payload != nil {
+		payloadVec = append(payloadVec, BytesToHex(payload))
+	}
+	if hash != nil {
+		hashVec = append(hashVec, BytesToHex(hash))
+	}
+	voteData := make([]any, 0)
+	for address, approved := range votes {
+		voteData = append(voteData, map[string]any{"key": address.String(), "value": approved})
+	}
+	return map[string]any{
+		"payload":            map[string]any{"vec": payloadVec},
+		"payload_hash":       map[string]any{"vec": hashVec},
+		"votes":              map[string]any{"data": voteData},
+		"creator":            creator.String(),
+		"creation_time_secs": "1700000000",
+	}
+}
+
+func testMultisigPayload(t *testing.T, amount uint64) *MultisigTransactionPayload {
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, amount)
+	assert.NoError(t, err)
+	return &MultisigTransactionPayload{Variant: MultisigTransactionPayloadVariantEntryFunction, Payload: entryFunction}
+}
+
+func TestMultisigAccount(t *testing.T) {
+	node := newTestNode(t)
+	owner1, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner3, err := NewEd25519Account()
+	assert.NoError(t, err)
+	formerOwner := AccountAddress{0x01, 0x02}
+	node.SetAccount(owner1.Address, 0, owner1.AuthKey().ToHex())
+	multisig := newTestMultisig(t, node, []AccountAddress{owner1.Address, owner2.Address, owner3.Address})
+
+	storedPayload := testMultisigPayload(t, 100)
+	storedBytes, err := bcs.Serialize(storedPayload)
+	assert.NoError(t, err)
+	hashedPayload := testMultisigPayload(t, 200)
+	hashedBytes, err := bcs.Serialize(hashedPayload)
+	assert.NoError(t, err)
+	multisig.transactions["1"] = testMultisigTransaction(owner1.Address, storedBytes, nil, map[AccountAddress]bool{owner1.Address: true, owner3.Address: true})
+	multisig.transactions["2"] = testMultisigTransaction(owner2.Address, nil, Sha3256Hash([][]byte{hashedBytes}), map[AccountAddress]bool{owner2.Address: true, formerOwner: true})
+
+	account := NewMultisigAccount(node.client, multisig.address)
+	info, err := account.Info()
+	assert.NoError(t, err)
+	assert.Equal(t, []AccountAddress{owner1.Address, owner2.Address, owner3.Address}, info.Owners)
+	assert.Equal(t, uint64(2), info.NumSignaturesRequired)
+	assert.Equal(t, uint64(3), info.NextSequenceNumber)
+	assert.Equal(t, append([]byte{8}, "treasury"...), info.Metadata["name"])
+
+	pending, err := account.PendingTransactions()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, uint64(1), pending[0].SequenceNumber)
+	assert.Len(t, pending[0].Approvals, 2)
+	assert.True(t, pending[0].Executable)
+	decoded, err := pending[0].DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, storedPayload, decoded)
+	assert.NoError(t, pending[0].VerifyPayload(storedPayload))
+	assert.ErrorIs(t, pending[0].VerifyPayload(hashedPayload), ErrMultisigPayloadMismatch)
+
+	// Votes of former owners don't count, and only the next proposal can execute
+	assert.Equal(t, []AccountAddress{owner2.Address}, pending[1].Approvals)
+	assert.False(t, pending[1].Executable)
+	assert.Nil(t, pending[1].Payload)
+	assert.NoError(t, pending[1].VerifyPayload(hashedPayload))
+	assert.ErrorIs(t, pending[1].VerifyPayload(storedPayload), ErrMultisigPayloadMismatch)
+
+	// Execute the stored payload
+	_, err = account.Execute(owner1, hashedPayload, SequenceNumber(0), GasUnitPrice(100))
+	assert.ErrorIs(t, err, ErrMultisigPayloadMismatch)
+	_, err = account.Execute(owner1, nil, SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 1)
+	assert.Equal(t, &Multisig{MultisigAddress: multisig.address}, submitted[0].Transaction.Payload.Payload)
+
+	// The hash only proposal needs its payload, and more approvals
+	multisig.lastExecuted = 1
+	_, err = account.Execute(owner1, hashedPayload, SequenceNumber(1), GasUnitPrice(100))
+	assert.ErrorIs(t, err, ErrMultisigNotExecutable)
+	multisig.transactions["2"] = testMultisigTransaction(owner2.Address, nil, Sha3256Hash([][]byte{hashedBytes}), map[AccountAddress]bool{owner2.Address: true, owner3.Address: true})
+	_, err = account.Execute(owner1, nil, SequenceNumber(1), GasUnitPrice(100))
+	assert.Error(t, err)
+	_, err = account.Execute(owner1, hashedPayload, SequenceNumber(1), GasUnitPrice(100))
+	assert.NoError(t, err)
+	submitted = node.Submitted()
+	assert.Len(t, submitted, 2)
+	assert.Equal(t, &Multisig{MultisigAddress: multisig.address, Payload: hashedPayload}, submitted[1].Transaction.Payload.Payload)
+}
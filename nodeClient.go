@@ -12,8 +12,11 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/endless-labs/endless-go-sdk/api"
@@ -42,6 +45,8 @@ type NodeClient struct {
 	baseUrl *url.URL          // Base URL of the node
 	chainId uint8             // Chain ID of the network
 	headers map[string]string // Headers to be added to every transaction
+
+	longPollUnsupported atomic.Bool // longPollUnsupported is set when the node has no wait_by_hash endpoint
 }
 
 // NewNodeClient creates a new client for interacting with an EndlessCoin nodE API
@@ -337,13 +342,17 @@ func (rc *NodeClient) getBlockCommon(restUrl *url.URL, withTransactions bool) (b
 }
 
 // WaitForTransaction does a long-GET for one transaction and wait for it to complete.
-// Initially poll at 10 Hz for up to 1 second if node replies with 404 (wait for txn to propagate).
+//
+// It uses the node's wait_by_hash long-poll endpoint, which returns as soon as the transaction commits.  If the
+// node does not support it, it falls back to [NodeClient.PollForTransaction].
 //
 // Optional arguments:
-//   - PollPeriod: time.Duration, how often to poll for the transaction. Default 100ms.
-//   - PollTimeout: time.Duration, how long to wait for the transaction. Default 10s.
+//   - [PollPeriod] how often to poll for the transaction at first. Default 100ms.
+//   - [PollMaxPeriod] the longest time between polls, as the period backs off. Default 1s.
+//   - [PollTimeout] how long to wait for the transaction. Default 10s.
+//   - [TransactionLifecycle] callbacks for changes in the transaction's state
 func (rc *NodeClient) WaitForTransaction(txnHash string, options ...any) (data *api.UserTransaction, err error) {
-	return rc.PollForTransaction(txnHash, options...)
+	return rc.waitForTransaction(txnHash, true, options...)
 }
 
 // PollPeriod is an option to PollForTransactions
@@ -352,87 +361,218 @@ type PollPeriod time.Duration
 // PollTimeout is an option to PollForTransactions
 type PollTimeout time.Duration
 
-func getTransactionPollOptions(defaultPeriod, defaultTimeout time.Duration, options ...any) (period time.Duration, timeout time.Duration, err error) {
-	period = defaultPeriod
-	timeout = defaultTimeout
+// PollMaxPeriod is an option to PollForTransactions, the poll period backs off from [PollPeriod] up to this
+type PollMaxPeriod time.Duration
+
+// ErrTransactionExpired is returned when waiting for a transaction that expired without committing
+var ErrTransactionExpired = errors.New("transaction expired")
+
+// TransactionLifecycle is an option to wait and poll for transactions, with callbacks for changes in the
+// transaction's state.  Any callback may be nil.  Each fires at most once per transaction.  When waiting for several
+// transactions, callbacks may be called concurrently.
+type TransactionLifecycle struct {
+	OnPending   func(txn *api.PendingTransaction) // OnPending is called the first time the transaction is seen in mempool
+	OnCommitted func(txn *api.UserTransaction)    // OnCommitted is called when the transaction commits successfully
+	OnFailed    func(txn *api.UserTransaction)    // OnFailed is called when the transaction commits with a failure
+	OnExpired   func(hash string)                 // OnExpired is called when the ledger timestamp passes the expiration
+}
+
+// TransactionWaitResult is the outcome of waiting for a single transaction
+type TransactionWaitResult struct {
+	Hash        string               // Hash of the transaction
+	Transaction *api.UserTransaction // Transaction is the committed transaction, nil if it did not commit
+	Err         error                // Err is the reason the transaction did not commit e.g. [ErrTransactionExpired]
+}
+
+type transactionPollOptions struct {
+	period    time.Duration
+	maxPeriod time.Duration
+	timeout   time.Duration
+	lifecycle *TransactionLifecycle
+}
+
+func getTransactionPollOptions(defaultPeriod, defaultTimeout time.Duration, options ...any) (pollOptions transactionPollOptions, err error) {
+	pollOptions.period = defaultPeriod
+	pollOptions.maxPeriod = time.Second
+	pollOptions.timeout = defaultTimeout
+	pollOptions.lifecycle = &TransactionLifecycle{}
 	for i, arg := range options {
 		switch value := arg.(type) {
 		case PollPeriod:
-			period = time.Duration(value)
+			pollOptions.period = time.Duration(value)
+		case PollMaxPeriod:
+			pollOptions.maxPeriod = time.Duration(value)
 		case PollTimeout:
-			timeout = time.Duration(value)
+			pollOptions.timeout = time.Duration(value)
+		case TransactionLifecycle:
+			pollOptions.lifecycle = &value
+		case *TransactionLifecycle:
+			pollOptions.lifecycle = value
 		default:
 			err = fmt.Errorf("PollForTransactions arg %d bad type %T", i+1, arg)
 			return
 		}
 	}
+	if pollOptions.maxPeriod < pollOptions.period {
+		pollOptions.maxPeriod = pollOptions.period
+	}
 	return
 }
 
-// PollForTransaction waits up to 10 seconds for a transaction to be done, polling at 10Hz
-// Accepts options PollPeriod and PollTimeout which should wrap time.Duration values.
+// PollForTransaction waits up to 10 seconds for a transaction to be done, polling at 10Hz and backing off
+// Accepts options PollPeriod, PollMaxPeriod and PollTimeout which should wrap time.Duration values, and
+// [TransactionLifecycle] callbacks.
 // Not just a degenerate case of PollForTransactions, it may return additional information for the single transaction polled.
+//
+// Returns [ErrTransactionExpired] if the ledger timestamp passes the transaction's expiration before it commits.
 func (rc *NodeClient) PollForTransaction(hash string, options ...any) (*api.UserTransaction, error) {
-	period, timeout, err := getTransactionPollOptions(100*time.Millisecond, 10*time.Second, options...)
+	return rc.waitForTransaction(hash, false, options...)
+}
+
+// waitForTransaction waits for a transaction, with long-polling if enabled and supported
+func (rc *NodeClient) waitForTransaction(hash string, longPoll bool, options ...any) (*api.UserTransaction, error) {
+	pollOptions, err := getTransactionPollOptions(100*time.Millisecond, 10*time.Second, options...)
 	if err != nil {
 		return nil, err
 	}
-	start := time.Now()
-	deadline := start.Add(timeout)
+	lifecycle := pollOptions.lifecycle
+	deadline := time.Now().Add(pollOptions.timeout)
+	period := pollOptions.period
+	expiration := uint64(0)
+
 	for {
-		if time.Now().After(deadline) {
-			return nil, errors.New("PollForTransaction timeout")
+		requestStart := time.Now()
+		var txn *api.Transaction
+		waited := false
+		if longPoll && !rc.longPollUnsupported.Load() {
+			txn, waited, err = rc.waitTransactionByHash(hash)
+		} else {
+			txn, err = rc.TransactionByHash(hash)
 		}
-		time.Sleep(period)
-		txn, err := rc.TransactionByHash(hash)
 		if err == nil {
-			if txn.Type == api.TransactionVariantPending {
-				// not done yet!
-			} else if txn.Type == api.TransactionVariantUser {
-				// done!
+			switch txn.Type {
+			case api.TransactionVariantPending:
+				if expiration == 0 {
+					pendingTxn, err := txn.PendingTransaction()
+					if err == nil {
+						expiration = pendingTxn.ExpirationTimestampSecs
+						if lifecycle.OnPending != nil {
+							lifecycle.OnPending(pendingTxn)
+						}
+					}
+				}
+			case api.TransactionVariantUser:
 				slog.Debug("txn done", "hash", hash)
-				return txn.UserTransaction()
+				userTxn, err := txn.UserTransaction()
+				if err != nil {
+					return nil, err
+				}
+				if userTxn.Success {
+					if lifecycle.OnCommitted != nil {
+						lifecycle.OnCommitted(userTxn)
+					}
+				} else if lifecycle.OnFailed != nil {
+					lifecycle.OnFailed(userTxn)
+				}
+				return userTxn, nil
 			}
 		}
+
+		// Once the expiration is known, the transaction can no longer commit after the ledger passes it
+		if expiration != 0 {
+			ledgerTimestamp, err := rc.ledgerTimestampSeconds()
+			if err == nil && ledgerTimestamp >= expiration {
+				// Check one last time, it may have committed since
+				txn, err = rc.TransactionByHash(hash)
+				if err == nil && txn.Type == api.TransactionVariantUser {
+					continue
+				}
+				if lifecycle.OnExpired != nil {
+					lifecycle.OnExpired(hash)
+				}
+				return nil, ErrTransactionExpired
+			}
+		}
+
+		now := time.Now()
+		if now.After(deadline) {
+			return nil, errors.New("PollForTransaction timeout")
+		}
+		if waited && now.Sub(requestStart) >= period {
+			// The node already waited for us
+			continue
+		}
+		time.Sleep(min(period, deadline.Sub(now)))
+		period = min(period*3/2, pollOptions.maxPeriod)
 	}
 }
 
-// PollForTransactions waits up to 10 seconds for transactions to be done, polling at 10Hz
-// Accepts options PollPeriod and PollTimeout which should wrap time.Duration values.
-func (rc *NodeClient) PollForTransactions(txnHashes []string, options ...any) error {
-	period, timeout, err := getTransactionPollOptions(100*time.Millisecond, 10*time.Second, options...)
+// waitTransactionByHash uses the wait_by_hash long-poll endpoint, returning false if it is not supported by the node
+func (rc *NodeClient) waitTransactionByHash(txnHash string) (data *api.Transaction, waited bool, err error) {
+	restUrl := rc.baseUrl.JoinPath("transactions/wait_by_hash", txnHash)
+	data, err = Get[*api.Transaction](rc, restUrl.String())
 	if err != nil {
-		return err
+		var httpError *HttpError
+		if errors.As(err, &httpError) && httpError.StatusCode == http.StatusNotFound {
+			// A missing route is not the same as a missing transaction
+			if apiError, ok := httpError.ApiError(); !ok || apiError.ErrorCode != "transaction_not_found" {
+				rc.longPollUnsupported.Store(true)
+				data, err = rc.TransactionByHash(txnHash)
+				return data, false, err
+			}
+		}
+		return nil, false, fmt.Errorf("wait for transaction api err: %w", err)
 	}
-	hashSet := make(map[string]bool, len(txnHashes))
-	for _, hash := range txnHashes {
-		hashSet[hash] = true
+	return data, true, nil
+}
+
+// ledgerTimestampSeconds fetches the ledger timestamp, without changing any client state
+func (rc *NodeClient) ledgerTimestampSeconds() (uint64, error) {
+	info, err := Get[NodeInfo](rc, rc.baseUrl.String())
+	if err != nil {
+		return 0, err
 	}
-	start := time.Now()
-	deadline := start.Add(timeout)
-	for len(hashSet) > 0 {
-		if time.Now().After(deadline) {
-			return errors.New("PollForTransactions timeout")
-		}
-		time.Sleep(period)
-		for _, hash := range txnHashes {
-			if !hashSet[hash] {
-				// already done
-				continue
-			}
-			txn, err := rc.TransactionByHash(hash)
-			if err == nil {
-				if txn.Type == api.TransactionVariantPending {
-					// not done yet!
-				} else if txn.Type == api.TransactionVariantUser {
-					// done!
-					delete(hashSet, hash)
-					slog.Debug("txn done", "hash", hash)
-				}
+	return info.LedgerTimestamp() / 1_000_000, nil
+}
+
+// PollForTransactions waits up to 10 seconds for transactions to be done, polling at 10Hz and backing off
+// Accepts options PollPeriod, PollMaxPeriod and PollTimeout which should wrap time.Duration values, and
+// [TransactionLifecycle] callbacks.
+//
+// The timeout is for all the transactions together, not each.  [TransactionLifecycle] callbacks are called from
+// several goroutines at once, so they must be safe for concurrent use.
+//
+// Returns a result per hash, in the same order as txnHashes.  The error is only set for invalid options.
+func (rc *NodeClient) PollForTransactions(txnHashes []string, options ...any) ([]TransactionWaitResult, error) {
+	pollOptions, err := getTransactionPollOptions(100*time.Millisecond, 10*time.Second, options...)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(pollOptions.timeout)
+
+	// Limit the number of concurrent requests to the node
+	const maxConcurrent = 8
+	semaphore := make(chan struct{}, maxConcurrent)
+	results := make([]TransactionWaitResult, len(txnHashes))
+	var wg sync.WaitGroup
+	for i, hash := range txnHashes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			// Waiting for a slot uses up the shared timeout
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				results[i] = TransactionWaitResult{Hash: hash, Err: errors.New("PollForTransactions timeout")}
+				return
 			}
-		}
+			txn, err := rc.PollForTransaction(hash, append(slices.Clone(options), PollTimeout(remaining))...)
+			results[i] = TransactionWaitResult{Hash: hash, Transaction: txn, Err: err}
+		}()
 	}
-	return nil
+	wg.Wait()
+	return results, nil
 }
 
 // Transactions Get recent transactions.
@@ -653,15 +793,20 @@ type EstimatePrioritizedGasUnitPrice bool
 // TODO: This needs to support RawTransactionWithData
 // TODO: Support multikey simulation
 func (rc *NodeClient) SimulateTransaction(rawTxn *RawTransaction, sender TransactionSigner, options ...any) (data []*api.UserTransaction, err error) {
-	// build authenticator for simulation
-	derivationScheme := sender.PubKey().Scheme()
-	switch derivationScheme {
-	case crypto.MultiEd25519Scheme:
-	case crypto.MultiKeyScheme:
-		// todo: add support for multikey simulation on the node
-		return nil, fmt.Errorf("currently unsupported sender derivation scheme %v", derivationScheme)
+	// build authenticator for simulation, a multi auth key account has no single public key
+	if pubKey := sender.PubKey(); pubKey != nil {
+		derivationScheme := pubKey.Scheme()
+		switch derivationScheme {
+		case crypto.MultiEd25519Scheme:
+		case crypto.MultiKeyScheme:
+			// todo: add support for multikey simulation on the node
+			return nil, fmt.Errorf("currently unsupported sender derivation scheme %v", derivationScheme)
+		}
 	}
 	auth := sender.SimulationAuthenticator()
+	if auth == nil {
+		return nil, fmt.Errorf("sender %s can't create a simulation authenticator", sender.AccountAddress())
+	}
 
 	// generate signed transaction for simulation (with zero signature)
 	signedTxn, err := rawTxn.SignedTransactionWithAuthenticator(auth)
@@ -1026,6 +1171,27 @@ func (rc *NodeClient) View(payload *ViewPayload, ledgerVersion ...uint64) (data
 	return data, nil
 }
 
+// TableItem fetches an item from a Move table by its handle, e.g. a [MultisigAccountInfo] TransactionsHandle, and
+// parses it into the given type with JSON.  The key is given as it's represented in JSON, e.g. a u64 as a string.
+// Optionally, a ledgerVersion can be given to get the item at a specific ledger version
+func TableItem[T any](rc *NodeClient, handle string, keyType string, valueType string, key any, ledgerVersion ...uint64) (data T, err error) {
+	au := rc.baseUrl.JoinPath("tables", handle, "item")
+	if len(ledgerVersion) > 0 {
+		params := url.Values{}
+		params.Set("ledger_version", strconv.FormatUint(ledgerVersion[0], 10))
+		au.RawQuery = params.Encode()
+	}
+	body, err := json.Marshal(map[string]any{"key_type": keyType, "value_type": valueType, "key": key})
+	if err != nil {
+		return data, err
+	}
+	data, err = Post[T](rc, au.String(), ContentTypeApplicationJson, bytes.NewReader(body))
+	if err != nil {
+		return data, fmt.Errorf("get table item api err: %w", err)
+	}
+	return data, nil
+}
+
 // EstimateGasPrice estimates the gas price given on-chain data
 // TODO: add caching for some period of time
 func (rc *NodeClient) EstimateGasPrice() (info EstimateGasInfo, err error) {
@@ -0,0 +1,119 @@
+package endless
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func submitWaitTestTxn(t *testing.T, node *testNode, seq uint64, expiration uint64) string {
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	signedTxn := outboxTestTxn(t, node, sender, seq, expiration)
+	_, err = node.client.SubmitTransaction(signedTxn)
+	assert.NoError(t, err)
+	hash, err := signedTxn.Hash()
+	assert.NoError(t, err)
+	return hash
+}
+
+func TestNodeClient_WaitForTransactionLongPoll(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	hash := submitWaitTestTxn(t, node, 0, 2000)
+
+	calls := atomic.Int32{}
+	node.Handlers["GET /transactions/wait_by_hash/"+hash] = func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		node.Commit(hash, true, "Executed successfully")
+		node.mutex.Lock()
+		defer node.mutex.Unlock()
+		node.writeJson(w, http.StatusOK, node.txnJson(node.txns[hash]))
+	}
+
+	committed := false
+	txn, err := node.client.WaitForTransaction(hash, PollPeriod(time.Millisecond), TransactionLifecycle{
+		OnCommitted: func(txn *api.UserTransaction) { committed = true },
+	})
+	assert.NoError(t, err)
+	assert.True(t, txn.Success)
+	assert.True(t, committed)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.False(t, node.client.longPollUnsupported.Load())
+}
+
+func TestNodeClient_WaitForTransactionFallback(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	hash := submitWaitTestTxn(t, node, 0, 2000)
+
+	var pending *api.PendingTransaction
+	failed := false
+	txn, err := node.client.WaitForTransaction(hash, PollPeriod(time.Millisecond), PollMaxPeriod(5*time.Millisecond), TransactionLifecycle{
+		OnPending: func(txn *api.PendingTransaction) {
+			pending = txn
+			go node.Commit(hash, false, "Move abort")
+		},
+		OnFailed: func(txn *api.UserTransaction) { failed = true },
+	})
+	assert.NoError(t, err)
+	assert.False(t, txn.Success)
+	assert.True(t, failed)
+	assert.Equal(t, hash, pending.Hash)
+	assert.Equal(t, uint64(2000), pending.ExpirationTimestampSecs)
+
+	// The node has no long-poll endpoint, so it isn't tried again
+	assert.True(t, node.client.longPollUnsupported.Load())
+}
+
+func TestNodeClient_PollForTransactionExpired(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	hash := submitWaitTestTxn(t, node, 0, 2000)
+
+	expired := ""
+	_, err := node.client.PollForTransaction(hash, PollPeriod(time.Millisecond), PollTimeout(5*time.Second), TransactionLifecycle{
+		OnPending: func(txn *api.PendingTransaction) { node.SetLedgerTimestamp(2000) },
+		OnExpired: func(hash string) { expired = hash },
+	})
+	assert.ErrorIs(t, err, ErrTransactionExpired)
+	assert.Equal(t, hash, expired)
+}
+
+func TestNodeClient_PollForTransactions(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	committedHash := submitWaitTestTxn(t, node, 0, 2000)
+	pendingHash := submitWaitTestTxn(t, node, 0, 2000)
+	node.Commit(committedHash, true, "Executed successfully")
+
+	results, err := node.client.PollForTransactions([]string{pendingHash, committedHash}, PollPeriod(time.Millisecond), PollTimeout(50*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, pendingHash, results[0].Hash)
+	assert.Error(t, results[0].Err)
+	assert.Nil(t, results[0].Transaction)
+	assert.Equal(t, committedHash, results[1].Hash)
+	assert.NoError(t, results[1].Err)
+	assert.True(t, results[1].Transaction.Success)
+
+	_, err = node.client.PollForTransactions([]string{committedHash}, "bad option")
+	assert.Error(t, err)
+
+	// The timeout is shared, even with more transactions than are polled at once
+	hashes := make([]string, 20)
+	for i := range hashes {
+		hashes[i] = submitWaitTestTxn(t, node, 0, 2000)
+	}
+	start := time.Now()
+	results, err = node.client.PollForTransactions(hashes, PollPeriod(time.Millisecond), PollTimeout(200*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 450*time.Millisecond)
+	for _, result := range results {
+		assert.Error(t, result.Err)
+	}
+}
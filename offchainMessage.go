@@ -0,0 +1,357 @@
+package endless
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+var offchainMessagePrehash []byte
+
+const offchainMessagePrehashStr = "ENDLESS::OffchainMessage"
+
+// OffchainMessagePrehash Return the sha3-256 prehash for OffchainMessage, which keeps signed messages from ever being
+// valid transactions.  Do not write to the []byte returned
+func OffchainMessagePrehash() []byte {
+	// Cache the prehash
+	if offchainMessagePrehash == nil {
+		b32 := sha3.Sum256([]byte(offchainMessagePrehashStr))
+		out := make([]byte, len(b32))
+		copy(out, b32[:])
+		offchainMessagePrehash = out
+		return out
+	}
+	return offchainMessagePrehash
+}
+
+// ErrOffchainMessageSignature is returned when a [SignedOffchainMessage] signature doesn't verify
+var ErrOffchainMessageSignature = errors.New("offchain message signature is invalid")
+
+// ErrOffchainMessageExpired is returned when a [SignedOffchainMessage] is used outside its issued-at and expiry times
+var ErrOffchainMessageExpired = errors.New("offchain message is expired or not yet valid")
+
+// ErrOffchainMessageChainId is returned when a [SignedOffchainMessage] is for a different chain than the network
+var ErrOffchainMessageChainId = errors.New("offchain message is for a different chain")
+
+// ErrOffchainMessageAuthKey is returned when a [SignedOffchainMessage] isn't signed by the account's on-chain keys
+var ErrOffchainMessageAuthKey = errors.New("offchain message signer is not an authentication key of the account")
+
+//region OffchainMessage
+
+// OffchainMessage is a structured message for signing off-chain, such as to sign in to an app with a wallet.
+//
+// The signing message is [OffchainMessagePrehash] followed by the BCS of the message, so a signature can't be replayed
+// as a transaction, on another chain, or after it expires.
+//
+// Implements:
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type OffchainMessage struct {
+	Address   AccountAddress // Address is the account signing the message
+	ChainId   uint8          // ChainId is the chain the account is on
+	Nonce     string         // Nonce is chosen by the verifier, or randomly, to prevent replays
+	IssuedAt  uint64         // IssuedAt is when the message was created, in unix seconds
+	ExpiresAt uint64         // ExpiresAt is when the message is no longer valid, in unix seconds, or 0 for never
+	Message   string         // Message is the human-readable statement being signed
+}
+
+// NewOffchainMessage creates an [OffchainMessage] issued now, valid for the duration or forever if 0, with a random
+// nonce.  Set Nonce afterward to use one chosen by the verifier.
+func NewOffchainMessage(address AccountAddress, chainId uint8, message string, validFor time.Duration) (*OffchainMessage, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := &OffchainMessage{
+		Address:  address,
+		ChainId:  chainId,
+		Nonce:    BytesToHex(nonce),
+		IssuedAt: uint64(now.Unix()),
+		Message:  message,
+	}
+	if validFor > 0 {
+		out.ExpiresAt = uint64(now.Add(validFor).Unix())
+	}
+	return out, nil
+}
+
+// SigningMessage is the bytes to sign, [OffchainMessagePrehash] followed by the BCS of the message
+func (msg *OffchainMessage) SigningMessage() ([]byte, error) {
+	msgBytes, err := bcs.Serialize(msg)
+	if err != nil {
+		return nil, err
+	}
+	prehash := OffchainMessagePrehash()
+	out := make([]byte, len(prehash)+len(msgBytes))
+	copy(out, prehash)
+	copy(out[len(prehash):], msgBytes)
+	return out, nil
+}
+
+// Sign signs the message with any [crypto.Signer], including an [Account]
+func (msg *OffchainMessage) Sign(signer crypto.Signer) (*SignedOffchainMessage, error) {
+	signingMessage, err := msg.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := signer.Sign(signingMessage)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedOffchainMessage{Message: *msg, Authenticator: auth}, nil
+}
+
+// SignMultiKey signs the message for a [crypto.MultiKey] account, with one [crypto.SingleSigner] for each key signing.
+// Other keys in the [crypto.MultiKey] aren't needed, but there must be at least SignaturesRequired signers.
+func (msg *OffchainMessage) SignMultiKey(pubKey *crypto.MultiKey, signers ...*crypto.SingleSigner) (*SignedOffchainMessage, error) {
+	signingMessage, err := msg.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]crypto.IndexedAnySignature, 0, len(signers))
+	for _, signer := range signers {
+		index := -1
+		signerKey := signer.PubKey().Bytes()
+		for i, key := range pubKey.PubKeys {
+			if string(key.Bytes()) == string(signerKey) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("signer %s is not in the multi key", signer.PubKey().ToHex())
+		}
+		signature, err := signer.SignMessage(signingMessage)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, crypto.IndexedAnySignature{Index: uint8(index), Signature: signature.(*crypto.AnySignature)})
+	}
+	if len(signatures) < int(pubKey.SignaturesRequired) {
+		return nil, fmt.Errorf("not enough signers, %d of %d required", len(signatures), pubKey.SignaturesRequired)
+	}
+	signature, err := crypto.NewMultiKeySignature(signatures)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedOffchainMessage{
+		Message:       *msg,
+		Authenticator: &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiKey, Auth: &crypto.MultiKeyAuthenticator{PubKey: pubKey, Sig: signature}},
+	}, nil
+}
+
+// SignMultiEd25519 signs the message for a [crypto.MultiEd25519PublicKey] account, with one private key for each key
+// signing.  Other keys aren't needed, but there must be at least SignaturesRequired signers.
+func (msg *OffchainMessage) SignMultiEd25519(pubKey *crypto.MultiEd25519PublicKey, signers ...*crypto.Ed25519PrivateKey) (*SignedOffchainMessage, error) {
+	signingMessage, err := msg.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	// Signatures are in key order, with the bitmap marking each key from the most significant bit
+	signatures := make([]*crypto.Ed25519Signature, len(pubKey.PubKeys))
+	for _, signer := range signers {
+		index := -1
+		for i, key := range pubKey.PubKeys {
+			if string(key.Bytes()) == string(signer.PubKey().Bytes()) {
+				index = i
+				break
+			}
+		}
+		if index < 0 || index >= crypto.MultiEd25519BitmapLen*8 {
+			return nil, fmt.Errorf("signer %s is not in the multi ed25519 key", signer.PubKey().ToHex())
+		}
+		signature, err := signer.SignMessage(signingMessage)
+		if err != nil {
+			return nil, err
+		}
+		signatures[index] = signature.(*crypto.Ed25519Signature)
+	}
+	multiSignature := &crypto.MultiEd25519Signature{Signatures: make([]*crypto.Ed25519Signature, 0, len(signers))}
+	for i, signature := range signatures {
+		if signature != nil {
+			multiSignature.Signatures = append(multiSignature.Signatures, signature)
+			multiSignature.Bitmap[i/8] |= 128 >> (i % 8)
+		}
+	}
+	if len(multiSignature.Signatures) < int(pubKey.SignaturesRequired) {
+		return nil, fmt.Errorf("not enough signers, %d of %d required", len(multiSignature.Signatures), pubKey.SignaturesRequired)
+	}
+	return &SignedOffchainMessage{
+		Message:       *msg,
+		Authenticator: &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiEd25519, Auth: &crypto.MultiEd25519Authenticator{PubKey: pubKey, Sig: multiSignature}},
+	}, nil
+}
+
+//region OffchainMessage bcs.Struct
+
+// MarshalBCS serializes the message to bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (msg *OffchainMessage) MarshalBCS(ser *bcs.Serializer) {
+	ser.Struct(&msg.Address)
+	ser.U8(msg.ChainId)
+	ser.WriteString(msg.Nonce)
+	ser.U64(msg.IssuedAt)
+	ser.U64(msg.ExpiresAt)
+	ser.WriteString(msg.Message)
+}
+
+// UnmarshalBCS deserializes the message from bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (msg *OffchainMessage) UnmarshalBCS(des *bcs.Deserializer) {
+	des.Struct(&msg.Address)
+	msg.ChainId = des.U8()
+	msg.Nonce = des.ReadString()
+	msg.IssuedAt = des.U64()
+	msg.ExpiresAt = des.U64()
+	msg.Message = des.ReadString()
+}
+
+//endregion
+//endregion
+
+//region SignedOffchainMessage
+
+// SignedOffchainMessage is an [OffchainMessage] with the [crypto.AccountAuthenticator] of its signer.  It can be sent
+// as hex with [SignedOffchainMessage.ToHex] and [ParseSignedOffchainMessage].
+//
+// Implements:
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type SignedOffchainMessage struct {
+	Message       OffchainMessage
+	Authenticator *crypto.AccountAuthenticator
+}
+
+// ParseSignedOffchainMessage parses the hex BCS of a [SignedOffchainMessage]
+func ParseSignedOffchainMessage(hexStr string) (*SignedOffchainMessage, error) {
+	bytes, err := ParseHex(hexStr)
+	if err != nil {
+		return nil, err
+	}
+	out := &SignedOffchainMessage{}
+	if err = bcs.Deserialize(out, bytes); err != nil {
+		return nil, fmt.Errorf("invalid signed offchain message: %w", err)
+	}
+	return out, nil
+}
+
+// ToHex is the hex BCS of the signed message
+func (signed *SignedOffchainMessage) ToHex() (string, error) {
+	bytes, err := bcs.Serialize(signed)
+	if err != nil {
+		return "", err
+	}
+	return BytesToHex(bytes), nil
+}
+
+// Verify checks the signature, and that the message is valid at the current time.
+//
+// This doesn't check the signer owns Address, as an account's keys can change.  Use [Client.VerifyOffchainMessage] to
+// check the signing keys against the account on-chain.
+func (signed *SignedOffchainMessage) Verify() error {
+	if signed.Authenticator == nil || signed.Authenticator.Auth == nil {
+		return ErrOffchainMessageSignature
+	}
+	signingMessage, err := signed.Message.SigningMessage()
+	if err != nil {
+		return err
+	}
+	if !signed.Authenticator.Verify(signingMessage) {
+		return ErrOffchainMessageSignature
+	}
+	now := uint64(time.Now().Unix())
+	if signed.Message.IssuedAt > now+uint64(offchainMessageClockSkew.Seconds()) {
+		return fmt.Errorf("%w: issued at %d is in the future", ErrOffchainMessageExpired, signed.Message.IssuedAt)
+	}
+	if signed.Message.ExpiresAt != 0 && signed.Message.ExpiresAt <= now {
+		return fmt.Errorf("%w: expired at %d", ErrOffchainMessageExpired, signed.Message.ExpiresAt)
+	}
+	return nil
+}
+
+// offchainMessageClockSkew is how far in the future IssuedAt may be, for signers with fast clocks
+const offchainMessageClockSkew = time.Minute
+
+//region SignedOffchainMessage bcs.Struct
+
+// MarshalBCS serializes the signed message to bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (signed *SignedOffchainMessage) MarshalBCS(ser *bcs.Serializer) {
+	if signed.Authenticator == nil {
+		ser.SetError(errors.New("signed offchain message has no authenticator"))
+		return
+	}
+	ser.Struct(&signed.Message)
+	ser.Struct(signed.Authenticator)
+}
+
+// UnmarshalBCS deserializes the signed message from bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (signed *SignedOffchainMessage) UnmarshalBCS(des *bcs.Deserializer) {
+	des.Struct(&signed.Message)
+	signed.Authenticator = &crypto.AccountAuthenticator{}
+	des.Struct(signed.Authenticator)
+}
+
+//endregion
+//endregion
+
+// VerifyOffchainMessage verifies a [SignedOffchainMessage] with [SignedOffchainMessage.Verify], checks it's for this
+// network's chain id, and checks the signing keys are authentication keys of the account on-chain.  A
+// [crypto.MultiAuthKeyAuthenticator] must also have at least the account's NumSignaturesRequired keys.
+func (rc *NodeClient) VerifyOffchainMessage(signed *SignedOffchainMessage) error {
+	if err := signed.Verify(); err != nil {
+		return err
+	}
+	chainId, err := rc.GetChainId()
+	if err != nil {
+		return err
+	}
+	if signed.Message.ChainId != chainId {
+		return fmt.Errorf("%w: message chain id %d, network chain id %d", ErrOffchainMessageChainId, signed.Message.ChainId, chainId)
+	}
+
+	info, err := rc.Account(signed.Message.Address)
+	if err != nil {
+		return err
+	}
+	onChainKeys, err := info.AuthenticationKey()
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, candidates := range authenticatorAuthKeys(signed.Authenticator) {
+		found := false
+		for _, authKey := range candidates {
+			for _, onChainKey := range onChainKeys {
+				if string(onChainKey) == string(authKey[:]) {
+					seen[string(onChainKey)] = true
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrOffchainMessageAuthKey, candidates[0].ToHex())
+		}
+	}
+	if len(seen) < info.NumSignaturesRequired {
+		return fmt.Errorf("%w: %d of %d required keys signed", ErrOffchainMessageAuthKey, len(seen), info.NumSignaturesRequired)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package endless
+
+import (
+	"testing"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffchainMessage_SignAndVerify(t *testing.T) {
+	node := newTestNode(t)
+	ed25519Account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	singleSenderAccount, err := NewEd25519SingleSenderAccount()
+	assert.NoError(t, err)
+	secp256k1Account, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+
+	for _, account := range []*Account{ed25519Account, singleSenderAccount, secp256k1Account} {
+		node.SetAccount(account.Address, 0, account.AuthKey().ToHex())
+		msg, err := NewOffchainMessage(account.Address, testNodeChainId, "Sign in to example.com", time.Minute)
+		assert.NoError(t, err)
+		signed, err := msg.Sign(account)
+		assert.NoError(t, err)
+
+		// Round trip through hex
+		signedHex, err := signed.ToHex()
+		assert.NoError(t, err)
+		parsed, err := ParseSignedOffchainMessage(signedHex)
+		assert.NoError(t, err)
+		assert.Equal(t, signed.Message, parsed.Message)
+		assert.NoError(t, parsed.Verify())
+		assert.NoError(t, node.client.VerifyOffchainMessage(parsed))
+
+		// Changing any field breaks the signature
+		parsed.Message.Nonce = "replayed"
+		assert.ErrorIs(t, parsed.Verify(), ErrOffchainMessageSignature)
+	}
+}
+
+func TestOffchainMessage_NotATransaction(t *testing.T) {
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	msg, err := NewOffchainMessage(account.Address, testNodeChainId, "hello", 0)
+	assert.NoError(t, err)
+	signingMessage, err := msg.SigningMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, OffchainMessagePrehash(), signingMessage[:32])
+	assert.NotEqual(t, RawTransactionPrehash(), signingMessage[:32])
+	assert.NotEqual(t, RawTransactionWithDataPrehash(), signingMessage[:32])
+}
+
+func TestOffchainMessage_Rejected(t *testing.T) {
+	node := newTestNode(t)
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	other, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(account.Address, 0, other.AuthKey().ToHex())
+
+	// Expired
+	msg, err := NewOffchainMessage(account.Address, testNodeChainId, "hello", time.Minute)
+	assert.NoError(t, err)
+	msg.IssuedAt -= 120
+	msg.ExpiresAt -= 120
+	signed, err := msg.Sign(account)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, signed.Verify(), ErrOffchainMessageExpired)
+
+	// Other chain
+	msg, err = NewOffchainMessage(account.Address, testNodeChainId+1, "hello", time.Minute)
+	assert.NoError(t, err)
+	signed, err = msg.Sign(account)
+	assert.NoError(t, err)
+	assert.NoError(t, signed.Verify())
+	assert.ErrorIs(t, node.client.VerifyOffchainMessage(signed), ErrOffchainMessageChainId)
+
+	// The account's key was rotated away from the signer
+	msg.ChainId = testNodeChainId
+	signed, err = msg.Sign(account)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, node.client.VerifyOffchainMessage(signed), ErrOffchainMessageAuthKey)
+
+	// Signed by someone else for the account
+	node.SetAccount(account.Address, 0, account.AuthKey().ToHex())
+	signed, err = msg.Sign(other)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, node.client.VerifyOffchainMessage(signed), ErrOffchainMessageAuthKey)
+}
+
+func TestOffchainMessage_MultiKey(t *testing.T) {
+	node := newTestNode(t)
+	signers := make([]*crypto.SingleSigner, 3)
+	pubKeys := make([]*crypto.AnyPublicKey, 3)
+	for i := range signers {
+		var privateKey crypto.MessageSigner
+		var err error
+		if i == 2 {
+			privateKey, err = crypto.GenerateSecp256k1Key()
+		} else {
+			privateKey, err = crypto.GenerateEd25519PrivateKey()
+		}
+		assert.NoError(t, err)
+		signers[i] = crypto.NewSingleSigner(privateKey)
+		pubKeys[i] = signers[i].PubKey().(*crypto.AnyPublicKey)
+	}
+	pubKey := &crypto.MultiKey{PubKeys: pubKeys, SignaturesRequired: 2}
+	address := AccountAddress(*pubKey.AuthKey())
+	node.SetAccount(address, 0, pubKey.AuthKey().ToHex())
+
+	msg, err := NewOffchainMessage(address, testNodeChainId, "hello", time.Minute)
+	assert.NoError(t, err)
+	signed, err := msg.SignMultiKey(pubKey, signers[2], signers[0])
+	assert.NoError(t, err)
+	assert.NoError(t, node.client.VerifyOffchainMessage(signed))
+
+	_, err = msg.SignMultiKey(pubKey, signers[1])
+	assert.Error(t, err)
+}
+
+func TestOffchainMessage_MultiEd25519(t *testing.T) {
+	node := newTestNode(t)
+	privateKeys := make([]*crypto.Ed25519PrivateKey, 3)
+	pubKeys := make([]*crypto.Ed25519PublicKey, 3)
+	for i := range privateKeys {
+		privateKey, err := crypto.GenerateEd25519PrivateKey()
+		assert.NoError(t, err)
+		privateKeys[i] = privateKey
+		pubKeys[i] = privateKey.PubKey().(*crypto.Ed25519PublicKey)
+	}
+	pubKey := &crypto.MultiEd25519PublicKey{PubKeys: pubKeys, SignaturesRequired: 2}
+	address := AccountAddress(*pubKey.AuthKey())
+	node.SetAccount(address, 0, pubKey.AuthKey().ToHex())
+
+	msg, err := NewOffchainMessage(address, testNodeChainId, "hello", time.Minute)
+	assert.NoError(t, err)
+	signed, err := msg.SignMultiEd25519(pubKey, privateKeys[2], privateKeys[0])
+	assert.NoError(t, err)
+	assert.NoError(t, node.client.VerifyOffchainMessage(signed))
+
+	signedHex, err := signed.ToHex()
+	assert.NoError(t, err)
+	parsed, err := ParseSignedOffchainMessage(signedHex)
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Verify())
+}
+
+func TestOffchainMessage_MultiAuthKey(t *testing.T) {
+	node := newTestNode(t)
+	owner1, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	node.SetAccount(owner1.Address, 0, owner1.AuthKey().ToHex(), owner2.AuthKey().ToHex())
+	node.accounts[owner1.Address].NumSignaturesRequired = 2
+
+	msg, err := NewOffchainMessage(owner1.Address, testNodeChainId, "hello", time.Minute)
+	assert.NoError(t, err)
+	signingMessage, err := msg.SigningMessage()
+	assert.NoError(t, err)
+	auth1, err := owner1.Sign(signingMessage)
+	assert.NoError(t, err)
+	auth2, err := owner2.Sign(signingMessage)
+	assert.NoError(t, err)
+	multiAuthKey := &crypto.MultiAuthKeyAuthenticator{}
+	assert.NoError(t, multiAuthKey.FromAuthenticators([]*crypto.AccountAuthenticator{auth1, auth2}))
+	signed := &SignedOffchainMessage{Message: *msg, Authenticator: &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiAuthKey, Auth: multiAuthKey}}
+	assert.NoError(t, node.client.VerifyOffchainMessage(signed))
+
+	// One key isn't enough
+	signed, err = msg.Sign(owner1)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, node.client.VerifyOffchainMessage(signed), ErrOffchainMessageAuthKey)
+}
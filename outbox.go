@@ -0,0 +1,496 @@
+package endless
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+)
+
+// OutboxStatus is the status of a transaction in an [Outbox]
+type OutboxStatus uint8
+
+const (
+	// OutboxPending is a signed transaction that is not known to be accepted by the node yet
+	OutboxPending OutboxStatus = iota
+	// OutboxSubmitted is a transaction that was accepted by the node, and is waiting to commit
+	OutboxSubmitted
+	// OutboxCommitted is a transaction that committed successfully
+	OutboxCommitted
+	// OutboxFailed is a transaction that committed with a failure, or was rejected by the node for good
+	OutboxFailed
+	// OutboxExpired is a transaction that passed its expiration without committing, and was dropped
+	OutboxExpired
+)
+
+// String returns a readable name of the OutboxStatus
+func (status OutboxStatus) String() string {
+	switch status {
+	case OutboxPending:
+		return "pending"
+	case OutboxSubmitted:
+		return "submitted"
+	case OutboxCommitted:
+		return "committed"
+	case OutboxFailed:
+		return "failed"
+	case OutboxExpired:
+		return "expired"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(status))
+	}
+}
+
+// Final tells if the transaction will not change status anymore
+func (status OutboxStatus) Final() bool {
+	return status == OutboxCommitted || status == OutboxFailed || status == OutboxExpired
+}
+
+// OutboxEntry is a snapshot of a transaction in an [Outbox]
+type OutboxEntry struct {
+	Hash                       string               // Hash of the signed transaction
+	Status                     OutboxStatus         // Status of the transaction
+	ExpirationTimestampSeconds uint64               // ExpirationTimestampSeconds of the transaction
+	SignedTxn                  *SignedTransaction   // SignedTxn is the transaction to submit
+	Error                      string               // Error is why the transaction failed, if it did
+	Result                     *api.UserTransaction // Result of the committed transaction, only known after processing
+}
+
+// outboxRecord is a single line of the write-ahead log
+type outboxRecord struct {
+	Hash       string       `json:"hash"`
+	Status     OutboxStatus `json:"status"`
+	Expiration uint64       `json:"expiration,omitempty"`
+	Txn        string       `json:"txn,omitempty"` // Txn is the hex BCS of the SignedTransaction, only on the first record
+	Error      string       `json:"error,omitempty"`
+}
+
+// Outbox is a durable queue of signed transactions, so transactions are neither lost nor sent twice across crashes.
+//
+// Every signed transaction is written to a file-backed write-ahead log before it is submitted, and every status
+// change is appended after.  The log is replayed on [OpenOutbox].  Before submitting a transaction which was not
+// confirmed before a restart, the node is asked for its hash, and it is only submitted if the node doesn't know it.
+// As the same signed bytes are submitted, a transaction can never execute twice.
+//
+// Transactions which pass their expiration without committing are dropped.  Finished transactions are removed from
+// the log the next time it is opened.
+type Outbox struct {
+	client *NodeClient
+	path   string
+
+	mutex   sync.Mutex
+	file    *os.File
+	entries map[string]*OutboxEntry
+	order   []string
+}
+
+// OpenOutbox opens or creates the outbox log at path, replaying it to restore unfinished transactions
+func OpenOutbox(client *NodeClient, path string) (*Outbox, error) {
+	outbox := &Outbox{
+		client:  client,
+		path:    path,
+		entries: make(map[string]*OutboxEntry),
+	}
+	err := outbox.replay()
+	if err != nil {
+		return nil, err
+	}
+	err = outbox.compact()
+	if err != nil {
+		return nil, err
+	}
+	return outbox, nil
+}
+
+// Close closes the outbox log
+func (outbox *Outbox) Close() error {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	if outbox.file == nil {
+		return nil
+	}
+	err := outbox.file.Close()
+	outbox.file = nil
+	return err
+}
+
+// Add durably records a signed transaction for submission.  Adding the same transaction again does nothing.
+func (outbox *Outbox) Add(signedTxn *SignedTransaction) (string, error) {
+	hash, err := signedTxn.Hash()
+	if err != nil {
+		return "", err
+	}
+	txnBytes, err := bcs.Serialize(signedTxn)
+	if err != nil {
+		return "", err
+	}
+
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	if _, ok := outbox.entries[hash]; ok {
+		return hash, nil
+	}
+	entry := &OutboxEntry{
+		Hash:                       hash,
+		Status:                     OutboxPending,
+		ExpirationTimestampSeconds: signedTxn.Transaction.ExpirationTimestampSeconds,
+		SignedTxn:                  signedTxn,
+	}
+	err = outbox.appendLocked(outboxRecord{
+		Hash:       hash,
+		Status:     OutboxPending,
+		Expiration: entry.ExpirationTimestampSeconds,
+		Txn:        hex.EncodeToString(txnBytes),
+	})
+	if err != nil {
+		return "", err
+	}
+	outbox.entries[hash] = entry
+	outbox.order = append(outbox.order, hash)
+	return hash, nil
+}
+
+// Entries returns a snapshot of all transactions in the outbox, in order of addition
+func (outbox *Outbox) Entries() []OutboxEntry {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	out := make([]OutboxEntry, 0, len(outbox.order))
+	for _, hash := range outbox.order {
+		out = append(out, *outbox.entries[hash])
+	}
+	return out
+}
+
+// Get returns a snapshot of a transaction in the outbox
+func (outbox *Outbox) Get(hash string) (OutboxEntry, bool) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	entry, ok := outbox.entries[hash]
+	if !ok {
+		return OutboxEntry{}, false
+	}
+	return *entry, true
+}
+
+// Process makes a single pass over unfinished transactions, several at a time.  Pending transactions are looked up, and
+// submitted if the node doesn't know them.  Those rejected with SEQUENCE_NUMBER_TOO_NEW stay pending, and are
+// submitted again on the next pass.  Submitted transactions are polled with [NodeClient.PollForTransaction] until they
+// commit or the poll times out.  Transactions past expiration are dropped.  Returns the entries whose status changed,
+// in the order they were added.
+//
+// Accepts options for [NodeClient.PollForTransaction]:
+//   - [PollPeriod]
+//   - [PollTimeout]
+func (outbox *Outbox) Process(options ...any) ([]OutboxEntry, error) {
+	outbox.mutex.Lock()
+	unfinished := make([]OutboxEntry, 0)
+	for _, hash := range outbox.order {
+		if entry := outbox.entries[hash]; !entry.Status.Final() {
+			unfinished = append(unfinished, *entry)
+		}
+	}
+	outbox.mutex.Unlock()
+
+	// Process concurrently, so a slow transaction doesn't hold up the rest, but limit the requests to the node
+	const maxConcurrent = 8
+	semaphore := make(chan struct{}, maxConcurrent)
+	updated := make([]*OutboxEntry, len(unfinished))
+	errs := make([]error, len(unfinished))
+	var wg sync.WaitGroup
+	for i, entry := range unfinished {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			var err error
+			updated[i], err = outbox.process(entry, options...)
+			if err != nil {
+				errs[i] = fmt.Errorf("outbox transaction %s: %w", entry.Hash, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	updates := make([]OutboxEntry, 0)
+	for _, entry := range updated {
+		if entry != nil {
+			updates = append(updates, *entry)
+		}
+	}
+	return updates, errors.Join(errs...)
+}
+
+// Run processes the outbox every period until stop is closed, calling onUpdate for every entry whose status changed.
+// Errors from processing are passed to onError, if not nil.
+func (outbox *Outbox) Run(period time.Duration, stop <-chan struct{}, onUpdate func(OutboxEntry), onError func(error), options ...any) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		updates, err := outbox.Process(options...)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		if onUpdate != nil {
+			for _, update := range updates {
+				onUpdate(update)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process moves a single transaction forward, returning the updated entry if the status changed
+func (outbox *Outbox) process(entry OutboxEntry, options ...any) (*OutboxEntry, error) {
+	wasPending := entry.Status == OutboxPending
+	if wasPending {
+		// Never submit before checking, the transaction may have been submitted before a crash
+		known, updated, err := outbox.lookup(entry)
+		if err != nil || updated != nil {
+			return updated, err
+		}
+		if !known {
+			if expired, err := outbox.expired(entry); err != nil || expired {
+				return outbox.expireIfUnknown(entry, err)
+			}
+			_, err = outbox.client.SubmitTransaction(entry.SignedTxn)
+			if err != nil {
+				apiError, ok := ApiErrorFromError(err)
+				if !ok || ClassifySubmissionError(apiError) == SubmissionErrorTransient {
+					return nil, err
+				}
+				if ClassifySubmissionError(apiError) == SubmissionErrorSequenceNumber {
+					// It may have committed in the meantime
+					if _, updated, lookupErr := outbox.lookup(entry); lookupErr != nil || updated != nil {
+						return updated, lookupErr
+					}
+					// Too new is valid once the earlier sequence numbers commit, too old was taken by another
+					if IsSequenceNumberTooNew(err) {
+						return nil, err
+					}
+				}
+				return outbox.update(entry.Hash, OutboxFailed, apiError.Message, nil)
+			}
+		}
+		updated, err = outbox.update(entry.Hash, OutboxSubmitted, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		entry = *updated
+	}
+
+	userTxn, err := outbox.client.PollForTransaction(entry.Hash, options...)
+	if err != nil {
+		// Not committed yet, drop it if it can no longer commit
+		if expired, expiredErr := outbox.expired(entry); expiredErr == nil && expired {
+			return outbox.expireIfUnknown(entry, nil)
+		}
+		if wasPending {
+			return outbox.changed(entry.Hash), nil
+		}
+		return nil, nil
+	}
+	return outbox.committed(entry.Hash, userTxn)
+}
+
+// lookup asks the node for the transaction.  Returns whether the node knows it, and the updated entry if it committed.
+func (outbox *Outbox) lookup(entry OutboxEntry) (bool, *OutboxEntry, error) {
+	txn, err := outbox.client.TransactionByHash(entry.Hash)
+	if err != nil {
+		var httpError *HttpError
+		if errors.As(err, &httpError) && httpError.StatusCode == http.StatusNotFound {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if txn.Type != api.TransactionVariantUser {
+		return true, nil, nil
+	}
+	userTxn, err := txn.UserTransaction()
+	if err != nil {
+		return true, nil, err
+	}
+	updated, err := outbox.committed(entry.Hash, userTxn)
+	return true, updated, err
+}
+
+// expired tells if the ledger has passed the expiration of the transaction
+func (outbox *Outbox) expired(entry OutboxEntry) (bool, error) {
+	ledgerSeconds, err := outbox.client.ledgerTimestampSeconds()
+	if err != nil {
+		return false, err
+	}
+	return ledgerSeconds >= entry.ExpirationTimestampSeconds, nil
+}
+
+// expireIfUnknown drops an expired transaction, after a final check that it didn't commit
+func (outbox *Outbox) expireIfUnknown(entry OutboxEntry, err error) (*OutboxEntry, error) {
+	if err != nil {
+		return nil, err
+	}
+	_, updated, err := outbox.lookup(entry)
+	if err != nil || updated != nil {
+		return updated, err
+	}
+	return outbox.update(entry.Hash, OutboxExpired, "expired without committing", nil)
+}
+
+func (outbox *Outbox) committed(hash string, userTxn *api.UserTransaction) (*OutboxEntry, error) {
+	if userTxn.Success {
+		return outbox.update(hash, OutboxCommitted, "", userTxn)
+	}
+	return outbox.update(hash, OutboxFailed, userTxn.VmStatus, userTxn)
+}
+
+// changed returns a snapshot of the entry
+func (outbox *Outbox) changed(hash string) *OutboxEntry {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	entry := *outbox.entries[hash]
+	return &entry
+}
+
+// update durably records a new status for a transaction
+func (outbox *Outbox) update(hash string, status OutboxStatus, message string, result *api.UserTransaction) (*OutboxEntry, error) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	entry, ok := outbox.entries[hash]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s is not in the outbox", hash)
+	}
+	err := outbox.appendLocked(outboxRecord{Hash: hash, Status: status, Error: message})
+	if err != nil {
+		return nil, err
+	}
+	entry.Status = status
+	entry.Error = message
+	entry.Result = result
+	updated := *entry
+	return &updated, nil
+}
+
+// appendLocked appends a record to the log, and syncs it to disk
+func (outbox *Outbox) appendLocked(record outboxRecord) error {
+	if outbox.file == nil {
+		return errors.New("outbox is closed")
+	}
+	blob, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = outbox.file.Write(append(blob, '\n'))
+	if err != nil {
+		return err
+	}
+	return outbox.file.Sync()
+}
+
+// replay rebuilds the entries from the log
+func (outbox *Outbox) replay() error {
+	file, err := os.Open(outbox.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		record := outboxRecord{}
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			// A crash during a write can leave a partial last line, it was never acknowledged
+			continue
+		}
+		entry, ok := outbox.entries[record.Hash]
+		if record.Txn != "" {
+			if ok {
+				continue
+			}
+			txnBytes, err := hex.DecodeString(record.Txn)
+			if err != nil {
+				return fmt.Errorf("bad outbox transaction %s: %w", record.Hash, err)
+			}
+			signedTxn := &SignedTransaction{}
+			err = bcs.Deserialize(signedTxn, txnBytes)
+			if err != nil {
+				return fmt.Errorf("bad outbox transaction %s: %w", record.Hash, err)
+			}
+			outbox.entries[record.Hash] = &OutboxEntry{
+				Hash:                       record.Hash,
+				Status:                     record.Status,
+				ExpirationTimestampSeconds: record.Expiration,
+				SignedTxn:                  signedTxn,
+			}
+			outbox.order = append(outbox.order, record.Hash)
+			continue
+		}
+		if ok {
+			entry.Status = record.Status
+			entry.Error = record.Error
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	// Whether unfinished transactions reached the node before the restart is unknown, so check them all again
+	for _, entry := range outbox.entries {
+		if !entry.Status.Final() {
+			entry.Status = OutboxPending
+		}
+	}
+	return nil
+}
+
+// compact rewrites the log with only the unfinished entries, and opens it for appending
+func (outbox *Outbox) compact() error {
+	tmpPath := outbox.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	outbox.file = file
+
+	order := make([]string, 0, len(outbox.order))
+	for _, hash := range outbox.order {
+		entry := outbox.entries[hash]
+		if entry.Status.Final() {
+			delete(outbox.entries, hash)
+			continue
+		}
+		txnBytes, err := bcs.Serialize(entry.SignedTxn)
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+		err = outbox.appendLocked(outboxRecord{
+			Hash:       hash,
+			Status:     entry.Status,
+			Expiration: entry.ExpirationTimestampSeconds,
+			Txn:        hex.EncodeToString(txnBytes),
+		})
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+		order = append(order, hash)
+	}
+	outbox.order = slices.Clip(order)
+	return os.Rename(tmpPath, outbox.path)
+}
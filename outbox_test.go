@@ -0,0 +1,187 @@
+package endless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func outboxTestTxn(t *testing.T, node *testNode, sender *Account, seq uint64, expiration uint64) *SignedTransaction {
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, seq+1)
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransaction(sender.Address, TransactionPayload{Payload: entryFunction}, SequenceNumber(seq), GasUnitPrice(100), ExpirationSeconds(expiration))
+	assert.NoError(t, err)
+	signedTxn, err := rawTxn.SignedTransaction(sender)
+	assert.NoError(t, err)
+	return signedTxn
+}
+
+func TestOutbox_SubmitAndCommit(t *testing.T) {
+	node := newTestNode(t)
+	node.AutoCommit = true
+	node.SetLedgerTimestamp(1000)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	outbox, err := OpenOutbox(node.client, path)
+	assert.NoError(t, err)
+	defer outbox.Close()
+
+	signedTxn := outboxTestTxn(t, node, sender, 0, 2000)
+	hash, err := outbox.Add(signedTxn)
+	assert.NoError(t, err)
+	// Adding again doesn't duplicate
+	hash2, err := outbox.Add(signedTxn)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, hash2)
+	assert.Len(t, outbox.Entries(), 1)
+
+	updates, err := outbox.Process(PollPeriod(time.Millisecond), PollTimeout(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, OutboxCommitted, updates[0].Status)
+	assert.True(t, updates[0].Result.Success)
+	assert.Len(t, node.Submitted(), 1)
+
+	// Finished entries are not processed again
+	updates, err = outbox.Process(PollPeriod(time.Millisecond), PollTimeout(time.Second))
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestOutbox_RestartDoesNotResubmit(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	outbox, err := OpenOutbox(node.client, path)
+	assert.NoError(t, err)
+	submittedTxn := outboxTestTxn(t, node, sender, 0, 2000)
+	unsentTxn := outboxTestTxn(t, node, sender, 1, 2000)
+	submittedHash, err := outbox.Add(submittedTxn)
+	assert.NoError(t, err)
+	unsentHash, err := outbox.Add(unsentTxn)
+	assert.NoError(t, err)
+
+	// Crash after the first was submitted, but before recording it
+	_, err = node.client.SubmitTransaction(submittedTxn)
+	assert.NoError(t, err)
+	node.Commit(submittedHash, true, "Executed successfully")
+	assert.NoError(t, outbox.Close())
+	// A partial write at the end of the log is ignored
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"hash":"trunc`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	outbox, err = OpenOutbox(node.client, path)
+	assert.NoError(t, err)
+	defer outbox.Close()
+	assert.Len(t, outbox.Entries(), 2)
+
+	updates, err := outbox.Process(PollPeriod(time.Millisecond), PollTimeout(10*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, updates, 2)
+
+	// The committed one wasn't sent again, the other was submitted
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 2)
+	entry, ok := outbox.Get(submittedHash)
+	assert.True(t, ok)
+	assert.Equal(t, OutboxCommitted, entry.Status)
+	entry, ok = outbox.Get(unsentHash)
+	assert.True(t, ok)
+	assert.Equal(t, OutboxSubmitted, entry.Status)
+
+	// Finished entries are compacted away on reopen
+	assert.NoError(t, outbox.Close())
+	outbox, err = OpenOutbox(node.client, path)
+	assert.NoError(t, err)
+	entries := outbox.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, unsentHash, entries[0].Hash)
+	assert.Equal(t, OutboxPending, entries[0].Status)
+}
+
+func TestOutbox_DropsExpired(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	outbox, err := OpenOutbox(node.client, filepath.Join(t.TempDir(), "outbox.log"))
+	assert.NoError(t, err)
+	defer outbox.Close()
+
+	// Already expired is never submitted
+	expiredHash, err := outbox.Add(outboxTestTxn(t, node, sender, 0, 900))
+	assert.NoError(t, err)
+	// Submitted, but it expires before committing
+	stuckHash, err := outbox.Add(outboxTestTxn(t, node, sender, 1, 1010))
+	assert.NoError(t, err)
+
+	updates, err := outbox.Process(PollPeriod(time.Millisecond), PollTimeout(5*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, updates, 2)
+	entry, _ := outbox.Get(expiredHash)
+	assert.Equal(t, OutboxExpired, entry.Status)
+	entry, _ = outbox.Get(stuckHash)
+	assert.Equal(t, OutboxSubmitted, entry.Status)
+	assert.Len(t, node.Submitted(), 1)
+
+	node.SetLedgerTimestamp(1010)
+	updates, err = outbox.Process(PollPeriod(time.Millisecond), PollTimeout(5*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, stuckHash, updates[0].Hash)
+	assert.Equal(t, OutboxExpired, updates[0].Status)
+}
+
+func TestOutbox_SequenceNumberErrors(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLedgerTimestamp(1000)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 3)
+
+	outbox, err := OpenOutbox(node.client, filepath.Join(t.TempDir(), "outbox.log"))
+	assert.NoError(t, err)
+	defer outbox.Close()
+	oldHash, err := outbox.Add(outboxTestTxn(t, node, sender, 1, 2000))
+	assert.NoError(t, err)
+	newHash, err := outbox.Add(outboxTestTxn(t, node, sender, 5, 2000))
+	assert.NoError(t, err)
+	node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		if txn.Transaction.SequenceNumber < 3 {
+			return sequenceNumberError(VmStatusSequenceNumberTooOld, "SEQUENCE_NUMBER_TOO_OLD")(txn)
+		}
+		return sequenceNumberError(VmStatusSequenceNumberTooNew, "SEQUENCE_NUMBER_TOO_NEW")(txn)
+	}
+
+	// Too old fails for good, too new stays pending
+	updates, err := outbox.Process(PollPeriod(time.Millisecond), PollTimeout(5*time.Millisecond))
+	assert.Error(t, err)
+	assert.Len(t, updates, 1)
+	entry, _ := outbox.Get(oldHash)
+	assert.Equal(t, OutboxFailed, entry.Status)
+	entry, _ = outbox.Get(newHash)
+	assert.Equal(t, OutboxPending, entry.Status)
+
+	// Once the gap is filled it goes through
+	node.SubmitHook = nil
+	updates, err = outbox.Process(PollPeriod(time.Millisecond), PollTimeout(5*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, newHash, updates[0].Hash)
+	assert.Equal(t, OutboxSubmitted, updates[0].Status)
+}
@@ -0,0 +1,429 @@
+package endless
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+/*
+The remote signer protocol is JSON over HTTP, with every request authenticated by an HMAC-SHA256 of a shared secret:
+
+	GET  {url}/keys/{key_id}       -> RemoteSignerKey
+	POST {url}/keys/{key_id}/sign  RemoteSignRequest -> RemoteSignResponse
+
+	X-Endless-Signer-Timestamp: <unix seconds>
+	Authorization: HMAC-SHA256 <hex HMAC of "METHOD\nPATH\nTIMESTAMP\nBODY">
+
+PATH is relative to the signer's base url, without a leading slash e.g. keys/treasury/sign, so the server may be mounted
+under any prefix.
+*/
+
+// Remote signer request headers
+const (
+	RemoteSignerTimestampHeader = "X-Endless-Signer-Timestamp"
+	RemoteSignerAuthScheme      = "HMAC-SHA256"
+)
+
+// Remote signer key schemes, in [RemoteSignerKey]
+const (
+	RemoteSignerSchemeEd25519   = "ed25519"    // RemoteSignerSchemeEd25519 is a legacy Ed25519 account key
+	RemoteSignerSchemeSingleKey = "single_key" // RemoteSignerSchemeSingleKey is a single key account key, with an [crypto.AnyPublicKey]
+)
+
+// Error codes returned by a [RemoteSignerServer], in the error_code field of an [api.Error]
+const (
+	RemoteSignerErrorUnauthorized    = "unauthorized"     // RemoteSignerErrorUnauthorized the request HMAC or timestamp is invalid
+	RemoteSignerErrorKeyNotFound     = "key_not_found"    // RemoteSignerErrorKeyNotFound the key id isn't held by the server
+	RemoteSignerErrorInvalidInput    = "invalid_input"    // RemoteSignerErrorInvalidInput the request couldn't be parsed
+	RemoteSignerErrorMessageRejected = "message_rejected" // RemoteSignerErrorMessageRejected the message isn't in an allowed signing domain
+	RemoteSignerErrorSigningFailed   = "signing_failed"   // RemoteSignerErrorSigningFailed the key failed to sign
+)
+
+// RemoteSignerKey describes a key held by a remote signer
+type RemoteSignerKey struct {
+	KeyId     string `json:"key_id"`
+	Scheme    string `json:"scheme"`     // Scheme is [RemoteSignerSchemeEd25519] or [RemoteSignerSchemeSingleKey]
+	PublicKey string `json:"public_key"` // PublicKey is the hex [crypto.Ed25519PublicKey] or BCS [crypto.AnyPublicKey]
+}
+
+// RemoteSignRequest asks the remote signer to sign a message
+type RemoteSignRequest struct {
+	Message string `json:"message"` // Message is the hex message, starting with an allowed prefix
+}
+
+// RemoteSignResponse is the signature of a [RemoteSignRequest]
+type RemoteSignResponse struct {
+	Signature string `json:"signature"` // Signature is the hex [crypto.Ed25519Signature] or BCS [crypto.AnySignature]
+}
+
+// remoteSignerMac is the HMAC for a request
+func remoteSignerMac(secret []byte, method string, path string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+//region RemoteSigner
+
+// RemoteSigner is a [crypto.Signer] for a key held by a separate signing service, such as a [RemoteSignerServer].
+//
+// The public key is fetched once when created, and every signature from the service is verified against it before use.
+//
+//	signer, err := endless.NewRemoteSigner("https://signer.internal", "treasury", secret, nil)
+//	account, err := endless.NewAccountFromSigner(signer)
+//
+// Implements:
+//   - [crypto.Signer]
+type RemoteSigner struct {
+	baseUrl *url.URL
+	keyId   string
+	secret  []byte
+	client  *http.Client
+
+	scheme  string
+	pubKey  crypto.PublicKey
+	authKey *crypto.AuthenticationKey
+}
+
+// NewRemoteSigner connects to a remote signer, and fetches the public key for keyId.  If httpClient is nil,
+// [http.DefaultClient] is used.
+func NewRemoteSigner(signerUrl string, keyId string, secret []byte, httpClient *http.Client) (*RemoteSigner, error) {
+	baseUrl, err := url.Parse(signerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote signer url '%s': %w", signerUrl, err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	signer := &RemoteSigner{baseUrl: baseUrl, keyId: keyId, secret: secret, client: httpClient}
+
+	key := &RemoteSignerKey{}
+	if err = signer.do(http.MethodGet, "keys/"+url.PathEscape(keyId), nil, key); err != nil {
+		return nil, err
+	}
+	signer.scheme = key.Scheme
+	switch key.Scheme {
+	case RemoteSignerSchemeEd25519:
+		pubKey := &crypto.Ed25519PublicKey{}
+		if err = pubKey.FromHex(key.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid remote signer public key: %w", err)
+		}
+		signer.pubKey = pubKey
+	case RemoteSignerSchemeSingleKey:
+		pubKey := &crypto.AnyPublicKey{}
+		if err = pubKey.FromHex(key.PublicKey); err != nil {
+			return nil, fmt.Errorf("invalid remote signer public key: %w", err)
+		}
+		signer.pubKey = pubKey
+	default:
+		return nil, fmt.Errorf("unsupported remote signer key scheme %s", key.Scheme)
+	}
+	signer.authKey = signer.pubKey.AuthKey()
+	return signer, nil
+}
+
+// do sends an authenticated request to the escaped path relative to the base url, and decodes the JSON response
+func (rs *RemoteSigner) do(method string, path string, request any, response any) error {
+	requestUrl := strings.TrimSuffix(rs.baseUrl.String(), "/") + "/" + path
+	var body []byte
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return err
+		}
+	}
+	httpRequest, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	httpRequest.Header.Set(RemoteSignerTimestampHeader, timestamp)
+	httpRequest.Header.Set("Authorization", RemoteSignerAuthScheme+" "+hex.EncodeToString(remoteSignerMac(rs.secret, method, path, timestamp, body)))
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := rs.client.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("%s %s, %w", method, requestUrl, err)
+	}
+	if httpResponse.StatusCode >= 400 {
+		return NewHttpError(httpResponse)
+	}
+	defer httpResponse.Body.Close()
+	if err = json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		return fmt.Errorf("error getting response data, %w", err)
+	}
+	return nil
+}
+
+// KeyId is the id of the key on the remote signer
+func (rs *RemoteSigner) KeyId() string {
+	return rs.keyId
+}
+
+// SignMessage asks the remote signer to sign the message, and verifies the returned signature
+//
+// Implements:
+//   - [crypto.Signer]
+func (rs *RemoteSigner) SignMessage(msg []byte) (crypto.Signature, error) {
+	response := &RemoteSignResponse{}
+	request := &RemoteSignRequest{Message: BytesToHex(msg)}
+	if err := rs.do(http.MethodPost, "keys/"+url.PathEscape(rs.keyId)+"/sign", request, response); err != nil {
+		return nil, err
+	}
+
+	var signature crypto.Signature
+	if rs.scheme == RemoteSignerSchemeEd25519 {
+		signature = &crypto.Ed25519Signature{}
+	} else {
+		signature = &crypto.AnySignature{}
+	}
+	if err := signature.FromHex(response.Signature); err != nil {
+		return nil, fmt.Errorf("invalid remote signer signature: %w", err)
+	}
+	if !rs.pubKey.Verify(msg, signature) {
+		return nil, errors.New("remote signer returned an invalid signature")
+	}
+	return signature, nil
+}
+
+// Sign signs the message remotely, and returns the [crypto.AccountAuthenticator]
+//
+// Implements:
+//   - [crypto.Signer]
+func (rs *RemoteSigner) Sign(msg []byte) (*crypto.AccountAuthenticator, error) {
+	signature, err := rs.SignMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return rs.authenticator(signature), nil
+}
+
+// SimulationAuthenticator creates an [crypto.AccountAuthenticator] with an empty signature, without calling the
+// remote signer
+//
+// Implements:
+//   - [crypto.Signer]
+func (rs *RemoteSigner) SimulationAuthenticator() *crypto.AccountAuthenticator {
+	if rs.scheme == RemoteSignerSchemeEd25519 {
+		return rs.authenticator(&crypto.Ed25519Signature{})
+	}
+	return rs.authenticator(rs.pubKey.(*crypto.AnyPublicKey).EmptySignature())
+}
+
+func (rs *RemoteSigner) authenticator(signature crypto.Signature) *crypto.AccountAuthenticator {
+	if rs.scheme == RemoteSignerSchemeEd25519 {
+		return &crypto.AccountAuthenticator{
+			Variant: crypto.AccountAuthenticatorEd25519,
+			Auth: &crypto.Ed25519Authenticator{
+				PubKey: rs.pubKey.(*crypto.Ed25519PublicKey),
+				Sig:    signature.(*crypto.Ed25519Signature),
+			},
+		}
+	}
+	return &crypto.AccountAuthenticator{
+		Variant: crypto.AccountAuthenticatorSingleSender,
+		Auth: &crypto.SingleKeyAuthenticator{
+			PubKey: rs.pubKey.(*crypto.AnyPublicKey),
+			Sig:    signature.(*crypto.AnySignature),
+		},
+	}
+}
+
+// AuthKey is the cached [crypto.AuthenticationKey] of the remote key
+//
+// Implements:
+//   - [crypto.Signer]
+func (rs *RemoteSigner) AuthKey() *crypto.AuthenticationKey {
+	return rs.authKey
+}
+
+// PubKey is the cached [crypto.PublicKey] of the remote key
+//
+// Implements:
+//   - [crypto.Signer]
+func (rs *RemoteSigner) PubKey() crypto.PublicKey {
+	return rs.pubKey
+}
+
+//endregion
+
+//region RemoteSignerServer
+
+// RemoteSignerServer is a reference [http.Handler] for the remote signer protocol, holding keys in memory.
+//
+// It only signs messages that start with an allowed prefix, by default the [RawTransactionPrehash] and
+// [RawTransactionWithDataPrehash] domains, so a leaked secret can sign transactions but not arbitrary messages.
+//
+//	server := endless.NewRemoteSignerServer(secret)
+//	err := server.AddKey("treasury", privateKey)
+//	http.Handle("/signer/", http.StripPrefix("/signer", server))
+type RemoteSignerServer struct {
+	secret   []byte
+	prefixes [][]byte
+	now      func() time.Time
+
+	// MaxClockSkew is how far a request's timestamp may be from the server's clock, default 5 minutes
+	MaxClockSkew time.Duration
+
+	mutex sync.RWMutex
+	keys  map[string]crypto.Signer
+}
+
+// NewRemoteSignerServer creates a [RemoteSignerServer] that authenticates requests with the shared secret
+func NewRemoteSignerServer(secret []byte) *RemoteSignerServer {
+	return &RemoteSignerServer{
+		secret:       secret,
+		prefixes:     [][]byte{RawTransactionPrehash(), RawTransactionWithDataPrehash()},
+		now:          time.Now,
+		MaxClockSkew: 5 * time.Minute,
+		keys:         make(map[string]crypto.Signer),
+	}
+}
+
+// AddKey adds a key to the server.  The signer must be a [crypto.Ed25519PrivateKey], or a [crypto.SingleSigner].
+func (server *RemoteSignerServer) AddKey(keyId string, signer crypto.Signer) error {
+	switch signer.(type) {
+	case *crypto.Ed25519PrivateKey, *crypto.SingleSigner:
+	default:
+		return fmt.Errorf("unsupported remote signer key type %T", signer)
+	}
+	if keyId == "" || strings.Contains(keyId, "/") {
+		return fmt.Errorf("invalid key id %q", keyId)
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.keys[keyId] = signer
+	return nil
+}
+
+// AllowPrefix allows signing messages that start with the prefix, in addition to the transaction domains.  Only add
+// prefixes of other domain separated messages, a short prefix allows signing nearly anything.
+func (server *RemoteSignerServer) AllowPrefix(prefix []byte) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.prefixes = append(server.prefixes, append([]byte{}, prefix...))
+}
+
+// ServeHTTP handles the remote signer protocol
+//
+// Implements:
+//   - [http.Handler]
+func (server *RemoteSignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		server.writeError(w, http.StatusBadRequest, RemoteSignerErrorInvalidInput, err.Error())
+		return
+	}
+	if !server.authenticate(r, body) {
+		server.writeError(w, http.StatusUnauthorized, RemoteSignerErrorUnauthorized, "invalid request signature or timestamp")
+		return
+	}
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(path) < 2 || path[0] != "keys" {
+		server.writeError(w, http.StatusNotFound, RemoteSignerErrorKeyNotFound, "not found")
+		return
+	}
+	server.mutex.RLock()
+	signer, ok := server.keys[path[1]]
+	prefixes := server.prefixes
+	server.mutex.RUnlock()
+	if !ok {
+		server.writeError(w, http.StatusNotFound, RemoteSignerErrorKeyNotFound, "key "+path[1]+" not found")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(path) == 2:
+		scheme := RemoteSignerSchemeSingleKey
+		if _, ok = signer.(*crypto.Ed25519PrivateKey); ok {
+			scheme = RemoteSignerSchemeEd25519
+		}
+		server.writeJson(w, http.StatusOK, &RemoteSignerKey{KeyId: path[1], Scheme: scheme, PublicKey: signer.PubKey().ToHex()})
+	case r.Method == http.MethodPost && len(path) == 3 && path[2] == "sign":
+		request := &RemoteSignRequest{}
+		if err = json.Unmarshal(body, request); err != nil {
+			server.writeError(w, http.StatusBadRequest, RemoteSignerErrorInvalidInput, err.Error())
+			return
+		}
+		message, err := ParseHex(request.Message)
+		if err != nil {
+			server.writeError(w, http.StatusBadRequest, RemoteSignerErrorInvalidInput, "invalid message: "+err.Error())
+			return
+		}
+		if !allowedMessage(prefixes, message) {
+			server.writeError(w, http.StatusForbidden, RemoteSignerErrorMessageRejected, "message is not in an allowed signing domain")
+			return
+		}
+		signature, err := signer.SignMessage(message)
+		if err != nil {
+			server.writeError(w, http.StatusInternalServerError, RemoteSignerErrorSigningFailed, err.Error())
+			return
+		}
+		server.writeJson(w, http.StatusOK, &RemoteSignResponse{Signature: signature.ToHex()})
+	default:
+		server.writeError(w, http.StatusNotFound, RemoteSignerErrorKeyNotFound, "not found")
+	}
+}
+
+// authenticate checks the request's HMAC and timestamp
+func (server *RemoteSignerServer) authenticate(r *http.Request, body []byte) bool {
+	timestamp := r.Header.Get(RemoteSignerTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := server.now().Sub(time.Unix(seconds, 0))
+	if skew > server.MaxClockSkew || skew < -server.MaxClockSkew {
+		return false
+	}
+	authorization, ok := strings.CutPrefix(r.Header.Get("Authorization"), RemoteSignerAuthScheme+" ")
+	if !ok {
+		return false
+	}
+	mac, err := hex.DecodeString(authorization)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(mac, remoteSignerMac(server.secret, r.Method, strings.TrimPrefix(r.URL.EscapedPath(), "/"), timestamp, body))
+}
+
+// allowedMessage is true if the message is longer than, and starts with, one of the prefixes
+func allowedMessage(prefixes [][]byte, message []byte) bool {
+	for _, prefix := range prefixes {
+		if len(message) > len(prefix) && bytes.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (server *RemoteSignerServer) writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (server *RemoteSignerServer) writeError(w http.ResponseWriter, statusCode int, errorCode string, message string) {
+	server.writeJson(w, statusCode, api.Error{Message: message, ErrorCode: errorCode})
+}
+
+//endregion
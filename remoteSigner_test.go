@@ -0,0 +1,113 @@
+package endless
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRemoteSignerSecret = []byte("remote signer secret")
+
+func newRemoteSignerTest(t *testing.T) (*RemoteSignerServer, *httptest.Server) {
+	server := NewRemoteSignerServer(testRemoteSignerSecret)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return server, httpServer
+}
+
+func remoteSignerStatus(t *testing.T, err error) int {
+	var httpError *HttpError
+	assert.True(t, errors.As(err, &httpError))
+	return httpError.StatusCode
+}
+
+func TestRemoteSigner_SignTransaction(t *testing.T) {
+	server, httpServer := newRemoteSignerTest(t)
+	ed25519Account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	singleSenderAccount, err := NewEd25519SingleSenderAccount()
+	assert.NoError(t, err)
+	secp256k1Account, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+
+	for _, local := range []*Account{ed25519Account, singleSenderAccount, secp256k1Account} {
+		keyId := "key " + local.Address.String()
+		assert.NoError(t, server.AddKey(keyId, local.Signer))
+
+		signer, err := NewRemoteSigner(httpServer.URL, keyId, testRemoteSignerSecret, httpServer.Client())
+		assert.NoError(t, err)
+		assert.Equal(t, keyId, signer.KeyId())
+		assert.Equal(t, local.Signer.AuthKey(), signer.AuthKey())
+		assert.Equal(t, local.Signer.PubKey().ToHex(), signer.PubKey().ToHex())
+		assert.Equal(t, local.Signer.SimulationAuthenticator().Variant, signer.SimulationAuthenticator().Variant)
+
+		account, err := NewAccountFromSigner(signer)
+		assert.NoError(t, err)
+		assert.Equal(t, local.Address, account.Address)
+
+		entryFunction, err := CoinTransferPayload(nil, AccountOne, 100)
+		assert.NoError(t, err)
+		rawTxn := &RawTransaction{
+			Sender:                     account.Address,
+			Payload:                    TransactionPayload{Payload: entryFunction},
+			MaxGasAmount:               1000,
+			GasUnitPrice:               100,
+			ExpirationTimestampSeconds: uint64(time.Now().Unix() + 60),
+			ChainId:                    4,
+		}
+		signedTxn, err := rawTxn.SignedTransaction(signer)
+		assert.NoError(t, err)
+		assert.NoError(t, signedTxn.Verify())
+	}
+}
+
+func TestRemoteSigner_RejectsMessage(t *testing.T) {
+	server, httpServer := newRemoteSignerTest(t)
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	assert.NoError(t, server.AddKey("key", account.Signer))
+	signer, err := NewRemoteSigner(httpServer.URL, "key", testRemoteSignerSecret, httpServer.Client())
+	assert.NoError(t, err)
+
+	// Arbitrary messages, and the bare prefix, aren't signed
+	_, err = signer.SignMessage([]byte("hello"))
+	assert.Equal(t, http.StatusForbidden, remoteSignerStatus(t, err))
+	_, err = signer.SignMessage(RawTransactionPrehash())
+	assert.Equal(t, http.StatusForbidden, remoteSignerStatus(t, err))
+	apiError, ok := ApiErrorFromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, RemoteSignerErrorMessageRejected, apiError.ErrorCode)
+
+	// Until the prefix is allowed
+	server.AllowPrefix([]byte("hello"))
+	signature, err := signer.SignMessage([]byte("hello world"))
+	assert.NoError(t, err)
+	assert.True(t, account.Signer.PubKey().Verify([]byte("hello world"), signature))
+
+	assert.Error(t, server.AddKey("remote", signer))
+	assert.Error(t, server.AddKey("a/b", account.Signer))
+}
+
+func TestRemoteSigner_Unauthorized(t *testing.T) {
+	server, httpServer := newRemoteSignerTest(t)
+	account, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	assert.NoError(t, server.AddKey("key", account.Signer))
+
+	_, err = NewRemoteSigner(httpServer.URL, "key", []byte("wrong secret"), httpServer.Client())
+	assert.Equal(t, http.StatusUnauthorized, remoteSignerStatus(t, err))
+
+	_, err = NewRemoteSigner(httpServer.URL, "missing", testRemoteSignerSecret, httpServer.Client())
+	assert.Equal(t, http.StatusNotFound, remoteSignerStatus(t, err))
+
+	// Requests outside the clock skew are rejected
+	signer, err := NewRemoteSigner(httpServer.URL, "key", testRemoteSignerSecret, httpServer.Client())
+	assert.NoError(t, err)
+	server.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	_, err = signer.Sign(append(RawTransactionPrehash(), 1))
+	assert.Equal(t, http.StatusUnauthorized, remoteSignerStatus(t, err))
+}
@@ -0,0 +1,545 @@
+package endless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+)
+
+const (
+	// VmStatusSequenceNumberTooOld is the VM validation status when the sequence number was already used on-chain
+	VmStatusSequenceNumberTooOld = uint64(3)
+	// VmStatusSequenceNumberTooNew is the VM validation status when the sequence number is too far ahead of on-chain
+	VmStatusSequenceNumberTooNew = uint64(4)
+)
+
+// IsSequenceNumberTooOld tells if the error returned from submission is SEQUENCE_NUMBER_TOO_OLD
+func IsSequenceNumberTooOld(err error) bool {
+	if apiError, ok := ApiErrorFromError(err); ok && apiError.ErrorCode == "sequence_number_too_old" {
+		return true
+	}
+	return isVmStatusError(err, VmStatusSequenceNumberTooOld, "SEQUENCE_NUMBER_TOO_OLD")
+}
+
+// IsSequenceNumberTooNew tells if the error returned from submission is SEQUENCE_NUMBER_TOO_NEW
+func IsSequenceNumberTooNew(err error) bool {
+	return isVmStatusError(err, VmStatusSequenceNumberTooNew, "SEQUENCE_NUMBER_TOO_NEW")
+}
+
+func isVmStatusError(err error, vmStatus uint64, name string) bool {
+	if err == nil {
+		return false
+	}
+	apiError, ok := ApiErrorFromError(err)
+	if !ok {
+		return strings.Contains(err.Error(), name)
+	}
+	return apiError.VmErrorCode == vmStatus || strings.Contains(apiError.Message, name)
+}
+
+// SequenceNumberStore persists the next sequence number to hand out per account.  It allows a
+// [SequenceNumberManager] to survive restarts, and to be shared between processes sending from the same account.
+type SequenceNumberStore interface {
+	// Update atomically replaces the next sequence number stored for the address with the output of update.
+	// found is false if nothing has been stored for the address yet.  Returns the newly stored value.
+	Update(address AccountAddress, update func(next uint64, found bool) uint64) (uint64, error)
+}
+
+// MemorySequenceNumberStore is a [SequenceNumberStore] shared only within the process.  It is the default for
+// [NewSequenceNumberManager].
+type MemorySequenceNumberStore struct {
+	mutex sync.Mutex
+	next  map[AccountAddress]uint64
+}
+
+// NewMemorySequenceNumberStore creates an empty [MemorySequenceNumberStore]
+func NewMemorySequenceNumberStore() *MemorySequenceNumberStore {
+	return &MemorySequenceNumberStore{next: make(map[AccountAddress]uint64)}
+}
+
+// Update atomically replaces the next sequence number for the address
+//
+// Implements:
+//   - [SequenceNumberStore]
+func (store *MemorySequenceNumberStore) Update(address AccountAddress, update func(next uint64, found bool) uint64) (uint64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	next, found := store.next[address]
+	next = update(next, found)
+	store.next[address] = next
+	return next, nil
+}
+
+// FileSequenceNumberStore is a [SequenceNumberStore] backed by a JSON file.  Processes on the same machine pointing
+// at the same Path share sequence numbers.  Access is serialized with a lock file next to Path, so it is safe without
+// OS specific file locking.
+type FileSequenceNumberStore struct {
+	Path         string        // Path of the JSON file mapping address to next sequence number
+	LockTimeout  time.Duration // LockTimeout is how long to wait for the lock, defaults to 10 seconds
+	StaleLockAge time.Duration // StaleLockAge is when a lock left by a crashed process is removed, defaults to 30 seconds
+}
+
+// NewFileSequenceNumberStore creates a [FileSequenceNumberStore] with default timeouts
+func NewFileSequenceNumberStore(path string) *FileSequenceNumberStore {
+	return &FileSequenceNumberStore{
+		Path:         path,
+		LockTimeout:  10 * time.Second,
+		StaleLockAge: 30 * time.Second,
+	}
+}
+
+// Update atomically replaces the next sequence number for the address, across processes
+//
+// Implements:
+//   - [SequenceNumberStore]
+func (store *FileSequenceNumberStore) Update(address AccountAddress, update func(next uint64, found bool) uint64) (uint64, error) {
+	unlock, err := store.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	state := make(map[string]uint64)
+	blob, err := os.ReadFile(store.Path)
+	if err == nil {
+		err = json.Unmarshal(blob, &state)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse sequence number store %s: %w", store.Path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	key := address.StringLong()
+	next, found := state[key]
+	next = update(next, found)
+	state[key] = next
+
+	blob, err = json.Marshal(state)
+	if err != nil {
+		return 0, err
+	}
+	// Write to a temporary file and rename, so a crash never leaves a partially written store
+	tmpPath := store.Path + ".tmp"
+	err = os.WriteFile(tmpPath, blob, 0600)
+	if err != nil {
+		return 0, err
+	}
+	err = os.Rename(tmpPath, store.Path)
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (store *FileSequenceNumberStore) lock() (unlock func(), err error) {
+	lockTimeout := store.LockTimeout
+	if lockTimeout == 0 {
+		lockTimeout = 10 * time.Second
+	}
+	staleLockAge := store.StaleLockAge
+	if staleLockAge == 0 {
+		staleLockAge = 30 * time.Second
+	}
+
+	lockPath := store.Path + ".lock"
+	err = os.MkdirAll(filepath.Dir(lockPath), 0700)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = file.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		// Remove locks left behind by a crashed process
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for sequence number store lock %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// SequenceNumberManager hands out sequence numbers for a single sender account, and reconciles them with the chain.
+//
+// Unlike [SequenceNumberTracker], it knows about the outcome of each transaction:
+//   - [SequenceNumberManager.Release] returns a number that never reached the mempool, so it is handed out again
+//   - [SequenceNumberManager.Reconcile] re-syncs with on-chain state on SEQUENCE_NUMBER_TOO_OLD or TOO_NEW errors
+//   - [SequenceNumberManager.DetectGaps] finds numbers left unused, for example by transactions that expired
+//   - [SequenceNumberManager.FillGaps] fills the gaps with no-op transactions, so later transactions can proceed
+//
+// It is safe to use across goroutines.  To share an account across processes, provide a [FileSequenceNumberStore].
+// Only the next number is stored, numbers in flight and released are kept in memory.  Gap detection, and the numbers
+// handed out again by Release and Reconcile, only cover numbers handed out by this manager since it was created.
+type SequenceNumberManager struct {
+	client  *NodeClient
+	address AccountAddress
+	store   SequenceNumberStore
+
+	mutex    sync.Mutex
+	synced   bool
+	onChain  uint64            // onChain is the last known on-chain sequence number of the account
+	released []uint64          // released are sorted numbers that never reached the mempool, they are handed out first
+	inFlight map[uint64]uint64 // inFlight maps handed out numbers to the expiration timestamp, 0 if not submitted yet
+}
+
+// NewSequenceNumberManager creates a manager for the sender address.  The on-chain sequence number is fetched lazily.
+//
+// Accepts options:
+//   - [SequenceNumberStore] defaults to a new [MemorySequenceNumberStore]
+func NewSequenceNumberManager(client *NodeClient, address AccountAddress, options ...any) (*SequenceNumberManager, error) {
+	var store SequenceNumberStore
+	for i, option := range options {
+		switch ovalue := option.(type) {
+		case SequenceNumberStore:
+			store = ovalue
+		default:
+			return nil, fmt.Errorf("NewSequenceNumberManager arg [%d] unknown option type %T", i+3, option)
+		}
+	}
+	if store == nil {
+		store = NewMemorySequenceNumberStore()
+	}
+	return &SequenceNumberManager{
+		client:   client,
+		address:  address,
+		store:    store,
+		inFlight: make(map[uint64]uint64),
+	}, nil
+}
+
+// Address is the sender account the manager is handing out sequence numbers for
+func (snm *SequenceNumberManager) Address() AccountAddress {
+	return snm.address
+}
+
+// Next reserves the next sequence number to build a transaction with.  The caller must report the outcome with
+// [SequenceNumberManager.Submitted] or [SequenceNumberManager.Release].
+func (snm *SequenceNumberManager) Next() (uint64, error) {
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	if !snm.synced {
+		err := snm.syncLocked()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(snm.released) > 0 {
+		sequenceNumber := snm.released[0]
+		snm.released = snm.released[1:]
+		snm.inFlight[sequenceNumber] = 0
+		return sequenceNumber, nil
+	}
+
+	onChain := snm.onChain
+	next, err := snm.store.Update(snm.address, func(next uint64, found bool) uint64 {
+		if !found || next < onChain {
+			next = onChain
+		}
+		return next + 1
+	})
+	if err != nil {
+		return 0, err
+	}
+	sequenceNumber := next - 1
+	snm.inFlight[sequenceNumber] = 0
+	return sequenceNumber, nil
+}
+
+// Release returns a sequence number whose transaction failed to build, sign, or submit before reaching the mempool.
+// It will be handed out again by [SequenceNumberManager.Next] before any new number.
+func (snm *SequenceNumberManager) Release(sequenceNumber uint64) {
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	delete(snm.inFlight, sequenceNumber)
+	if sequenceNumber < snm.onChain {
+		return
+	}
+	index, found := slices.BinarySearch(snm.released, sequenceNumber)
+	if !found {
+		snm.released = slices.Insert(snm.released, index, sequenceNumber)
+	}
+}
+
+// Submitted records that the transaction with the sequence number was accepted into the mempool
+func (snm *SequenceNumberManager) Submitted(sequenceNumber uint64, expirationTimestampSeconds uint64) {
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	if sequenceNumber < snm.onChain {
+		return
+	}
+	snm.inFlight[sequenceNumber] = expirationTimestampSeconds
+}
+
+// Committed records that the transaction with the sequence number was committed, successful or not
+func (snm *SequenceNumberManager) Committed(sequenceNumber uint64) {
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	snm.advanceLocked(sequenceNumber + 1)
+}
+
+// Sync fetches the on-chain sequence number, and forgets about any numbers that have already been used on-chain
+func (snm *SequenceNumberManager) Sync() error {
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	return snm.syncLocked()
+}
+
+// Reconcile re-syncs with on-chain state if the error is a sequence number error from submission.  Returns true if
+// the error was a sequence number error, in which case the transaction can be rebuilt with a new number from
+// [SequenceNumberManager.Next].
+//
+// On SEQUENCE_NUMBER_TOO_NEW, the rejected number is handed out again before any new number.  The stored counter is
+// never lowered, as another process sharing the [SequenceNumberStore] may have handed out the numbers below it.
+func (snm *SequenceNumberManager) Reconcile(sequenceNumber uint64, err error) (bool, error) {
+	tooOld := IsSequenceNumberTooOld(err)
+	tooNew := IsSequenceNumberTooNew(err)
+	if !tooOld && !tooNew {
+		return false, nil
+	}
+
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	delete(snm.inFlight, sequenceNumber)
+	syncErr := snm.syncLocked()
+	if syncErr != nil {
+		return true, syncErr
+	}
+	if tooNew {
+		// The rejected number never reached the mempool, so it's handed out again once the numbers before it land.
+		// Numbers between the on-chain number and the counter that this manager didn't hand out may be held by
+		// another process sharing the store, so they are never handed out here, and the counter is never lowered.
+		next := snm.onChain
+		for seq := range snm.inFlight {
+			next = max(next, seq+1)
+		}
+		if sequenceNumber >= snm.onChain {
+			next = max(next, sequenceNumber+1)
+			index, found := slices.BinarySearch(snm.released, sequenceNumber)
+			if !found {
+				snm.released = slices.Insert(snm.released, index, sequenceNumber)
+			}
+		}
+		_, storeErr := snm.store.Update(snm.address, func(stored uint64, _ bool) uint64 { return max(stored, next) })
+		if storeErr != nil {
+			return true, storeErr
+		}
+	}
+	return true, nil
+}
+
+// DetectGaps returns sequence numbers that were handed out but will never be used on-chain without intervention.
+// These are released numbers, and numbers of transactions that expired according to the ledger timestamp.  Later
+// transactions from the account cannot commit until these are filled.
+func (snm *SequenceNumberManager) DetectGaps() ([]uint64, error) {
+	info, err := snm.client.Info()
+	if err != nil {
+		return nil, err
+	}
+	ledgerSeconds := info.LedgerTimestamp() / 1_000_000
+
+	snm.mutex.Lock()
+	defer snm.mutex.Unlock()
+	err = snm.syncLocked()
+	if err != nil {
+		return nil, err
+	}
+	gaps := slices.Clone(snm.released)
+	for seq, expiration := range snm.inFlight {
+		if expiration != 0 && expiration <= ledgerSeconds {
+			gaps = append(gaps, seq)
+		}
+	}
+	slices.Sort(gaps)
+	return gaps, nil
+}
+
+// FillGaps submits a no-op transaction, a transfer of 0 EDS to itself, for each gap found by
+// [SequenceNumberManager.DetectGaps].  The sender must be the signer for the managed account.
+//
+// Accepts options for [NodeClient.BuildTransaction] except [SequenceNumber]
+func (snm *SequenceNumberManager) FillGaps(sender TransactionSigner, options ...any) ([]*api.SubmitTransactionResponse, error) {
+	senderAddress := sender.AccountAddress()
+	if senderAddress != snm.address {
+		return nil, fmt.Errorf("sender %s does not match managed account %s", senderAddress.String(), snm.address.String())
+	}
+	gaps, err := snm.DetectGaps()
+	if err != nil {
+		return nil, err
+	}
+	entryFunction, err := CoinTransferPayload(nil, snm.address, 0)
+	if err != nil {
+		return nil, err
+	}
+	payload := TransactionPayload{Payload: entryFunction}
+
+	responses := make([]*api.SubmitTransactionResponse, 0, len(gaps))
+	for _, seq := range gaps {
+		snm.mutex.Lock()
+		snm.released = slices.DeleteFunc(snm.released, func(released uint64) bool { return released == seq })
+		snm.inFlight[seq] = 0
+		snm.mutex.Unlock()
+
+		response, err := snm.submit(sender, payload, seq, options...)
+		if err != nil {
+			return responses, fmt.Errorf("failed to fill sequence number gap %d: %w", seq, err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// BuildSignAndSubmitTransaction builds, signs, and submits a transaction with the next sequence number.  Sequence
+// numbers are released on failure before the mempool, and sequence number errors are reconciled and retried once.
+//
+// Accepts options for [NodeClient.BuildTransaction] except [SequenceNumber]
+func (snm *SequenceNumberManager) BuildSignAndSubmitTransaction(sender TransactionSigner, payload TransactionPayload, options ...any) (*api.SubmitTransactionResponse, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var seq uint64
+		seq, err = snm.Next()
+		if err != nil {
+			return nil, err
+		}
+		var response *api.SubmitTransactionResponse
+		response, err = snm.submit(sender, payload, seq, options...)
+		if err == nil {
+			return response, nil
+		}
+		retry, syncErr := snm.Reconcile(seq, err)
+		if syncErr != nil {
+			return nil, syncErr
+		}
+		if !retry {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// submit builds, signs, and submits a transaction with a reserved sequence number, releasing it on failures before
+// the mempool.  If the submission failed without a rejection, the transaction is looked up before releasing it.
+func (snm *SequenceNumberManager) submit(sender TransactionSigner, payload TransactionPayload, seq uint64, options ...any) (*api.SubmitTransactionResponse, error) {
+	rawTxn, err := snm.client.BuildTransaction(snm.address, payload, append(slices.Clone(options), SequenceNumber(seq))...)
+	if err != nil {
+		snm.Release(seq)
+		return nil, err
+	}
+	signedTxn, err := rawTxn.SignedTransaction(sender)
+	if err != nil {
+		snm.Release(seq)
+		return nil, err
+	}
+	response, err := snm.client.SubmitTransaction(signedTxn)
+	if err != nil {
+		switch {
+		case IsSequenceNumberTooOld(err) || IsSequenceNumberTooNew(err):
+			// Sequence number errors are left for Reconcile, as the number may be in use
+		case isSubmissionRejected(err):
+			snm.Release(seq)
+		default:
+			// On a timeout or server error the transaction may have reached the mempool anyway
+			known, lookupErr := transactionKnown(snm.client, signedTxn)
+			switch {
+			case known:
+				snm.Submitted(seq, rawTxn.ExpirationTimestampSeconds)
+				hash, hashErr := signedTxn.Hash()
+				if hashErr != nil {
+					return nil, hashErr
+				}
+				return &api.SubmitTransactionResponse{
+					Hash:                    hash,
+					Sender:                  &rawTxn.Sender,
+					SequenceNumber:          seq,
+					MaxGasAmount:            rawTxn.MaxGasAmount,
+					GasUnitPrice:            rawTxn.GasUnitPrice,
+					ExpirationTimestampSecs: rawTxn.ExpirationTimestampSeconds,
+				}, nil
+			case lookupErr != nil:
+				// Unknown, keep the number until the transaction expires, then it's found by DetectGaps
+				snm.Submitted(seq, rawTxn.ExpirationTimestampSeconds)
+			default:
+				snm.Release(seq)
+			}
+		}
+		return nil, err
+	}
+	snm.Submitted(seq, rawTxn.ExpirationTimestampSeconds)
+	return response, nil
+}
+
+// isSubmissionRejected tells if the node rejected a submission outright, so none of it reached the mempool
+func isSubmissionRejected(err error) bool {
+	var httpError *HttpError
+	return errors.As(err, &httpError) && httpError.StatusCode >= 400 && httpError.StatusCode < 500
+}
+
+// transactionKnown tells if the node has the transaction, pending or committed
+func transactionKnown(client *NodeClient, signedTxn *SignedTransaction) (bool, error) {
+	hash, err := signedTxn.Hash()
+	if err != nil {
+		return false, err
+	}
+	_, err = client.TransactionByHash(hash)
+	if err != nil {
+		var httpError *HttpError
+		if errors.As(err, &httpError) && httpError.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (snm *SequenceNumberManager) syncLocked() error {
+	account, err := snm.client.Account(snm.address)
+	if err != nil {
+		return err
+	}
+	onChain, err := account.SequenceNumber()
+	if err != nil {
+		return err
+	}
+	snm.synced = true
+	snm.advanceLocked(onChain)
+	_, err = snm.store.Update(snm.address, func(next uint64, found bool) uint64 {
+		if !found || next < onChain {
+			return onChain
+		}
+		return next
+	})
+	return err
+}
+
+// advanceLocked moves the on-chain sequence number forward, forgetting about numbers below it
+func (snm *SequenceNumberManager) advanceLocked(onChain uint64) {
+	if onChain < snm.onChain {
+		return
+	}
+	snm.onChain = onChain
+	for seq := range snm.inFlight {
+		if seq < onChain {
+			delete(snm.inFlight, seq)
+		}
+	}
+	snm.released = slices.DeleteFunc(snm.released, func(seq uint64) bool { return seq < onChain })
+}
@@ -0,0 +1,333 @@
+package endless
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func sequenceNumberError(vmErrorCode uint64, name string) func(*SignedTransaction) (int, map[string]any) {
+	return func(*SignedTransaction) (int, map[string]any) {
+		return http.StatusBadRequest, map[string]any{
+			"message":       "Invalid transaction: Type: Validation Code: " + name,
+			"error_code":    "vm_error",
+			"vm_error_code": vmErrorCode,
+		}
+	}
+}
+
+func TestSequenceNumberManager_NextAndRelease(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 5)
+
+	snm, err := NewSequenceNumberManager(node.client, sender.Address)
+	assert.NoError(t, err)
+
+	// Concurrent callers get unique numbers
+	numbers := make(chan uint64, 10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seq, err := snm.Next()
+			assert.NoError(t, err)
+			numbers <- seq
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+	seen := make(map[uint64]bool)
+	for seq := range numbers {
+		assert.False(t, seen[seq])
+		assert.GreaterOrEqual(t, seq, uint64(5))
+		assert.Less(t, seq, uint64(15))
+		seen[seq] = true
+	}
+
+	// Released numbers are handed out first, lowest first
+	snm.Release(9)
+	snm.Release(7)
+	seq, err := snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), seq)
+	seq, err = snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), seq)
+	seq, err = snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), seq)
+}
+
+func TestSequenceNumberManager_Reconcile(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	snm, err := NewSequenceNumberManager(node.client, sender.Address)
+	assert.NoError(t, err)
+	seq, err := snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), seq)
+
+	// Another sender used the account, so the number is too old
+	node.SetAccount(sender.Address, 3)
+	node.SubmitHook = sequenceNumberError(VmStatusSequenceNumberTooOld, "SEQUENCE_NUMBER_TOO_OLD")
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	payload := TransactionPayload{Payload: entryFunction}
+	_, err = node.client.SubmitTransaction(buildTestTxn(t, node, sender, payload, seq))
+	assert.True(t, IsSequenceNumberTooOld(err))
+	assert.False(t, IsSequenceNumberTooNew(err))
+
+	retry, err := snm.Reconcile(seq, err)
+	assert.NoError(t, err)
+	assert.True(t, retry)
+	seq, err = snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), seq)
+
+	// Unrelated errors aren't reconciled
+	retry, err = snm.Reconcile(seq, assert.AnError)
+	assert.NoError(t, err)
+	assert.False(t, retry)
+
+	// Too new never hands out a number that is still reserved, 3 to 5 are held by other callers
+	handedOut := map[uint64]bool{3: true}
+	for i := 0; i < 3; i++ {
+		seq, err = snm.Next()
+		assert.NoError(t, err)
+		assert.False(t, handedOut[seq])
+		handedOut[seq] = true
+	}
+	retry, err = snm.Reconcile(6, sequenceNumberErrorValue(t, node, sender, payload, 6))
+	assert.NoError(t, err)
+	assert.True(t, retry)
+	delete(handedOut, 6)
+	seq, err = snm.Next()
+	assert.NoError(t, err)
+	assert.False(t, handedOut[seq])
+	assert.Equal(t, uint64(6), seq)
+	handedOut[seq] = true
+
+	// Only the rejected number is freed, later numbers stay reserved
+	retry, err = snm.Reconcile(4, sequenceNumberErrorValue(t, node, sender, payload, 4))
+	assert.NoError(t, err)
+	assert.True(t, retry)
+	delete(handedOut, 4)
+	for _, expected := range []uint64{4, 7} {
+		seq, err = snm.Next()
+		assert.NoError(t, err)
+		assert.False(t, handedOut[seq])
+		assert.Equal(t, expected, seq)
+		handedOut[seq] = true
+	}
+}
+
+func TestSequenceNumberManager_SharedStore(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	payload := TransactionPayload{Payload: entryFunction}
+
+	// Two processes sharing the store interleave numbers
+	store := NewMemorySequenceNumberStore()
+	snm1, err := NewSequenceNumberManager(node.client, sender.Address, store)
+	assert.NoError(t, err)
+	snm2, err := NewSequenceNumberManager(node.client, sender.Address, store)
+	assert.NoError(t, err)
+	for i, snm := range []*SequenceNumberManager{snm1, snm2, snm1} {
+		seq, err := snm.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i), seq)
+	}
+
+	// Too new only frees the rejected number, the counter isn't lowered under the other's number
+	retry, err := snm1.Reconcile(2, sequenceNumberErrorValue(t, node, sender, payload, 2))
+	assert.NoError(t, err)
+	assert.True(t, retry)
+	seq, err := snm2.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), seq)
+	seq, err = snm1.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), seq)
+	seq, err = snm1.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+}
+
+func TestSequenceNumberManager_SubmitErrorLooksUp(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+	snm, err := NewSequenceNumberManager(node.client, sender.Address)
+	assert.NoError(t, err)
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	payload := TransactionPayload{Payload: entryFunction}
+
+	// The submission fails with a server error, after the transaction reached the mempool or not
+	reachesMempool := atomic.Bool{}
+	node.Handlers["POST /transactions"] = func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signedTxn := &SignedTransaction{}
+		assert.NoError(t, bcs.Deserialize(signedTxn, body))
+		if reachesMempool.Load() {
+			node.mutex.Lock()
+			node.accept(signedTxn)
+			node.mutex.Unlock()
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	// In the mempool, so the number stays in use
+	reachesMempool.Store(true)
+	response, err := snm.BuildSignAndSubmitTransaction(sender, payload, GasUnitPrice(100))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), response.SequenceNumber)
+	hash, err := node.Submitted()[0].Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, response.Hash)
+
+	// Not in the mempool, so the number is released
+	reachesMempool.Store(false)
+	_, err = snm.BuildSignAndSubmitTransaction(sender, payload, GasUnitPrice(100))
+	assert.Error(t, err)
+	seq, err := snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), seq)
+}
+
+func TestSequenceNumberManager_BuildSignAndSubmit(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 10)
+
+	snm, err := NewSequenceNumberManager(node.client, sender.Address)
+	assert.NoError(t, err)
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	payload := TransactionPayload{Payload: entryFunction}
+
+	// First attempt is rejected as too old, the retry succeeds with the new on-chain number
+	attempts := 0
+	node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		attempts++
+		if attempts == 1 {
+			node.accounts[sender.Address].SequenceNumberStr = "12"
+			return sequenceNumberError(VmStatusSequenceNumberTooOld, "SEQUENCE_NUMBER_TOO_OLD")(txn)
+		}
+		return 0, nil
+	}
+	response, err := snm.BuildSignAndSubmitTransaction(sender, payload, GasUnitPrice(100))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), response.SequenceNumber)
+
+	// Failure before the mempool releases the number for reuse
+	node.SubmitHook = func(*SignedTransaction) (int, map[string]any) {
+		return http.StatusBadRequest, map[string]any{"message": "bad", "error_code": "invalid_input"}
+	}
+	_, err = snm.BuildSignAndSubmitTransaction(sender, payload, GasUnitPrice(100))
+	assert.Error(t, err)
+	seq, err := snm.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(13), seq)
+}
+
+func TestSequenceNumberManager_GapsFromExpiration(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+	node.SetLedgerTimestamp(1000)
+
+	snm, err := NewSequenceNumberManager(node.client, sender.Address)
+	assert.NoError(t, err)
+	for i := uint64(0); i < 4; i++ {
+		seq, err := snm.Next()
+		assert.NoError(t, err)
+		snm.Submitted(seq, 1000+i*10)
+	}
+	// 0 committed, 1 expired, 2 released, 3 not yet expired
+	snm.Committed(0)
+	node.SetAccount(sender.Address, 1)
+	snm.Release(2)
+	node.SetLedgerTimestamp(1015)
+
+	gaps, err := snm.DetectGaps()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, gaps)
+
+	responses, err := snm.FillGaps(sender, GasUnitPrice(100))
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 2)
+	assert.Equal(t, uint64(1), submitted[0].Transaction.SequenceNumber)
+	assert.Equal(t, uint64(2), submitted[1].Transaction.SequenceNumber)
+	for _, txn := range submitted {
+		assert.NoError(t, txn.Verify())
+	}
+
+	gaps, err = snm.DetectGaps()
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+}
+
+func TestFileSequenceNumberStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sequence_numbers.json")
+	store1 := NewFileSequenceNumberStore(path)
+	store2 := NewFileSequenceNumberStore(path)
+
+	next, err := store1.Update(AccountOne, func(next uint64, found bool) uint64 {
+		assert.False(t, found)
+		return 7
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), next)
+
+	// A second store, like another process, sees the same state
+	next, err = store2.Update(AccountOne, func(next uint64, found bool) uint64 {
+		assert.True(t, found)
+		return next + 1
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), next)
+
+	next, err = store1.Update(AccountTwo, func(next uint64, found bool) uint64 {
+		assert.False(t, found)
+		return 1
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+}
+
+func buildTestTxn(t *testing.T, node *testNode, sender *Account, payload TransactionPayload, seq uint64) *SignedTransaction {
+	rawTxn, err := node.client.BuildTransaction(sender.Address, payload, SequenceNumber(seq), GasUnitPrice(100))
+	assert.NoError(t, err)
+	signedTxn, err := rawTxn.SignedTransaction(sender)
+	assert.NoError(t, err)
+	return signedTxn
+}
+
+func sequenceNumberErrorValue(t *testing.T, node *testNode, sender *Account, payload TransactionPayload, seq uint64) error {
+	node.SubmitHook = sequenceNumberError(VmStatusSequenceNumberTooNew, "SEQUENCE_NUMBER_TOO_NEW")
+	_, err := node.client.SubmitTransaction(buildTestTxn(t, node, sender, payload, seq))
+	assert.True(t, IsSequenceNumberTooNew(err))
+	return err
+}
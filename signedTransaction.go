@@ -2,6 +2,7 @@ package endless
 
 import (
 	"errors"
+	"fmt"
 	"github.com/btcsuite/btcd/btcutil/base58"
 	"github.com/endless-labs/endless-go-sdk/bcs"
 	"github.com/endless-labs/endless-go-sdk/crypto"
@@ -33,63 +34,162 @@ type SignedTransaction struct {
 	Authenticator *TransactionAuthenticator // The authenticator for a transaction (can't be be a standalone [crypto.AccountAuthenticator])
 }
 
-// Verify checks a signed transaction's signature
+// Verify checks a signed transaction's signatures.  This doesn't check the keys belong to the signing accounts, use
+// [NodeClient.VerifyTransaction] to check them against the accounts on-chain.
 func (txn *SignedTransaction) Verify() error {
-	switch txn.Authenticator.Auth.(type) {
-	case *MultiAgentTransactionAuthenticator:
-		prehash := RawTransactionWithDataPrehash()
+	signers, err := txn.signers()
+	if err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		if !signer.verify() {
+			return fmt.Errorf("%w: %s %s", ErrTransactionSignature, signer.role, signer.address.String())
+		}
+	}
+	return nil
+}
 
-		rawTransactionWithData := &RawTransactionWithData{
+// VerifySignedTransactions checks the signatures of many signed transactions at once, returning an error for each
+// transaction as [SignedTransaction.Verify] would, nil if it's valid.  Ed25519 signatures are verified in batches, and
+// the rest in parallel, see [crypto.BatchVerifier].
+func VerifySignedTransactions(txns []*SignedTransaction) []error {
+	errs := make([]error, len(txns))
+	verifier := crypto.NewBatchVerifier()
+	// Each transaction's signers, and their indices in the verifier
+	txnSigners := make([][]transactionSignature, len(txns))
+	txnIndices := make([][]int, len(txns))
+	for i, txn := range txns {
+		signers, err := txn.signers()
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		txnSigners[i] = signers
+		for _, signer := range signers {
+			txnIndices[i] = append(txnIndices[i], verifier.Add(signer.auth, signer.messages...))
+		}
+	}
+	valid := verifier.Verify()
+	for i, signers := range txnSigners {
+		for j, signer := range signers {
+			if !valid[txnIndices[i][j]] {
+				errs[i] = fmt.Errorf("%w: %s %s", ErrTransactionSignature, signer.role, signer.address.String())
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// SigningMessage is the message every signer of the transaction signed.  Multi-agent and fee payer transactions sign
+// a [RawTransactionWithData] including the other signers' addresses, all others sign the [RawTransaction].
+func (txn *SignedTransaction) SigningMessage() ([]byte, error) {
+	if txn.Authenticator == nil || txn.Authenticator.Auth == nil {
+		return nil, errors.New("transaction has no authenticator")
+	}
+	switch auth := txn.Authenticator.Auth.(type) {
+	case *MultiAgentTransactionAuthenticator:
+		rawTxnWithData := &RawTransactionWithData{
 			Variant: MultiAgentRawTransactionWithDataVariant,
 			Inner: &MultiAgentRawTransactionWithData{
 				RawTxn:           txn.Transaction,
-				SecondarySigners: txn.Authenticator.Auth.(*MultiAgentTransactionAuthenticator).SecondarySignerAddresses,
+				SecondarySigners: auth.SecondarySignerAddresses,
 			},
 		}
-
-		bytes, err := bcs.Serialize(rawTransactionWithData)
-		if err != nil {
-			return err
+		return rawTxnWithData.SigningMessage()
+	case *FeePayerTransactionAuthenticator:
+		rawTxnWithData := &RawTransactionWithData{
+			Variant: MultiAgentWithFeePayerRawTransactionWithDataVariant,
+			Inner: &MultiAgentWithFeePayerRawTransactionWithData{
+				RawTxn:           txn.Transaction,
+				SecondarySigners: auth.SecondarySignerAddresses,
+				FeePayer:         auth.FeePayer,
+			},
 		}
+		return rawTxnWithData.SigningMessage()
+	default:
+		return txn.Transaction.SigningMessage()
+	}
+}
 
-		message := make([]byte, len(prehash)+len(bytes))
-		copy(message, prehash)
-		copy(message[len(prehash):], bytes)
+// transactionSignature is one account's signature on a transaction, and the messages it may have signed
+type transactionSignature struct {
+	role     SignerRole
+	address  AccountAddress
+	auth     *crypto.AccountAuthenticator
+	messages [][]byte
+}
 
-		if txn.Authenticator.Verify(message) {
-			return nil
+// verify checks the signature is valid for one of the messages
+func (signature *transactionSignature) verify() bool {
+	if signature.auth == nil || signature.auth.Auth == nil {
+		return false
+	}
+	for _, message := range signature.messages {
+		if signature.auth.Verify(message) {
+			return true
 		}
-		return errors.New("signature is invalid")
-	default:
-		bytes, err := txn.Transaction.SigningMessage()
-
-		//log.Printf("SignedTransaction Verify.msg = %#v \n\n", bytes)
+	}
+	return false
+}
 
-		if err != nil {
-			return err
+// signers splits the authenticator into each account's signature.  The sender and secondary signers of a fee payer
+// transaction may sign before the fee payer is known, with the fee payer as [AccountZero], so either message is valid
+// for them.
+func (txn *SignedTransaction) signers() ([]transactionSignature, error) {
+	message, err := txn.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	messages := [][]byte{message}
+	var sender *crypto.AccountAuthenticator
+	var secondaryAddresses []AccountAddress
+	var secondarySigners []crypto.AccountAuthenticator
+	var feePayer *transactionSignature
+	switch auth := txn.Authenticator.Auth.(type) {
+	case *Ed25519TransactionAuthenticator:
+		sender = auth.Sender
+	case *MultiEd25519TransactionAuthenticator:
+		sender = auth.Sender
+	case *SingleSenderTransactionAuthenticator:
+		sender = auth.Sender
+	case *MultiAgentTransactionAuthenticator:
+		sender, secondaryAddresses, secondarySigners = auth.Sender, auth.SecondarySignerAddresses, auth.SecondarySigners
+	case *FeePayerTransactionAuthenticator:
+		sender, secondaryAddresses, secondarySigners = auth.Sender, auth.SecondarySignerAddresses, auth.SecondarySigners
+		if auth.FeePayer == nil {
+			return nil, errors.New("fee payer transaction has no fee payer")
 		}
-		if txn.Authenticator.Verify(bytes) {
-			return nil
+		feePayer = &transactionSignature{role: SignerRoleFeePayer, address: *auth.FeePayer, auth: auth.FeePayerAuthenticator, messages: messages}
+		if *auth.FeePayer != AccountZero {
+			unknownFeePayerMessage, err := (&RawTransactionWithData{
+				Variant: MultiAgentWithFeePayerRawTransactionWithDataVariant,
+				Inner: &MultiAgentWithFeePayerRawTransactionWithData{
+					RawTxn:           txn.Transaction,
+					SecondarySigners: auth.SecondarySignerAddresses,
+					FeePayer:         &AccountZero,
+				},
+			}).SigningMessage()
+			if err != nil {
+				return nil, err
+			}
+			messages = [][]byte{message, unknownFeePayerMessage}
 		}
-		return errors.New("signature is invalid")
-
-		//log.Printf(
-		//	"SignedTransaction.Authenticator.Variant 	 txn.Authenticator.Auth = %#v \n\n",
-		//	txn.Authenticator.Variant,
-		//)
-		//
-		//log.Printf(
-		//	"SignedTransaction.Authenticator.Auth 	 txn.Authenticator.Auth = %#v \n\n",
-		//	txn.Authenticator.Auth,
-		//)
-
-		//log.Printf(
-		//	"SignedTransaction.Authenticator.Auth 	 txn.Authenticator.Auth.(*SingleSenderTransactionAuthenticator) = %#v \n\n",
-		//	txn.Authenticator.Auth.(*SingleSenderTransactionAuthenticator),
-		//)
-
-		//return errors.New("SignedTransaction.Authenticator.Auth error ")
+	default:
+		return nil, fmt.Errorf("unknown transaction authenticator %T", auth)
+	}
+	if len(secondaryAddresses) != len(secondarySigners) {
+		return nil, fmt.Errorf("%d secondary signer addresses for %d secondary signers", len(secondaryAddresses), len(secondarySigners))
+	}
+
+	signers := []transactionSignature{{role: SignerRoleSender, address: txn.Transaction.Sender, auth: sender, messages: messages}}
+	for i := range secondarySigners {
+		signers = append(signers, transactionSignature{role: SignerRoleSecondary, address: secondaryAddresses[i], auth: &secondarySigners[i], messages: messages})
+	}
+	if feePayer != nil {
+		signers = append(signers, *feePayer)
 	}
+	return signers, nil
 }
 
 // TransactionPrefix is a cached hash prefix for taking transaction hashes
@@ -0,0 +1,490 @@
+package endless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// Error codes returned by a [SponsorHandler], in the error_code field of an [api.Error]
+const (
+	SponsorErrorInvalidInput     = "invalid_input"            // The request could not be parsed
+	SponsorErrorInvalidSignature = "invalid_signature"        // A sender or secondary signer authenticator did not verify
+	SponsorErrorPolicyViolation  = "sponsor_policy_violation" // The transaction is not allowed by the [SponsorPolicy]
+	SponsorErrorQuotaExceeded    = "sponsor_quota_exceeded"   // The sender has used up its [SponsorPolicy] quota
+	SponsorErrorSubmitFailed     = "sponsor_submit_failed"    // The co-signed transaction was rejected by the node
+	SponsorErrorInternal         = "internal_error"           // The sponsor failed to co-sign the transaction
+)
+
+// SponsorRequest is the JSON body sent to a [SponsorHandler]
+type SponsorRequest struct {
+	// Transaction is the hex BCS of the fee payer [RawTransactionWithData].  The fee payer may be the sponsor, or
+	// [AccountZero] if the sender signed without knowing the sponsor.
+	Transaction string `json:"transaction"`
+	// SenderAuthenticator is the hex BCS of the sender's [crypto.AccountAuthenticator]
+	SenderAuthenticator string `json:"sender_authenticator"`
+	// SecondaryAuthenticators is the hex BCS of each secondary signer's [crypto.AccountAuthenticator], in order
+	SecondaryAuthenticators []string `json:"secondary_authenticators,omitempty"`
+	// Submit asks the sponsor to submit the transaction, rather than returning it to the caller to submit
+	Submit bool `json:"submit"`
+}
+
+// SponsorResponse is the JSON body returned by a [SponsorHandler]
+type SponsorResponse struct {
+	SignedTransaction string `json:"signed_transaction"` // SignedTransaction is the hex BCS of the co-signed [SignedTransaction]
+	Hash              string `json:"hash"`               // Hash of the signed transaction
+	Submitted         bool   `json:"submitted"`          // Submitted is true if the sponsor submitted the transaction
+}
+
+// SponsorPolicy decides which transactions a [SponsorHandler] will pay for.  Zero values disable each check.
+type SponsorPolicy struct {
+	// AllowedFunctions is a list of entry functions as "address::module::function", where function may be "*" for any
+	// function in the module.  If empty, any payload is allowed.
+	AllowedFunctions []string
+	// MaxGasAmount is the largest max gas amount allowed
+	MaxGasAmount uint64
+	// MaxGasUnitPrice is the largest gas unit price allowed
+	MaxGasUnitPrice uint64
+	// SenderQuota is the number of transactions sponsored per sender within the QuotaWindow
+	SenderQuota int
+	// QuotaWindow is the sliding window for SenderQuota, defaults to 24 hours
+	QuotaWindow time.Duration
+	// MaxExpiration is how far in the future the transaction's expiration may be
+	MaxExpiration time.Duration
+	// Check is an additional custom check, the transaction is rejected if it returns an error
+	Check func(rawTxn *RawTransaction) error
+}
+
+// checkTransaction applies the policy to a transaction, other than the sender quota
+func (policy *SponsorPolicy) checkTransaction(rawTxn *RawTransaction, now time.Time) error {
+	if len(policy.AllowedFunctions) > 0 {
+		entryFunction, ok := rawTxn.Payload.Payload.(*EntryFunction)
+		if !ok {
+			return errors.New("only entry function payloads are sponsored")
+		}
+		allowed, err := policy.allowsFunction(entryFunction)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("function %s::%s::%s is not sponsored", entryFunction.Module.Address.String(), entryFunction.Module.Name, entryFunction.Function)
+		}
+	}
+	if policy.MaxGasAmount != 0 && rawTxn.MaxGasAmount > policy.MaxGasAmount {
+		return fmt.Errorf("max gas amount %d is over the limit %d", rawTxn.MaxGasAmount, policy.MaxGasAmount)
+	}
+	if policy.MaxGasUnitPrice != 0 && rawTxn.GasUnitPrice > policy.MaxGasUnitPrice {
+		return fmt.Errorf("gas unit price %d is over the limit %d", rawTxn.GasUnitPrice, policy.MaxGasUnitPrice)
+	}
+	nowSeconds := uint64(now.Unix())
+	if rawTxn.ExpirationTimestampSeconds <= nowSeconds {
+		return errors.New("transaction has already expired")
+	}
+	if policy.MaxExpiration != 0 && rawTxn.ExpirationTimestampSeconds > nowSeconds+uint64(policy.MaxExpiration/time.Second) {
+		return fmt.Errorf("expiration is more than %s in the future", policy.MaxExpiration)
+	}
+	if policy.Check != nil {
+		return policy.Check(rawTxn)
+	}
+	return nil
+}
+
+// allowsFunction checks an entry function against the AllowedFunctions
+func (policy *SponsorPolicy) allowsFunction(entryFunction *EntryFunction) (bool, error) {
+	for _, allowed := range policy.AllowedFunctions {
+		parts := strings.Split(allowed, "::")
+		if len(parts) != 3 {
+			return false, fmt.Errorf("invalid allowed function %s", allowed)
+		}
+		address := AccountAddress{}
+		if err := address.ParseStringRelaxed(parts[0]); err != nil {
+			return false, fmt.Errorf("invalid allowed function %s: %w", allowed, err)
+		}
+		if address == entryFunction.Module.Address && parts[1] == entryFunction.Module.Name &&
+			(parts[2] == "*" || parts[2] == entryFunction.Function) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SponsorHandler is an [http.Handler] that pays for other accounts' transactions.
+//
+// A sender builds a fee payer transaction with [NodeClient.BuildTransactionMultiAgent] and [FeePayer], signs it, and
+// POSTs a [SponsorRequest].  The handler verifies the signatures, checks the [SponsorPolicy], co-signs as the fee
+// payer, and either submits the transaction or returns it to the caller.  Errors are returned as an [api.Error] with
+// one of the SponsorError codes.
+//
+//	handler := endless.NewSponsorHandler(client, sponsorAccount, endless.SponsorPolicy{
+//		AllowedFunctions: []string{"0x1::endless_account::transfer"},
+//		MaxGasAmount:     10_000,
+//		SenderQuota:      10,
+//	})
+//	http.Handle("/sponsor", handler)
+type SponsorHandler struct {
+	client   *NodeClient
+	feePayer TransactionSigner
+	policy   SponsorPolicy
+	now      func() time.Time
+
+	mutex sync.Mutex
+	usage map[AccountAddress][]time.Time
+}
+
+// NewSponsorHandler creates a [SponsorHandler] that co-signs with the feePayer, and submits to the client
+func NewSponsorHandler(client *NodeClient, feePayer TransactionSigner, policy SponsorPolicy) *SponsorHandler {
+	if policy.QuotaWindow == 0 {
+		policy.QuotaWindow = 24 * time.Hour
+	}
+	return &SponsorHandler{
+		client:   client,
+		feePayer: feePayer,
+		policy:   policy,
+		now:      time.Now,
+		usage:    make(map[AccountAddress][]time.Time),
+	}
+}
+
+// ServeHTTP handles a [SponsorRequest]
+//
+// Implements:
+//   - [http.Handler]
+func (sh *SponsorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sh.writeError(w, http.StatusMethodNotAllowed, SponsorErrorInvalidInput, "method not allowed")
+		return
+	}
+	request := SponsorRequest{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&request); err != nil {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidInput, err.Error())
+		return
+	}
+	rawTxn, senderAuth, secondaryAuths, err := request.decode()
+	if err != nil {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidInput, err.Error())
+		return
+	}
+	feePayerTxn := rawTxn.Inner.(*MultiAgentWithFeePayerRawTransactionWithData)
+	sponsorAddress := sh.feePayer.AccountAddress()
+	if *feePayerTxn.FeePayer != AccountZero && *feePayerTxn.FeePayer != sponsorAddress {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidInput, "fee payer is not this sponsor")
+		return
+	}
+	if sh.client.chainId != 0 && feePayerTxn.RawTxn.ChainId != sh.client.chainId {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidInput, fmt.Sprintf("chain id %d does not match %d", feePayerTxn.RawTxn.ChainId, sh.client.chainId))
+		return
+	}
+
+	// The sender signed the transaction as it was sent, before the sponsor is filled in
+	message, err := rawTxn.SigningMessage()
+	if err != nil {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidInput, err.Error())
+		return
+	}
+	if !senderAuth.Verify(message) {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidSignature, "sender signature is invalid")
+		return
+	}
+	if len(secondaryAuths) != len(feePayerTxn.SecondarySigners) {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidSignature, fmt.Sprintf("expected %d secondary authenticators, got %d", len(feePayerTxn.SecondarySigners), len(secondaryAuths)))
+		return
+	}
+	for i, secondaryAuth := range secondaryAuths {
+		if !secondaryAuth.Verify(message) {
+			sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidSignature, fmt.Sprintf("secondary signer %d signature is invalid", i))
+			return
+		}
+	}
+
+	// A valid signature is only meaningful if it's from a key of the signing account
+	if err = sh.authorized(feePayerTxn.RawTxn.Sender, senderAuth); err != nil {
+		sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidSignature, fmt.Sprintf("sender: %s", err))
+		return
+	}
+	for i, secondaryAuth := range secondaryAuths {
+		if err = sh.authorized(feePayerTxn.SecondarySigners[i], secondaryAuth); err != nil {
+			sh.writeError(w, http.StatusBadRequest, SponsorErrorInvalidSignature, fmt.Sprintf("secondary signer %d: %s", i, err))
+			return
+		}
+	}
+
+	now := sh.now()
+	if err = sh.policy.checkTransaction(feePayerTxn.RawTxn, now); err != nil {
+		sh.writeError(w, http.StatusForbidden, SponsorErrorPolicyViolation, err.Error())
+		return
+	}
+	sender := feePayerTxn.RawTxn.Sender
+	if !sh.reserve(sender, now) {
+		sh.writeError(w, http.StatusTooManyRequests, SponsorErrorQuotaExceeded, "sender quota exceeded")
+		return
+	}
+
+	signedTxn, err := sh.coSign(rawTxn, senderAuth, secondaryAuths)
+	if err != nil {
+		sh.release(sender, now)
+		sh.writeError(w, http.StatusInternalServerError, SponsorErrorInternal, err.Error())
+		return
+	}
+	response := SponsorResponse{}
+	response.Hash, err = signedTxn.Hash()
+	if err != nil {
+		sh.release(sender, now)
+		sh.writeError(w, http.StatusInternalServerError, SponsorErrorInternal, err.Error())
+		return
+	}
+	signedBytes, err := bcs.Serialize(signedTxn)
+	if err != nil {
+		sh.release(sender, now)
+		sh.writeError(w, http.StatusInternalServerError, SponsorErrorInternal, err.Error())
+		return
+	}
+	response.SignedTransaction = BytesToHex(signedBytes)
+
+	if request.Submit {
+		if _, err = sh.client.SubmitTransaction(signedTxn); err != nil {
+			sh.release(sender, now)
+			message := err.Error()
+			if apiError, ok := ApiErrorFromError(err); ok {
+				message = apiError.Message
+			}
+			sh.writeError(w, http.StatusBadGateway, SponsorErrorSubmitFailed, message)
+			return
+		}
+		response.Submitted = true
+	}
+	sh.writeJson(w, http.StatusOK, response)
+}
+
+// coSign fills in the sponsor as fee payer, and signs the transaction
+func (sh *SponsorHandler) coSign(rawTxn *RawTransactionWithData, senderAuth *crypto.AccountAuthenticator, secondaryAuths []*crypto.AccountAuthenticator) (*SignedTransaction, error) {
+	if !rawTxn.SetFeePayer(sh.feePayer.AccountAddress()) {
+		return nil, errors.New("failed to set fee payer")
+	}
+	feePayerAuth, err := rawTxn.Sign(sh.feePayer)
+	if err != nil {
+		return nil, err
+	}
+	additionalSigners := make([]crypto.AccountAuthenticator, len(secondaryAuths))
+	for i, secondaryAuth := range secondaryAuths {
+		additionalSigners[i] = *secondaryAuth
+	}
+	signedTxn, ok := rawTxn.ToFeePayerSignedTransaction(senderAuth, feePayerAuth, additionalSigners)
+	if !ok {
+		return nil, errors.New("failed to build fee payer signed transaction")
+	}
+	return signedTxn, nil
+}
+
+// authorized checks that every key in the authenticator is an authentication key of the account.  An account that
+// doesn't exist on-chain yet only has the key its address was derived from.
+func (sh *SponsorHandler) authorized(address AccountAddress, auth *crypto.AccountAuthenticator) error {
+	var authKeys []*crypto.AuthenticationKey
+	if multiAuthKey, ok := auth.Auth.(*crypto.MultiAuthKeyAuthenticator); ok {
+		for _, pubKey := range multiAuthKey.PubKeys {
+			authKeys = append(authKeys, pubKey.AuthKey())
+		}
+	} else if pubKey := auth.PubKey(); pubKey != nil {
+		authKeys = append(authKeys, pubKey.AuthKey())
+	}
+	if len(authKeys) == 0 {
+		return errors.New("authenticator has no public keys")
+	}
+
+	info, err := sh.client.Account(address)
+	if err != nil {
+		var httpError *HttpError
+		if !errors.As(err, &httpError) || httpError.StatusCode != http.StatusNotFound {
+			return err
+		}
+		derived := AccountAddress{}
+		derived.FromAuthKey(authKeys[0])
+		if len(authKeys) != 1 || derived != address {
+			return errors.New("authenticator does not match the address")
+		}
+		return nil
+	}
+
+	accountKeys, err := info.AuthenticationKey()
+	if err != nil {
+		return err
+	}
+	for _, authKey := range authKeys {
+		if !slices.ContainsFunc(accountKeys, func(accountKey []byte) bool { return bytes.Equal(accountKey, authKey[:]) }) {
+			return errors.New("authenticator key is not an authentication key of the account")
+		}
+	}
+	if len(authKeys) < info.NumSignaturesRequired {
+		return fmt.Errorf("%d signatures required, got %d", info.NumSignaturesRequired, len(authKeys))
+	}
+	return nil
+}
+
+// reserve takes one from the sender's quota, returning false if there is none left
+func (sh *SponsorHandler) reserve(sender AccountAddress, now time.Time) bool {
+	if sh.policy.SenderQuota <= 0 {
+		return true
+	}
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+	cutoff := now.Add(-sh.policy.QuotaWindow)
+	used := sh.usage[sender]
+	for len(used) > 0 && !used[0].After(cutoff) {
+		used = used[1:]
+	}
+	if len(used) >= sh.policy.SenderQuota {
+		sh.usage[sender] = used
+		return false
+	}
+	sh.usage[sender] = append(used, now)
+	return true
+}
+
+// release gives back a reservation from the sender's quota, for a transaction that was not sponsored
+func (sh *SponsorHandler) release(sender AccountAddress, reservedAt time.Time) {
+	if sh.policy.SenderQuota <= 0 {
+		return
+	}
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+	used := sh.usage[sender]
+	for i := len(used) - 1; i >= 0; i-- {
+		if used[i].Equal(reservedAt) {
+			sh.usage[sender] = append(used[:i], used[i+1:]...)
+			return
+		}
+	}
+}
+
+func (sh *SponsorHandler) writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (sh *SponsorHandler) writeError(w http.ResponseWriter, statusCode int, errorCode string, message string) {
+	sh.writeJson(w, statusCode, api.Error{Message: message, ErrorCode: errorCode})
+}
+
+// decode parses the hex BCS fields of the request
+func (request *SponsorRequest) decode() (*RawTransactionWithData, *crypto.AccountAuthenticator, []*crypto.AccountAuthenticator, error) {
+	txnBytes, err := ParseHex(request.Transaction)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	rawTxn := &RawTransactionWithData{}
+	if err = bcs.Deserialize(rawTxn, txnBytes); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if rawTxn.Variant != MultiAgentWithFeePayerRawTransactionWithDataVariant {
+		return nil, nil, nil, errors.New("transaction is not a fee payer transaction")
+	}
+	senderAuth, err := decodeAccountAuthenticator(request.SenderAuthenticator)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid sender authenticator: %w", err)
+	}
+	secondaryAuths := make([]*crypto.AccountAuthenticator, len(request.SecondaryAuthenticators))
+	for i, secondary := range request.SecondaryAuthenticators {
+		secondaryAuths[i], err = decodeAccountAuthenticator(secondary)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid secondary authenticator %d: %w", i, err)
+		}
+	}
+	return rawTxn, senderAuth, secondaryAuths, nil
+}
+
+func decodeAccountAuthenticator(hexString string) (*crypto.AccountAuthenticator, error) {
+	authBytes, err := ParseHex(hexString)
+	if err != nil {
+		return nil, err
+	}
+	auth := &crypto.AccountAuthenticator{}
+	if err = bcs.Deserialize(auth, authBytes); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// SponsorClient requests sponsorship from a [SponsorHandler]
+type SponsorClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewSponsorClient creates a [SponsorClient] for the sponsor service at url.  If httpClient is nil,
+// [http.DefaultClient] is used.
+func NewSponsorClient(url string, httpClient *http.Client) *SponsorClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &SponsorClient{url: url, client: httpClient}
+}
+
+// Sponsor sends a sender signed fee payer transaction to the sponsor service.  If submit is true, the sponsor submits
+// the transaction, otherwise the caller should submit the returned [SignedTransaction].
+//
+// Errors from the service are returned as [HttpError], use [ApiErrorFromError] to get the SponsorError code.
+func (sc *SponsorClient) Sponsor(rawTxn *RawTransactionWithData, senderAuth *crypto.AccountAuthenticator, secondaryAuths []*crypto.AccountAuthenticator, submit bool) (*SignedTransaction, *SponsorResponse, error) {
+	txnBytes, err := bcs.Serialize(rawTxn)
+	if err != nil {
+		return nil, nil, err
+	}
+	senderBytes, err := bcs.Serialize(senderAuth)
+	if err != nil {
+		return nil, nil, err
+	}
+	request := SponsorRequest{
+		Transaction:         BytesToHex(txnBytes),
+		SenderAuthenticator: BytesToHex(senderBytes),
+		Submit:              submit,
+	}
+	for _, secondaryAuth := range secondaryAuths {
+		secondaryBytes, err := bcs.Serialize(secondaryAuth)
+		if err != nil {
+			return nil, nil, err
+		}
+		request.SecondaryAuthenticators = append(request.SecondaryAuthenticators, BytesToHex(secondaryBytes))
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpResponse, err := sc.client.Post(sc.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("POST %s, %w", sc.url, err)
+	}
+	if httpResponse.StatusCode >= 400 {
+		return nil, nil, NewHttpError(httpResponse)
+	}
+	defer httpResponse.Body.Close()
+	response := &SponsorResponse{}
+	if err = json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		return nil, nil, fmt.Errorf("error getting response data, %w", err)
+	}
+
+	signedBytes, err := ParseHex(response.SignedTransaction)
+	if err != nil {
+		return nil, nil, err
+	}
+	signedTxn := &SignedTransaction{}
+	if err = bcs.Deserialize(signedTxn, signedBytes); err != nil {
+		return nil, nil, err
+	}
+	return signedTxn, response, nil
+}
+
+// NewSponsorHandler creates a [SponsorHandler] that submits to this client, see [NewSponsorHandler]
+func (client *Client) NewSponsorHandler(feePayer TransactionSigner, policy SponsorPolicy) *SponsorHandler {
+	return NewSponsorHandler(client.nodeClient, feePayer, policy)
+}
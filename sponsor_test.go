@@ -0,0 +1,170 @@
+package endless
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sponsorTest struct {
+	node    *testNode
+	sponsor *Account
+	sender  *Account
+	client  *SponsorClient
+}
+
+func newSponsorTest(t *testing.T, policy SponsorPolicy) *sponsorTest {
+	node := newTestNode(t)
+	sponsor, err := NewEd25519Account()
+	assert.NoError(t, err)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(NewSponsorHandler(node.client, sponsor, policy))
+	t.Cleanup(server.Close)
+	return &sponsorTest{node: node, sponsor: sponsor, sender: sender, client: NewSponsorClient(server.URL, server.Client())}
+}
+
+func (st *sponsorTest) buildAndSign(t *testing.T, feePayer AccountAddress, options ...any) (*RawTransactionWithData, *SponsorClient) {
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 100)
+	assert.NoError(t, err)
+	options = append([]any{FeePayer(&feePayer), SequenceNumber(0), GasUnitPrice(100), ExpirationSeconds(time.Now().Unix() + 60)}, options...)
+	rawTxn, err := st.node.client.BuildTransactionMultiAgent(st.sender.Address, TransactionPayload{Payload: entryFunction}, options...)
+	assert.NoError(t, err)
+	return rawTxn, st.client
+}
+
+func sponsorErrorCode(t *testing.T, err error) string {
+	apiError, ok := ApiErrorFromError(err)
+	assert.True(t, ok)
+	return apiError.ErrorCode
+}
+
+func TestSponsorHandler_SubmitUnknownSponsor(t *testing.T) {
+	st := newSponsorTest(t, SponsorPolicy{AllowedFunctions: []string{"0x1::endless_account::*"}})
+	rawTxn, client := st.buildAndSign(t, AccountZero)
+	senderAuth, err := rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+
+	signedTxn, response, err := client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, response.Submitted)
+	hash, err := signedTxn.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, response.Hash, hash)
+
+	submitted := st.node.Submitted()
+	assert.Len(t, submitted, 1)
+	feePayerAuth := submitted[0].Authenticator.Auth.(*FeePayerTransactionAuthenticator)
+	assert.Equal(t, st.sponsor.Address, *feePayerAuth.FeePayer)
+}
+
+func TestSponsorHandler_ReturnWithoutSubmit(t *testing.T) {
+	st := newSponsorTest(t, SponsorPolicy{})
+	// The account exists on-chain, so its authentication keys are checked
+	st.node.SetAccount(st.sender.Address, 0, BytesToHex(st.sender.AuthKey()[:]))
+	rawTxn, client := st.buildAndSign(t, st.sponsor.Address)
+	senderAuth, err := rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+
+	signedTxn, response, err := client.Sponsor(rawTxn, senderAuth, nil, false)
+	assert.NoError(t, err)
+	assert.False(t, response.Submitted)
+	assert.Equal(t, st.sender.Address, signedTxn.Transaction.Sender)
+	assert.Empty(t, st.node.Submitted())
+
+	// The caller can submit it themselves
+	_, err = st.node.client.SubmitTransaction(signedTxn)
+	assert.NoError(t, err)
+}
+
+func TestSponsorHandler_Rejections(t *testing.T) {
+	st := newSponsorTest(t, SponsorPolicy{
+		AllowedFunctions: []string{"0x1::endless_account::transfer"},
+		MaxGasAmount:     1000,
+		MaxExpiration:    5 * time.Minute,
+	})
+
+	// Signed by the wrong account
+	rawTxn, client := st.buildAndSign(t, AccountZero, MaxGasAmount(1000))
+	otherAuth, err := rawTxn.Sign(st.sponsor)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, otherAuth, nil, true)
+	assert.Equal(t, SponsorErrorInvalidSignature, sponsorErrorCode(t, err))
+
+	// Signed by a key that isn't on the existing account
+	st.node.SetAccount(st.sender.Address, 0, BytesToHex(st.sponsor.AuthKey()[:]))
+	rawTxn, client = st.buildAndSign(t, AccountZero, MaxGasAmount(1000))
+	senderAuth, err := rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorInvalidSignature, sponsorErrorCode(t, err))
+	st.node.SetAccount(st.sender.Address, 0, BytesToHex(st.sender.AuthKey()[:]))
+
+	// Too much gas
+	rawTxn, client = st.buildAndSign(t, AccountZero, MaxGasAmount(1001))
+	senderAuth, err = rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorPolicyViolation, sponsorErrorCode(t, err))
+
+	// Expiration too far out
+	rawTxn, client = st.buildAndSign(t, AccountZero, MaxGasAmount(1000), ExpirationSeconds(time.Now().Unix()+3600))
+	senderAuth, err = rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorPolicyViolation, sponsorErrorCode(t, err))
+
+	// Fee payer is someone else
+	rawTxn, client = st.buildAndSign(t, AccountOne, MaxGasAmount(1000))
+	senderAuth, err = rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorInvalidInput, sponsorErrorCode(t, err))
+
+	assert.Empty(t, st.node.Submitted())
+}
+
+func TestSponsorHandler_FunctionNotAllowed(t *testing.T) {
+	st := newSponsorTest(t, SponsorPolicy{AllowedFunctions: []string{"0x1::endless_account::create_account"}})
+	rawTxn, client := st.buildAndSign(t, AccountZero)
+	senderAuth, err := rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorPolicyViolation, sponsorErrorCode(t, err))
+}
+
+func TestSponsorHandler_Quota(t *testing.T) {
+	st := newSponsorTest(t, SponsorPolicy{SenderQuota: 2})
+	submitHookCalls := 0
+	st.node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		submitHookCalls++
+		if submitHookCalls == 1 {
+			return http.StatusBadRequest, map[string]any{"message": "mempool is full", "error_code": "mempool_is_full"}
+		}
+		return 0, nil
+	}
+
+	for i := uint64(0); i < 3; i++ {
+		rawTxn, client := st.buildAndSign(t, AccountZero, SequenceNumber(i))
+		senderAuth, err := rawTxn.Sign(st.sender)
+		assert.NoError(t, err)
+		_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+		if i == 0 {
+			// A failed submission doesn't use up the quota
+			assert.Equal(t, SponsorErrorSubmitFailed, sponsorErrorCode(t, err))
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+
+	rawTxn, client := st.buildAndSign(t, AccountZero, SequenceNumber(3))
+	senderAuth, err := rawTxn.Sign(st.sender)
+	assert.NoError(t, err)
+	_, _, err = client.Sponsor(rawTxn, senderAuth, nil, true)
+	assert.Equal(t, SponsorErrorQuotaExceeded, sponsorErrorCode(t, err))
+	assert.Len(t, st.node.Submitted(), 2)
+}
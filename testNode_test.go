@@ -0,0 +1,276 @@
+package endless
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+/* This is a minimal in-memory node API, used for testing client side logic without a network */
+
+const testNodeChainId = uint8(4)
+
+type testNodeTxn struct {
+	Txn       *SignedTransaction
+	Hash      string
+	Committed bool
+	Success   bool
+	VmStatus  string
+	Version   uint64
+}
+
+type testNode struct {
+	t      *testing.T
+	server *httptest.Server
+	client *NodeClient
+
+	mutex           sync.Mutex
+	accounts        map[AccountAddress]*AccountInfo
+	txns            map[string]*testNodeTxn
+	submitted       []*testNodeTxn
+	version         uint64
+	ledgerTimestamp uint64 // Microseconds
+
+	// AutoCommit commits accepted transactions as soon as they're next in the sender's sequence, like the mempool
+	AutoCommit bool
+	// SubmitHook can reject a submitted transaction by returning a non-nil error body and status code
+	SubmitHook func(txn *SignedTransaction) (statusCode int, body map[string]any)
+	// GasEstimate is returned from estimate_gas_price
+	GasEstimate EstimateGasInfo
+	// Handlers overrides routes by "METHOD /path", the path is relative to the API root
+	Handlers map[string]http.HandlerFunc
+}
+
+func newTestNode(t *testing.T) *testNode {
+	node := &testNode{
+		t:               t,
+		accounts:        make(map[AccountAddress]*AccountInfo),
+		txns:            make(map[string]*testNodeTxn),
+		ledgerTimestamp: 1_700_000_000_000_000,
+		GasEstimate:     EstimateGasInfo{DeprioritizedGasEstimate: 100, GasEstimate: 150, PrioritizedGasEstimate: 200},
+		Handlers:        make(map[string]http.HandlerFunc),
+	}
+	node.server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	t.Cleanup(node.server.Close)
+
+	client, err := NewNodeClientWithHttpClient(node.server.URL+"/v1", testNodeChainId, node.server.Client())
+	assert.NoError(t, err)
+	node.client = client
+	return node
+}
+
+// SetAccount sets the on-chain state of an account
+func (node *testNode) SetAccount(address AccountAddress, sequenceNumber uint64, authKeys ...string) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	if authKeys == nil {
+		authKeys = []string{}
+	}
+	node.accounts[address] = &AccountInfo{
+		SequenceNumberStr:     strconv.FormatUint(sequenceNumber, 10),
+		AuthenticationKeyHex:  authKeys,
+		NumSignaturesRequired: 1,
+	}
+}
+
+// SetLedgerTimestamp sets the ledger timestamp in seconds
+func (node *testNode) SetLedgerTimestamp(seconds uint64) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	node.ledgerTimestamp = seconds * 1_000_000
+}
+
+// Commit commits a previously submitted transaction
+func (node *testNode) Commit(hash string, success bool, vmStatus string) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	node.commitLocked(node.txns[hash], success, vmStatus)
+}
+
+// Submitted returns all accepted transactions in order of submission
+func (node *testNode) Submitted() []*SignedTransaction {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+	out := make([]*SignedTransaction, len(node.submitted))
+	for i, txn := range node.submitted {
+		out[i] = txn.Txn
+	}
+	return out
+}
+
+func (node *testNode) commitLocked(txn *testNodeTxn, success bool, vmStatus string) {
+	node.version++
+	txn.Committed = true
+	txn.Success = success
+	txn.VmStatus = vmStatus
+	txn.Version = node.version
+	sender := txn.Txn.Transaction.Sender
+	account, ok := node.accounts[sender]
+	if !ok {
+		account = &AccountInfo{SequenceNumberStr: "0", AuthenticationKeyHex: []string{}, NumSignaturesRequired: 1}
+		node.accounts[sender] = account
+	}
+	sequenceNumber, _ := account.SequenceNumber()
+	if txn.Txn.Transaction.SequenceNumber >= sequenceNumber {
+		account.SequenceNumberStr = strconv.FormatUint(txn.Txn.Transaction.SequenceNumber+1, 10)
+	}
+}
+
+// commitReadyLocked commits pending transactions from the sender, in order of sequence number, until there is a gap
+func (node *testNode) commitReadyLocked(sender AccountAddress) {
+	for {
+		sequenceNumber := uint64(0)
+		if account, ok := node.accounts[sender]; ok {
+			sequenceNumber, _ = account.SequenceNumber()
+		}
+		committed := false
+		for _, txn := range node.submitted {
+			raw := txn.Txn.Transaction
+			if !txn.Committed && raw.Sender == sender && raw.SequenceNumber == sequenceNumber {
+				node.commitLocked(txn, true, "Executed successfully")
+				committed = true
+				break
+			}
+		}
+		if !committed {
+			return
+		}
+	}
+}
+
+func (node *testNode) writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (node *testNode) writeError(w http.ResponseWriter, statusCode int, message string, errorCode string, vmErrorCode uint64) {
+	node.writeJson(w, statusCode, map[string]any{
+		"message":       message,
+		"error_code":    errorCode,
+		"vm_error_code": vmErrorCode,
+	})
+}
+
+func (node *testNode) txnJson(txn *testNodeTxn) map[string]any {
+	raw := txn.Txn.Transaction
+	out := map[string]any{
+		"type":                      "pending_transaction",
+		"hash":                      txn.Hash,
+		"sender":                    raw.Sender.String(),
+		"sequence_number":           strconv.FormatUint(raw.SequenceNumber, 10),
+		"max_gas_amount":            strconv.FormatUint(raw.MaxGasAmount, 10),
+		"gas_unit_price":            strconv.FormatUint(raw.GasUnitPrice, 10),
+		"expiration_timestamp_secs": strconv.FormatUint(raw.ExpirationTimestampSeconds, 10),
+	}
+	if txn.Committed {
+		out["type"] = "user_transaction"
+		out["version"] = strconv.FormatUint(txn.Version, 10)
+		out["success"] = txn.Success
+		out["vm_status"] = txn.VmStatus
+		out["gas_used"] = "1"
+		out["timestamp"] = strconv.FormatUint(node.ledgerTimestamp, 10)
+		out["changes"] = []any{}
+		out["events"] = []any{}
+	}
+	return out
+}
+
+// accept records a submitted transaction that passed the SubmitHook
+func (node *testNode) accept(signedTxn *SignedTransaction) *testNodeTxn {
+	hash, err := signedTxn.Hash()
+	assert.NoError(node.t, err)
+	txn := &testNodeTxn{Txn: signedTxn, Hash: hash}
+	node.txns[hash] = txn
+	node.submitted = append(node.submitted, txn)
+	if node.AutoCommit {
+		node.commitReadyLocked(signedTxn.Transaction.Sender)
+	}
+	return txn
+}
+
+func (node *testNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	if handler, ok := node.Handlers[r.Method+" "+path]; ok {
+		handler(w, r)
+		return
+	}
+
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && (path == "" || path == "/"):
+		node.writeJson(w, http.StatusOK, map[string]any{
+			"chain_id":         testNodeChainId,
+			"epoch":            "1",
+			"ledger_version":   strconv.FormatUint(node.version, 10),
+			"ledger_timestamp": strconv.FormatUint(node.ledgerTimestamp, 10),
+			"block_height":     "1",
+		})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/accounts/"):
+		address := AccountAddress{}
+		if err := address.ParseStringRelaxed(strings.TrimPrefix(path, "/accounts/")); err != nil {
+			node.writeError(w, http.StatusBadRequest, err.Error(), "invalid_input", 0)
+			return
+		}
+		account, ok := node.accounts[address]
+		if !ok {
+			node.writeError(w, http.StatusNotFound, "Account not found", "account_not_found", 0)
+			return
+		}
+		node.writeJson(w, http.StatusOK, account)
+	case r.Method == http.MethodGet && path == "/estimate_gas_price":
+		node.writeJson(w, http.StatusOK, node.GasEstimate)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/transactions/by_hash/"):
+		txn, ok := node.txns[strings.TrimPrefix(path, "/transactions/by_hash/")]
+		if !ok {
+			node.writeError(w, http.StatusNotFound, "Transaction not found", "transaction_not_found", 0)
+			return
+		}
+		node.writeJson(w, http.StatusOK, node.txnJson(txn))
+	case r.Method == http.MethodPost && path == "/transactions":
+		body, _ := io.ReadAll(r.Body)
+		signedTxn := &SignedTransaction{}
+		if err := bcs.Deserialize(signedTxn, body); err != nil {
+			node.writeError(w, http.StatusBadRequest, err.Error(), "invalid_input", 0)
+			return
+		}
+		if node.SubmitHook != nil {
+			if statusCode, errBody := node.SubmitHook(signedTxn); errBody != nil {
+				node.writeJson(w, statusCode, errBody)
+				return
+			}
+		}
+		node.writeJson(w, http.StatusAccepted, node.txnJson(node.accept(signedTxn)))
+	case r.Method == http.MethodPost && path == "/transactions/batch":
+		body, _ := io.ReadAll(r.Body)
+		des := bcs.NewDeserializer(body)
+		signedTxns := bcs.DeserializeSequence[SignedTransaction](des)
+		if des.Error() != nil {
+			node.writeError(w, http.StatusBadRequest, des.Error().Error(), "invalid_input", 0)
+			return
+		}
+		failures := make([]map[string]any, 0)
+		for i := range signedTxns {
+			if node.SubmitHook != nil {
+				if _, errBody := node.SubmitHook(&signedTxns[i]); errBody != nil {
+					failures = append(failures, map[string]any{"error": errBody, "transaction_index": i})
+					continue
+				}
+			}
+			node.accept(&signedTxns[i])
+		}
+		node.writeJson(w, http.StatusAccepted, map[string]any{"transaction_failures": failures})
+	default:
+		node.writeError(w, http.StatusNotFound, "not found: "+r.Method+" "+path, "web_framework_error", 0)
+	}
+}
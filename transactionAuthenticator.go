@@ -158,7 +158,7 @@ func (ea *MultiEd25519TransactionAuthenticator) Verify(msg []byte) bool {
 //region MultiEd25519TransactionAuthenticator bcs.Struct
 
 func (ea *MultiEd25519TransactionAuthenticator) MarshalBCS(ser *bcs.Serializer) {
-	ea.Sender.MarshalBCS(ser)
+	ea.Sender.Auth.MarshalBCS(ser)
 }
 
 func (ea *MultiEd25519TransactionAuthenticator) UnmarshalBCS(des *bcs.Deserializer) {
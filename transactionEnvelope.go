@@ -0,0 +1,585 @@
+package endless
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+// TransactionEnvelopeVersion is the version of the [TransactionEnvelope] serialization
+const TransactionEnvelopeVersion uint8 = 1
+
+// EnvelopeSignerRole is the part a signer plays in a transaction
+type EnvelopeSignerRole uint8
+
+const (
+	EnvelopeSignerSender    EnvelopeSignerRole = 0 // EnvelopeSignerSender is the sender of the transaction
+	EnvelopeSignerSecondary EnvelopeSignerRole = 1 // EnvelopeSignerSecondary is a secondary signer of a multi-agent transaction
+	EnvelopeSignerFeePayer  EnvelopeSignerRole = 2 // EnvelopeSignerFeePayer is the fee payer of a sponsored transaction
+)
+
+// String returns a readable name of the EnvelopeSignerRole
+func (role EnvelopeSignerRole) String() string {
+	switch role {
+	case EnvelopeSignerSender:
+		return "sender"
+	case EnvelopeSignerSecondary:
+		return "secondary"
+	case EnvelopeSignerFeePayer:
+		return "fee_payer"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(role))
+	}
+}
+
+// EnvelopeThreshold is an option to [NewTransactionEnvelope], setting how many authenticators a signer needs.  A
+// threshold above one is for a multi auth key account, and the authenticators are combined into a
+// [crypto.MultiAuthKeyAuthenticator].
+type EnvelopeThreshold struct {
+	Address   AccountAddress
+	Threshold uint8
+}
+
+// EnvelopeSigner is a signer required by a [TransactionEnvelope], and the authenticators collected for it so far
+type EnvelopeSigner struct {
+	Address        AccountAddress                 // Address of the signer
+	Role           EnvelopeSignerRole             // Role of the signer in the transaction
+	Threshold      uint8                          // Threshold is the number of authenticators required
+	Authenticators []*crypto.AccountAuthenticator // Authenticators collected so far, ordered by authentication key
+}
+
+// Complete returns true if the signer has enough authenticators
+func (signer *EnvelopeSigner) Complete() bool {
+	return len(signer.Authenticators) >= int(signer.Threshold)
+}
+
+// TransactionEnvelope carries an unsigned transaction between parties while signatures are collected, like a PSBT.
+//
+// Each party decodes the envelope, signs it with [TransactionEnvelope.Sign], and passes it on, or sends back their
+// copy to be combined with [TransactionEnvelope.Merge].  Every authenticator is checked against the transaction's
+// signing message when it is added.  Once every signer is complete, [TransactionEnvelope.Finalize] returns the
+// [SignedTransaction] to submit.
+//
+//	envelope, err := NewTransactionEnvelope(rawTxnWithData)
+//	encoded, err := envelope.Base64()
+//	// ... on another machine
+//	envelope, err := ParseTransactionEnvelope(encoded)
+//	err = envelope.Sign(account)
+//
+// It serializes to BCS, base64 of the BCS with [TransactionEnvelope.Base64], or JSON.
+type TransactionEnvelope struct {
+	Version     uint8              // Version of the envelope format, [TransactionEnvelopeVersion]
+	Transaction RawTransactionImpl // Transaction is a [RawTransaction] or [RawTransactionWithData]
+	Signers     []*EnvelopeSigner  // Signers in order of sender, secondary signers, fee payer
+}
+
+// NewTransactionEnvelope creates an envelope for a [RawTransaction] or [RawTransactionWithData]
+//
+// Accepts options:
+//   - [EnvelopeThreshold] one per multi auth key signer
+func NewTransactionEnvelope(txn RawTransactionImpl, options ...any) (*TransactionEnvelope, error) {
+	envelope := &TransactionEnvelope{Version: TransactionEnvelopeVersion, Transaction: txn}
+	signers, err := envelope.requiredSigners()
+	if err != nil {
+		return nil, err
+	}
+	envelope.Signers = signers
+	for i, option := range options {
+		switch ovalue := option.(type) {
+		case EnvelopeThreshold:
+			signer := envelope.Signer(ovalue.Address)
+			if signer == nil {
+				return nil, fmt.Errorf("NewTransactionEnvelope arg [%d] %s is not a signer", i+2, ovalue.Address.String())
+			}
+			if ovalue.Threshold == 0 {
+				return nil, fmt.Errorf("NewTransactionEnvelope arg [%d] threshold must be at least 1", i+2)
+			}
+			signer.Threshold = ovalue.Threshold
+		default:
+			return nil, fmt.Errorf("NewTransactionEnvelope arg [%d] unknown option type %T", i+2, option)
+		}
+	}
+	return envelope, nil
+}
+
+// requiredSigners lists the signers of the transaction, each with a threshold of one
+func (envelope *TransactionEnvelope) requiredSigners() ([]*EnvelopeSigner, error) {
+	switch txn := envelope.Transaction.(type) {
+	case *RawTransaction:
+		return []*EnvelopeSigner{{Address: txn.Sender, Role: EnvelopeSignerSender, Threshold: 1}}, nil
+	case *RawTransactionWithData:
+		var rawTxn *RawTransaction
+		var secondarySigners []AccountAddress
+		var feePayer *AccountAddress
+		switch inner := txn.Inner.(type) {
+		case *MultiAgentRawTransactionWithData:
+			rawTxn = inner.RawTxn
+			secondarySigners = inner.SecondarySigners
+		case *MultiAgentWithFeePayerRawTransactionWithData:
+			rawTxn = inner.RawTxn
+			secondarySigners = inner.SecondarySigners
+			feePayer = inner.FeePayer
+		default:
+			return nil, fmt.Errorf("unknown RawTransactionWithData variant %d", txn.Variant)
+		}
+		signers := []*EnvelopeSigner{{Address: rawTxn.Sender, Role: EnvelopeSignerSender, Threshold: 1}}
+		for _, secondary := range secondarySigners {
+			signers = append(signers, &EnvelopeSigner{Address: secondary, Role: EnvelopeSignerSecondary, Threshold: 1})
+		}
+		if feePayer != nil {
+			signers = append(signers, &EnvelopeSigner{Address: *feePayer, Role: EnvelopeSignerFeePayer, Threshold: 1})
+		}
+		return signers, nil
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %T", envelope.Transaction)
+	}
+}
+
+// Signer returns the required signer with the address, or nil if there is none
+func (envelope *TransactionEnvelope) Signer(address AccountAddress) *EnvelopeSigner {
+	for _, signer := range envelope.Signers {
+		if signer.Address == address {
+			return signer
+		}
+	}
+	return nil
+}
+
+// Missing returns the addresses of signers that still need authenticators
+func (envelope *TransactionEnvelope) Missing() []AccountAddress {
+	missing := make([]AccountAddress, 0)
+	for _, signer := range envelope.Signers {
+		if !signer.Complete() {
+			missing = append(missing, signer.Address)
+		}
+	}
+	return missing
+}
+
+// Complete returns true if every signer has enough authenticators to finalize
+func (envelope *TransactionEnvelope) Complete() bool {
+	return len(envelope.Missing()) == 0
+}
+
+// SetFeePayer fills in the fee payer of a fee payer transaction built with [AccountZero] as the fee payer.  Signatures
+// already collected over the transaction without a fee payer stay valid.
+func (envelope *TransactionEnvelope) SetFeePayer(feePayer AccountAddress) error {
+	txn, ok := envelope.Transaction.(*RawTransactionWithData)
+	if !ok || txn.Variant != MultiAgentWithFeePayerRawTransactionWithDataVariant {
+		return errors.New("transaction is not a fee payer transaction")
+	}
+	inner := txn.Inner.(*MultiAgentWithFeePayerRawTransactionWithData)
+	if *inner.FeePayer != AccountZero {
+		return fmt.Errorf("fee payer is already set to %s", inner.FeePayer.String())
+	}
+	txn.SetFeePayer(feePayer)
+	for _, signer := range envelope.Signers {
+		if signer.Role == EnvelopeSignerFeePayer {
+			signer.Address = feePayer
+			signer.Authenticators = nil
+		}
+	}
+	return nil
+}
+
+// Sign signs the transaction, and adds the authenticator for the signer's address
+func (envelope *TransactionEnvelope) Sign(signer TransactionSigner) error {
+	auth, err := envelope.Transaction.Sign(signer)
+	if err != nil {
+		return err
+	}
+	return envelope.AddAuthenticator(signer.AccountAddress(), auth)
+}
+
+// AddAuthenticator verifies an authenticator against the transaction's signing message, and adds it for the address.
+// Adding an authenticator for a key that has already signed is a no-op.
+func (envelope *TransactionEnvelope) AddAuthenticator(address AccountAddress, auth *crypto.AccountAuthenticator) error {
+	signer := envelope.Signer(address)
+	if signer == nil {
+		return fmt.Errorf("%s is not a signer of the transaction", address.String())
+	}
+	if auth == nil || auth.Auth == nil || auth.Variant == crypto.AccountAuthenticatorMultiAuthKey {
+		return errors.New("authenticator must be a single signer's authenticator")
+	}
+	pubKey := auth.PubKey()
+	if pubKey == nil {
+		return errors.New("authenticator has no public key")
+	}
+	messages, err := envelope.signingMessages(signer.Role)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(messages, auth.Verify) {
+		return fmt.Errorf("authenticator for %s does not verify against the transaction", address.String())
+	}
+
+	authKey := pubKey.AuthKey()
+	index, found := slices.BinarySearchFunc(signer.Authenticators, authKey, func(existing *crypto.AccountAuthenticator, target *crypto.AuthenticationKey) int {
+		return bytes.Compare(existing.PubKey().AuthKey()[:], target[:])
+	})
+	if found {
+		return nil
+	}
+	signer.Authenticators = slices.Insert(signer.Authenticators, index, auth)
+	return nil
+}
+
+// signingMessages returns the messages a signer with the role may have signed.  On a fee payer transaction, signers
+// other than the fee payer may have signed before the fee payer was known.
+func (envelope *TransactionEnvelope) signingMessages(role EnvelopeSignerRole) ([][]byte, error) {
+	message, err := envelope.Transaction.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	messages := [][]byte{message}
+	txn, ok := envelope.Transaction.(*RawTransactionWithData)
+	if !ok || txn.Variant != MultiAgentWithFeePayerRawTransactionWithDataVariant || role == EnvelopeSignerFeePayer {
+		return messages, nil
+	}
+	inner := txn.Inner.(*MultiAgentWithFeePayerRawTransactionWithData)
+	if *inner.FeePayer == AccountZero {
+		return messages, nil
+	}
+	withoutFeePayer := &RawTransactionWithData{
+		Variant: MultiAgentWithFeePayerRawTransactionWithDataVariant,
+		Inner: &MultiAgentWithFeePayerRawTransactionWithData{
+			RawTxn:           inner.RawTxn,
+			SecondarySigners: inner.SecondarySigners,
+			FeePayer:         &AccountZero,
+		},
+	}
+	message, err = withoutFeePayer.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	return append(messages, message), nil
+}
+
+// Merge adds the authenticators from another envelope of the same transaction.  The other envelope may still have
+// [AccountZero] as the fee payer, if this one has had it filled in with [TransactionEnvelope.SetFeePayer].
+func (envelope *TransactionEnvelope) Merge(other *TransactionEnvelope) error {
+	same, err := envelope.sameTransaction(other)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return errors.New("envelopes are for different transactions")
+	}
+	for _, otherSigner := range other.Signers {
+		if otherSigner.Role == EnvelopeSignerFeePayer && otherSigner.Address == AccountZero {
+			continue
+		}
+		for _, auth := range otherSigner.Authenticators {
+			if err = envelope.AddAuthenticator(otherSigner.Address, auth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sameTransaction compares the transactions, allowing the other to not have the fee payer filled in
+func (envelope *TransactionEnvelope) sameTransaction(other *TransactionEnvelope) (bool, error) {
+	ours, err := bcs.Serialize(envelope.Transaction)
+	if err != nil {
+		return false, err
+	}
+	theirs, err := bcs.Serialize(other.Transaction)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(ours, theirs) {
+		return true, nil
+	}
+	txn, ok := envelope.Transaction.(*RawTransactionWithData)
+	otherTxn, otherOk := other.Transaction.(*RawTransactionWithData)
+	if !ok || !otherOk || txn.Variant != MultiAgentWithFeePayerRawTransactionWithDataVariant || otherTxn.Variant != txn.Variant {
+		return false, nil
+	}
+	if *otherTxn.Inner.(*MultiAgentWithFeePayerRawTransactionWithData).FeePayer != AccountZero {
+		return false, nil
+	}
+	// The fee payer address is last, so everything before it must match
+	return bytes.Equal(ours[:len(ours)-32], theirs[:len(theirs)-32]), nil
+}
+
+// Finalize combines the authenticators into a [SignedTransaction], failing if any signer is incomplete
+func (envelope *TransactionEnvelope) Finalize() (*SignedTransaction, error) {
+	if missing := envelope.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("missing authenticators for %d signers, starting with %s", len(missing), missing[0].String())
+	}
+	auths := make([]*crypto.AccountAuthenticator, len(envelope.Signers))
+	for i, signer := range envelope.Signers {
+		if signer.Role == EnvelopeSignerFeePayer && signer.Address == AccountZero {
+			return nil, errors.New("fee payer has not been set")
+		}
+		auth, err := signer.authenticator()
+		if err != nil {
+			return nil, err
+		}
+		auths[i] = auth
+	}
+
+	switch txn := envelope.Transaction.(type) {
+	case *RawTransaction:
+		return txn.SignedTransactionWithAuthenticator(auths[0])
+	case *RawTransactionWithData:
+		additionalSigners := make([]crypto.AccountAuthenticator, 0, len(auths))
+		for i, signer := range envelope.Signers {
+			if signer.Role == EnvelopeSignerSecondary {
+				additionalSigners = append(additionalSigners, *auths[i])
+			}
+		}
+		var signedTxn *SignedTransaction
+		var ok bool
+		if txn.Variant == MultiAgentWithFeePayerRawTransactionWithDataVariant {
+			signedTxn, ok = txn.ToFeePayerSignedTransaction(auths[0], auths[len(auths)-1], additionalSigners)
+		} else {
+			signedTxn, ok = txn.ToMultiAgentSignedTransaction(auths[0], additionalSigners)
+		}
+		if !ok {
+			return nil, errors.New("failed to build signed transaction")
+		}
+		return signedTxn, nil
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %T", envelope.Transaction)
+	}
+}
+
+// authenticator is the signer's on-chain authenticator, combining multiple authenticators if needed
+func (signer *EnvelopeSigner) authenticator() (*crypto.AccountAuthenticator, error) {
+	if signer.Threshold == 1 {
+		return signer.Authenticators[0], nil
+	}
+	multiAuthKey := &crypto.MultiAuthKeyAuthenticator{}
+	if err := multiAuthKey.FromAuthenticators(signer.Authenticators[:signer.Threshold]); err != nil {
+		return nil, err
+	}
+	return &crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiAuthKey, Auth: multiAuthKey}, nil
+}
+
+// validate checks that an envelope from elsewhere has the right signers, and that every authenticator verifies
+func (envelope *TransactionEnvelope) validate() error {
+	if envelope.Version != TransactionEnvelopeVersion {
+		return fmt.Errorf("unsupported transaction envelope version %d", envelope.Version)
+	}
+	required, err := envelope.requiredSigners()
+	if err != nil {
+		return err
+	}
+	if len(required) != len(envelope.Signers) {
+		return fmt.Errorf("expected %d signers, got %d", len(required), len(envelope.Signers))
+	}
+	for i, signer := range envelope.Signers {
+		if signer.Address != required[i].Address || signer.Role != required[i].Role {
+			return fmt.Errorf("signer %d does not match the transaction", i)
+		}
+		if signer.Threshold == 0 {
+			return fmt.Errorf("signer %d has a threshold of 0", i)
+		}
+		auths := signer.Authenticators
+		signer.Authenticators = nil
+		for _, auth := range auths {
+			if err = envelope.AddAuthenticator(signer.Address, auth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//region TransactionEnvelope bcs.Struct
+
+// MarshalBCS serializes the envelope
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (envelope *TransactionEnvelope) MarshalBCS(ser *bcs.Serializer) {
+	ser.U8(envelope.Version)
+	switch envelope.Transaction.(type) {
+	case *RawTransaction:
+		ser.U8(0)
+	case *RawTransactionWithData:
+		ser.U8(1)
+	default:
+		ser.SetError(fmt.Errorf("unsupported transaction type %T", envelope.Transaction))
+		return
+	}
+	ser.Struct(envelope.Transaction)
+	ser.Uleb128(uint32(len(envelope.Signers)))
+	for _, signer := range envelope.Signers {
+		ser.Struct(&signer.Address)
+		ser.U8(uint8(signer.Role))
+		ser.U8(signer.Threshold)
+		bcs.SerializeSequence(signer.Authenticators, ser)
+	}
+}
+
+// UnmarshalBCS deserializes the envelope.  Use [ParseTransactionEnvelope] to also verify the authenticators.
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (envelope *TransactionEnvelope) UnmarshalBCS(des *bcs.Deserializer) {
+	envelope.Version = des.U8()
+	if envelope.Version != TransactionEnvelopeVersion {
+		des.SetError(fmt.Errorf("unsupported transaction envelope version %d", envelope.Version))
+		return
+	}
+	switch variant := des.U8(); variant {
+	case 0:
+		envelope.Transaction = &RawTransaction{}
+	case 1:
+		envelope.Transaction = &RawTransactionWithData{}
+	default:
+		des.SetError(fmt.Errorf("unknown transaction envelope variant %d", variant))
+		return
+	}
+	des.Struct(envelope.Transaction)
+	length := des.Uleb128()
+	envelope.Signers = make([]*EnvelopeSigner, 0, length)
+	for i := uint32(0); i < length && des.Error() == nil; i++ {
+		signer := &EnvelopeSigner{}
+		des.Struct(&signer.Address)
+		signer.Role = EnvelopeSignerRole(des.U8())
+		signer.Threshold = des.U8()
+		authCount := des.Uleb128()
+		for j := uint32(0); j < authCount && des.Error() == nil; j++ {
+			auth := &crypto.AccountAuthenticator{}
+			des.Struct(auth)
+			signer.Authenticators = append(signer.Authenticators, auth)
+		}
+		envelope.Signers = append(envelope.Signers, signer)
+	}
+}
+
+//endregion
+
+// Base64 encodes the envelope as base64 of the BCS
+func (envelope *TransactionEnvelope) Base64() (string, error) {
+	envelopeBytes, err := bcs.Serialize(envelope)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(envelopeBytes), nil
+}
+
+type transactionEnvelopeJson struct {
+	Version     uint8                    `json:"version"`
+	Transaction string                   `json:"transaction"`
+	WithData    bool                     `json:"with_data"`
+	Signers     []envelopeSignerJsonItem `json:"signers"`
+}
+
+type envelopeSignerJsonItem struct {
+	Address        string   `json:"address"`
+	Role           string   `json:"role"`
+	Threshold      uint8    `json:"threshold"`
+	Authenticators []string `json:"authenticators"`
+}
+
+// MarshalJSON encodes the envelope as JSON, with the transaction and authenticators as hex BCS
+func (envelope *TransactionEnvelope) MarshalJSON() ([]byte, error) {
+	txnBytes, err := bcs.Serialize(envelope.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	_, withData := envelope.Transaction.(*RawTransactionWithData)
+	out := transactionEnvelopeJson{
+		Version:     envelope.Version,
+		Transaction: BytesToHex(txnBytes),
+		WithData:    withData,
+		Signers:     make([]envelopeSignerJsonItem, len(envelope.Signers)),
+	}
+	for i, signer := range envelope.Signers {
+		out.Signers[i] = envelopeSignerJsonItem{
+			Address:        signer.Address.String(),
+			Role:           signer.Role.String(),
+			Threshold:      signer.Threshold,
+			Authenticators: make([]string, len(signer.Authenticators)),
+		}
+		for j, auth := range signer.Authenticators {
+			authBytes, err := bcs.Serialize(auth)
+			if err != nil {
+				return nil, err
+			}
+			out.Signers[i].Authenticators[j] = BytesToHex(authBytes)
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the envelope from JSON.  Use [ParseTransactionEnvelope] to also verify the authenticators.
+func (envelope *TransactionEnvelope) UnmarshalJSON(data []byte) error {
+	in := transactionEnvelopeJson{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	txnBytes, err := ParseHex(in.Transaction)
+	if err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+	if in.WithData {
+		envelope.Transaction = &RawTransactionWithData{}
+	} else {
+		envelope.Transaction = &RawTransaction{}
+	}
+	if err = bcs.Deserialize(envelope.Transaction, txnBytes); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+	envelope.Version = in.Version
+	envelope.Signers = make([]*EnvelopeSigner, len(in.Signers))
+	for i, item := range in.Signers {
+		signer := &EnvelopeSigner{Threshold: item.Threshold}
+		if err = signer.Address.ParseStringRelaxed(item.Address); err != nil {
+			return fmt.Errorf("invalid signer %d address: %w", i, err)
+		}
+		switch item.Role {
+		case EnvelopeSignerSender.String():
+			signer.Role = EnvelopeSignerSender
+		case EnvelopeSignerSecondary.String():
+			signer.Role = EnvelopeSignerSecondary
+		case EnvelopeSignerFeePayer.String():
+			signer.Role = EnvelopeSignerFeePayer
+		default:
+			return fmt.Errorf("invalid signer %d role %s", i, item.Role)
+		}
+		for j, authHex := range item.Authenticators {
+			auth, err := decodeAccountAuthenticator(authHex)
+			if err != nil {
+				return fmt.Errorf("invalid signer %d authenticator %d: %w", i, j, err)
+			}
+			signer.Authenticators = append(signer.Authenticators, auth)
+		}
+		envelope.Signers[i] = signer
+	}
+	return nil
+}
+
+// ParseTransactionEnvelope decodes an envelope from JSON, or base64 of the BCS, and verifies every authenticator in it
+func ParseTransactionEnvelope(data string) (*TransactionEnvelope, error) {
+	envelope := &TransactionEnvelope{}
+	trimmed := bytes.TrimSpace([]byte(data))
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, envelope); err != nil {
+			return nil, fmt.Errorf("invalid transaction envelope: %w", err)
+		}
+	} else {
+		envelopeBytes, err := base64.StdEncoding.DecodeString(string(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction envelope: %w", err)
+		}
+		if err = bcs.Deserialize(envelope, envelopeBytes); err != nil {
+			return nil, fmt.Errorf("invalid transaction envelope: %w", err)
+		}
+	}
+	if err := envelope.validate(); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
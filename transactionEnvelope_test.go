@@ -0,0 +1,165 @@
+package endless
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func envelopeTestPayload(t *testing.T) TransactionPayload {
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 100)
+	assert.NoError(t, err)
+	return TransactionPayload{Payload: entryFunction}
+}
+
+func envelopeRoundTrip(t *testing.T, envelope *TransactionEnvelope, useJson bool) *TransactionEnvelope {
+	var encoded string
+	if useJson {
+		jsonBytes, err := json.Marshal(envelope)
+		assert.NoError(t, err)
+		encoded = string(jsonBytes)
+	} else {
+		var err error
+		encoded, err = envelope.Base64()
+		assert.NoError(t, err)
+	}
+	decoded, err := ParseTransactionEnvelope(encoded)
+	assert.NoError(t, err)
+	return decoded
+}
+
+func TestTransactionEnvelope_MultiAgentMerge(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	secondary, err := NewEd25519Account()
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransactionMultiAgent(sender.Address, envelopeTestPayload(t), AdditionalSigners{secondary.Address}, SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+
+	envelope, err := NewTransactionEnvelope(rawTxn)
+	assert.NoError(t, err)
+	assert.Equal(t, []AccountAddress{sender.Address, secondary.Address}, envelope.Missing())
+
+	// Each party signs their own copy
+	senderCopy := envelopeRoundTrip(t, envelope, false)
+	assert.NoError(t, senderCopy.Sign(sender))
+	secondaryCopy := envelopeRoundTrip(t, envelope, true)
+	assert.NoError(t, secondaryCopy.Sign(secondary))
+
+	// Merged in either order, with duplicates, it comes out the same
+	merged := envelopeRoundTrip(t, secondaryCopy, true)
+	assert.NoError(t, merged.Merge(envelopeRoundTrip(t, senderCopy, false)))
+	assert.NoError(t, merged.Merge(senderCopy))
+	assert.True(t, merged.Complete())
+	assert.NoError(t, senderCopy.Merge(secondaryCopy))
+
+	signedTxn, err := merged.Finalize()
+	assert.NoError(t, err)
+	assert.NoError(t, signedTxn.Verify())
+	otherSignedTxn, err := senderCopy.Finalize()
+	assert.NoError(t, err)
+	hash, err := signedTxn.Hash()
+	assert.NoError(t, err)
+	otherHash, err := otherSignedTxn.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, otherHash)
+}
+
+func TestTransactionEnvelope_FeePayerSetLater(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	sponsor, err := NewEd25519Account()
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransactionMultiAgent(sender.Address, envelopeTestPayload(t), FeePayer(&AccountZero), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+
+	envelope, err := NewTransactionEnvelope(rawTxn)
+	assert.NoError(t, err)
+	assert.NoError(t, envelope.Sign(sender))
+	_, err = envelope.Finalize()
+	assert.Error(t, err)
+
+	// The sponsor fills themselves in, and the sender's signature still counts
+	sponsorCopy := envelopeRoundTrip(t, envelope, false)
+	assert.NoError(t, sponsorCopy.SetFeePayer(sponsor.Address))
+	assert.Error(t, sponsorCopy.SetFeePayer(sponsor.Address))
+	assert.Equal(t, []AccountAddress{sponsor.Address}, sponsorCopy.Missing())
+	assert.NoError(t, sponsorCopy.Sign(sponsor))
+	assert.NoError(t, sponsorCopy.Merge(envelope))
+
+	// Still valid after a round trip
+	sponsorCopy = envelopeRoundTrip(t, sponsorCopy, true)
+	signedTxn, err := sponsorCopy.Finalize()
+	assert.NoError(t, err)
+	feePayerAuth := signedTxn.Authenticator.Auth.(*FeePayerTransactionAuthenticator)
+	assert.Equal(t, sponsor.Address, *feePayerAuth.FeePayer)
+}
+
+func TestTransactionEnvelope_MultiAuthKey(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner1, err := crypto.GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	owner2, err := crypto.GenerateEd25519PrivateKey()
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransaction(sender.Address, envelopeTestPayload(t), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+
+	envelope, err := NewTransactionEnvelope(rawTxn, EnvelopeThreshold{Address: sender.Address, Threshold: 2})
+	assert.NoError(t, err)
+	for _, owner := range []*crypto.Ed25519PrivateKey{owner2, owner1, owner2} {
+		auth, err := rawTxn.Sign(owner)
+		assert.NoError(t, err)
+		assert.NoError(t, envelope.AddAuthenticator(sender.Address, auth))
+	}
+	assert.Len(t, envelope.Signers[0].Authenticators, 2)
+
+	envelope = envelopeRoundTrip(t, envelope, true)
+	assert.Equal(t, uint8(2), envelope.Signers[0].Threshold)
+	signedTxn, err := envelope.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.AccountAuthenticatorMultiAuthKey, signedTxn.Authenticator.Auth.(*SingleSenderTransactionAuthenticator).Sender.Variant)
+	assert.NoError(t, signedTxn.Verify())
+}
+
+func TestTransactionEnvelope_Rejects(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	other, err := NewEd25519Account()
+	assert.NoError(t, err)
+	rawTxn, err := node.client.BuildTransaction(sender.Address, envelopeTestPayload(t), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+	otherTxn, err := node.client.BuildTransaction(sender.Address, envelopeTestPayload(t), SequenceNumber(1), GasUnitPrice(100))
+	assert.NoError(t, err)
+
+	envelope, err := NewTransactionEnvelope(rawTxn)
+	assert.NoError(t, err)
+	_, err = NewTransactionEnvelope(rawTxn, EnvelopeThreshold{Address: other.Address, Threshold: 2})
+	assert.Error(t, err)
+
+	// Not a signer
+	assert.Error(t, envelope.Sign(other))
+	// Signed a different transaction
+	wrongAuth, err := otherTxn.Sign(sender)
+	assert.NoError(t, err)
+	assert.Error(t, envelope.AddAuthenticator(sender.Address, wrongAuth))
+	_, err = envelope.Finalize()
+	assert.Error(t, err)
+
+	otherEnvelope, err := NewTransactionEnvelope(otherTxn)
+	assert.NoError(t, err)
+	assert.Error(t, envelope.Merge(otherEnvelope))
+
+	// Tampered authenticators are caught when parsing
+	envelope.Signers[0].Authenticators = []*crypto.AccountAuthenticator{wrongAuth}
+	encoded, err := envelope.Base64()
+	assert.NoError(t, err)
+	_, err = ParseTransactionEnvelope(encoded)
+	assert.Error(t, err)
+}
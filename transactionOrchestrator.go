@@ -0,0 +1,332 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+)
+
+// ErrOrchestratorClosed is returned when submitting to a [TransactionOrchestrator] that has been closed
+var ErrOrchestratorClosed = errors.New("transaction orchestrator is closed")
+
+// OrchestratorBatchSize is the maximum number of transactions per sender in a single batch submission, defaults to 20
+type OrchestratorBatchSize int
+
+// OrchestratorQueueSize is the number of payloads that can be queued before [TransactionOrchestrator.Submit] blocks,
+// defaults to 100
+type OrchestratorQueueSize int
+
+// OrchestratorFlushInterval is how long a sender waits to fill a batch before submitting it, defaults to 50ms
+type OrchestratorFlushInterval time.Duration
+
+// OrchestratorResult is the outcome of a single payload submitted to a [TransactionOrchestrator]
+type OrchestratorResult struct {
+	Id             uint64         // Id is the caller supplied id from [TransactionBuildPayload]
+	Sender         AccountAddress // Sender is the account the payload was assigned to
+	SequenceNumber uint64         // SequenceNumber is the sequence number the transaction was built with
+	Hash           string         // Hash is the transaction hash, set only when it was accepted by the node
+	Err            error          // Err is set if the transaction failed to build, sign, or submit
+}
+
+// TransactionOrchestrator spreads a stream of payloads across a pool of sender accounts for high throughput.
+//
+// Each sender pulls payloads from a shared queue, so payloads go to whichever account is free.  Sequence numbers are
+// kept per account by a [SequenceNumberManager], and each account submits its payloads in batches with
+// [NodeClient.BatchSubmitTransaction].  Results are reported on [TransactionOrchestrator.Results] keyed by the caller's
+// id, in no particular order.
+//
+//	orchestrator, err := client.NewTransactionOrchestrator(signers)
+//	go func() {
+//		for result := range orchestrator.Results() {
+//			// Handle result
+//		}
+//	}()
+//	for i, payload := range payloads {
+//		err = orchestrator.Submit(TransactionBuildPayload{Id: uint64(i), Inner: payload})
+//	}
+//	orchestrator.Close()
+type TransactionOrchestrator struct {
+	client        *NodeClient
+	buildOptions  []any
+	batchSize     int
+	flushInterval time.Duration
+
+	payloads  chan TransactionBuildPayload
+	results   chan OrchestratorResult
+	closeLock sync.RWMutex
+	closed    bool
+	workers   sync.WaitGroup
+}
+
+// NewTransactionOrchestrator creates a [TransactionOrchestrator], and starts a worker per signer.  Only
+// [TransactionSubmissionTypeSingle] payloads are supported.
+//
+// Accepts options:
+//   - [OrchestratorBatchSize]
+//   - [OrchestratorQueueSize]
+//   - [OrchestratorFlushInterval]
+//   - [SequenceNumberStore] shared by all senders' [SequenceNumberManager]
+//   - Options for [NodeClient.BuildTransaction] except [SequenceNumber], applied to every transaction
+func NewTransactionOrchestrator(client *NodeClient, signers []TransactionSigner, options ...any) (*TransactionOrchestrator, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("transaction orchestrator requires at least one signer")
+	}
+	batchSize := 20
+	queueSize := 100
+	flushInterval := 50 * time.Millisecond
+	managerOptions := make([]any, 0)
+	buildOptions := make([]any, 0)
+	for i, option := range options {
+		switch ovalue := option.(type) {
+		case OrchestratorBatchSize:
+			batchSize = int(ovalue)
+		case OrchestratorQueueSize:
+			queueSize = int(ovalue)
+		case OrchestratorFlushInterval:
+			flushInterval = time.Duration(ovalue)
+		case SequenceNumberStore:
+			managerOptions = append(managerOptions, ovalue)
+		case SequenceNumber:
+			return nil, fmt.Errorf("NewTransactionOrchestrator arg [%d] SequenceNumber is managed per sender", i+3)
+		default:
+			buildOptions = append(buildOptions, option)
+		}
+	}
+	if batchSize <= 0 || queueSize < 0 {
+		return nil, fmt.Errorf("invalid batch size %d or queue size %d", batchSize, queueSize)
+	}
+
+	managers := make([]*SequenceNumberManager, len(signers))
+	for i, signer := range signers {
+		manager, err := NewSequenceNumberManager(client, signer.AccountAddress(), managerOptions...)
+		if err != nil {
+			return nil, err
+		}
+		managers[i] = manager
+	}
+
+	orchestrator := &TransactionOrchestrator{
+		client:        client,
+		buildOptions:  buildOptions,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		payloads:      make(chan TransactionBuildPayload, queueSize),
+		results:       make(chan OrchestratorResult, queueSize),
+	}
+	for i, signer := range signers {
+		orchestrator.workers.Add(1)
+		go orchestrator.worker(signer, managers[i])
+	}
+	go func() {
+		orchestrator.workers.Wait()
+		close(orchestrator.results)
+	}()
+	return orchestrator, nil
+}
+
+// Results is the channel of results, it is closed after [TransactionOrchestrator.Close] once every payload has been
+// processed.  It must be drained, or the orchestrator will stop processing payloads.
+func (to *TransactionOrchestrator) Results() <-chan OrchestratorResult {
+	return to.results
+}
+
+// Submit queues a payload, blocking while the queue is full.  Returns [ErrOrchestratorClosed] after
+// [TransactionOrchestrator.Close].
+func (to *TransactionOrchestrator) Submit(payload TransactionBuildPayload) error {
+	to.closeLock.RLock()
+	defer to.closeLock.RUnlock()
+	if to.closed {
+		return ErrOrchestratorClosed
+	}
+	to.payloads <- payload
+	return nil
+}
+
+// TrySubmit queues a payload without blocking, returns false if the queue is full or the orchestrator is closed
+func (to *TransactionOrchestrator) TrySubmit(payload TransactionBuildPayload) bool {
+	to.closeLock.RLock()
+	defer to.closeLock.RUnlock()
+	if to.closed {
+		return false
+	}
+	select {
+	case to.payloads <- payload:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops accepting payloads, and blocks until every queued payload has been submitted.  Sequence numbers released
+// by failed transactions, and those of transactions already expired, are filled with no-op transactions.
+//
+// A number whose batch submission failed and whose transaction couldn't be looked up is kept, as the transaction may
+// still commit, and isn't filled.  If that transaction expires instead, the sender is left with a gap at the number
+// reported in its [OrchestratorResult].
+func (to *TransactionOrchestrator) Close() {
+	to.closeLock.Lock()
+	if !to.closed {
+		to.closed = true
+		close(to.payloads)
+	}
+	to.closeLock.Unlock()
+	to.workers.Wait()
+}
+
+func (to *TransactionOrchestrator) worker(signer TransactionSigner, manager *SequenceNumberManager) {
+	defer to.workers.Done()
+	batch := make([]TransactionBuildPayload, 0, to.batchSize)
+	released := false
+	for {
+		payload, ok := <-to.payloads
+		if !ok {
+			break
+		}
+		batch = append(batch, payload)
+
+		// Fill up the batch, but don't hold on to payloads for longer than the flush interval
+		timer := time.NewTimer(to.flushInterval)
+		open := true
+		for open && len(batch) < to.batchSize {
+			select {
+			case payload, open = <-to.payloads:
+				if open {
+					batch = append(batch, payload)
+				}
+			case <-timer.C:
+				open = false
+			}
+		}
+		timer.Stop()
+
+		if to.submitBatch(signer, manager, batch) {
+			released = true
+		}
+		batch = batch[:0]
+	}
+
+	// Shutting down, leave no gaps behind
+	if released {
+		_, _ = manager.FillGaps(signer, to.buildOptions...)
+	}
+}
+
+// submitBatch builds, signs, and submits a batch of payloads for a single sender.  Returns true if any sequence number
+// was released, or may be left unused.
+func (to *TransactionOrchestrator) submitBatch(signer TransactionSigner, manager *SequenceNumberManager, batch []TransactionBuildPayload) (released bool) {
+	sender := signer.AccountAddress()
+	buildOptions := slices.Clone(to.buildOptions)
+	if !slices.ContainsFunc(buildOptions, func(option any) bool { _, ok := option.(GasUnitPrice); return ok }) {
+		// Estimate once for the whole batch, rather than per transaction
+		gasInfo, err := to.client.EstimateGasPrice()
+		if err == nil {
+			buildOptions = append(buildOptions, GasUnitPrice(gasInfo.GasEstimate))
+		}
+	}
+
+	requests := make([]TransactionSubmissionRequest, 0, len(batch))
+	sequenceNumbers := make([]uint64, 0, len(batch))
+	for _, payload := range batch {
+		if payload.Type != TransactionSubmissionTypeSingle {
+			to.results <- OrchestratorResult{Id: payload.Id, Sender: sender, Err: errors.New("transaction orchestrator only supports single signer payloads")}
+			continue
+		}
+		sequenceNumber, err := manager.Next()
+		if err != nil {
+			to.results <- OrchestratorResult{Id: payload.Id, Sender: sender, Err: err}
+			continue
+		}
+		options := append(slices.Clone(buildOptions), payload.Options...)
+		options = append(options, SequenceNumber(sequenceNumber))
+		rawTxn, err := to.client.BuildTransaction(sender, payload.Inner, options...)
+		if err != nil {
+			manager.Release(sequenceNumber)
+			released = true
+			to.results <- OrchestratorResult{Id: payload.Id, Sender: sender, SequenceNumber: sequenceNumber, Err: err}
+			continue
+		}
+		signedTxn, err := rawTxn.SignedTransaction(signer)
+		if err != nil {
+			manager.Release(sequenceNumber)
+			released = true
+			to.results <- OrchestratorResult{Id: payload.Id, Sender: sender, SequenceNumber: sequenceNumber, Err: err}
+			continue
+		}
+		requests = append(requests, TransactionSubmissionRequest{Id: payload.Id, SignedTxn: signedTxn})
+		sequenceNumbers = append(sequenceNumbers, sequenceNumber)
+	}
+	if len(requests) == 0 {
+		return released
+	}
+
+	signedTxns := make([]*SignedTransaction, len(requests))
+	for i, request := range requests {
+		signedTxns[i] = request.SignedTxn
+	}
+	response, err := to.client.BatchSubmitTransaction(signedTxns)
+	if err != nil {
+		// A rejected request accepted nothing, but on a timeout or server error some may already be in the mempool
+		rejected := isSubmissionRejected(err)
+		for i, request := range requests {
+			sequenceNumber := sequenceNumbers[i]
+			known := false
+			var lookupErr error
+			if !rejected {
+				known, lookupErr = transactionKnown(to.client, request.SignedTxn)
+			}
+			switch {
+			case known:
+				manager.Submitted(sequenceNumber, request.SignedTxn.Transaction.ExpirationTimestampSeconds)
+				hash, hashErr := request.SignedTxn.Hash()
+				to.results <- OrchestratorResult{Id: request.Id, Sender: sender, SequenceNumber: sequenceNumber, Hash: hash, Err: hashErr}
+			case lookupErr != nil:
+				// Unknown, keep the number until the transaction expires, then it's found by DetectGaps
+				manager.Submitted(sequenceNumber, request.SignedTxn.Transaction.ExpirationTimestampSeconds)
+				released = true
+				to.results <- OrchestratorResult{Id: request.Id, Sender: sender, SequenceNumber: sequenceNumber, Err: err}
+			default:
+				manager.Release(sequenceNumber)
+				released = true
+				to.results <- OrchestratorResult{Id: request.Id, Sender: sender, SequenceNumber: sequenceNumber, Err: err}
+			}
+		}
+		return released
+	}
+
+	failures := make(map[uint32]api.Error, len(response.TransactionFailures))
+	for _, failure := range response.TransactionFailures {
+		failures[failure.TransactionIndex] = failure.Error
+	}
+	for i, request := range requests {
+		sequenceNumber := sequenceNumbers[i]
+		if apiError, failed := failures[uint32(i)]; failed {
+			failureErr := &TransactionSubmissionError{
+				Request:  request,
+				Index:    uint32(i),
+				ApiError: apiError,
+				Kind:     ClassifySubmissionError(&apiError),
+			}
+			if failureErr.Kind == SubmissionErrorSequenceNumber {
+				_, _ = manager.Reconcile(sequenceNumber, failureErr)
+			} else {
+				manager.Release(sequenceNumber)
+				released = true
+			}
+			to.results <- OrchestratorResult{Id: request.Id, Sender: sender, SequenceNumber: sequenceNumber, Err: failureErr}
+			continue
+		}
+		manager.Submitted(sequenceNumber, request.SignedTxn.Transaction.ExpirationTimestampSeconds)
+		hash, err := request.SignedTxn.Hash()
+		to.results <- OrchestratorResult{Id: request.Id, Sender: sender, SequenceNumber: sequenceNumber, Hash: hash, Err: err}
+	}
+	return released
+}
+
+// NewTransactionOrchestrator creates a [TransactionOrchestrator] for the signers, see [NewTransactionOrchestrator]
+func (client *Client) NewTransactionOrchestrator(signers []TransactionSigner, options ...any) (*TransactionOrchestrator, error) {
+	return NewTransactionOrchestrator(client.nodeClient, signers, options...)
+}
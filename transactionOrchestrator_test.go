@@ -0,0 +1,162 @@
+package endless
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionOrchestrator(t *testing.T) {
+	node := newTestNode(t)
+	node.AutoCommit = true
+
+	signers := make([]TransactionSigner, 3)
+	for i := range signers {
+		account, err := NewEd25519Account()
+		assert.NoError(t, err)
+		node.SetAccount(account.Address, uint64(i*100))
+		signers[i] = account
+	}
+
+	orchestrator, err := NewTransactionOrchestrator(node.client, signers, OrchestratorBatchSize(5), OrchestratorQueueSize(4))
+	assert.NoError(t, err)
+
+	results := make(map[uint64]OrchestratorResult)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for result := range orchestrator.Results() {
+			results[result.Id] = result
+		}
+	}()
+
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	payload := TransactionPayload{Payload: entryFunction}
+	const numPayloads = 50
+	for i := uint64(0); i < numPayloads; i++ {
+		assert.NoError(t, orchestrator.Submit(TransactionBuildPayload{Id: i, Type: TransactionSubmissionTypeSingle, Inner: payload}))
+	}
+	orchestrator.Close()
+	wg.Wait()
+
+	// Every payload has a result, and is submitted exactly once
+	assert.Len(t, results, numPayloads)
+	submitted := node.Submitted()
+	assert.Len(t, submitted, numPayloads)
+
+	// Sequence numbers per sender are contiguous from the on-chain value
+	perSender := make(map[AccountAddress][]uint64)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		assert.NotEmpty(t, result.Hash)
+		perSender[result.Sender] = append(perSender[result.Sender], result.SequenceNumber)
+	}
+	for i, signer := range signers {
+		sequenceNumbers := perSender[signer.AccountAddress()]
+		start := uint64(i * 100)
+		seen := make(map[uint64]bool)
+		for _, seq := range sequenceNumbers {
+			seen[seq] = true
+		}
+		for seq := start; seq < start+uint64(len(sequenceNumbers)); seq++ {
+			assert.True(t, seen[seq])
+		}
+	}
+
+	// No more submissions after close
+	assert.ErrorIs(t, orchestrator.Submit(TransactionBuildPayload{Id: numPayloads, Inner: payload}), ErrOrchestratorClosed)
+	assert.False(t, orchestrator.TrySubmit(TransactionBuildPayload{Id: numPayloads, Inner: payload}))
+}
+
+func TestTransactionOrchestrator_FailuresFillGaps(t *testing.T) {
+	node := newTestNode(t)
+	node.AutoCommit = true
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	// Reject the first transfer with amount 2, it leaves a gap behind
+	rejected := false
+	node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		entryFunction := txn.Transaction.Payload.Payload.(*EntryFunction)
+		if !rejected && entryFunction.Args[1][0] == 2 {
+			rejected = true
+			return http.StatusBadRequest, map[string]any{"message": "rejected", "error_code": "vm_error", "vm_error_code": 1}
+		}
+		return 0, nil
+	}
+
+	orchestrator, err := NewTransactionOrchestrator(node.client, []TransactionSigner{sender}, OrchestratorBatchSize(3))
+	assert.NoError(t, err)
+	for i := uint64(1); i <= 3; i++ {
+		entryFunction, err := CoinTransferPayload(nil, AccountOne, i)
+		assert.NoError(t, err)
+		assert.NoError(t, orchestrator.Submit(TransactionBuildPayload{Id: i, Inner: TransactionPayload{Payload: entryFunction}}))
+	}
+	failed := 0
+	go orchestrator.Close()
+	for result := range orchestrator.Results() {
+		if result.Err != nil {
+			failed++
+			assert.Equal(t, uint64(2), result.Id)
+		}
+	}
+	assert.Equal(t, 1, failed)
+
+	// The released sequence number 1 was filled on shutdown
+	sequenceNumbers := make([]uint64, 0)
+	for _, txn := range node.Submitted() {
+		sequenceNumbers = append(sequenceNumbers, txn.Transaction.SequenceNumber)
+	}
+	assert.ElementsMatch(t, []uint64{0, 1, 2}, sequenceNumbers)
+}
+
+func TestTransactionOrchestrator_BatchErrorLooksUp(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	// The first transaction reaches the mempool, but the batch request fails
+	node.Handlers["POST /transactions/batch"] = func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		des := bcs.NewDeserializer(body)
+		signedTxns := bcs.DeserializeSequence[SignedTransaction](des)
+		assert.NoError(t, des.Error())
+		node.mutex.Lock()
+		node.accept(&signedTxns[0])
+		node.mutex.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	orchestrator, err := NewTransactionOrchestrator(node.client, []TransactionSigner{sender}, OrchestratorBatchSize(3), OrchestratorFlushInterval(time.Second))
+	assert.NoError(t, err)
+	for i := uint64(1); i <= 3; i++ {
+		entryFunction, err := CoinTransferPayload(nil, AccountOne, i)
+		assert.NoError(t, err)
+		assert.NoError(t, orchestrator.Submit(TransactionBuildPayload{Id: i, Inner: TransactionPayload{Payload: entryFunction}}))
+	}
+	go orchestrator.Close()
+	for result := range orchestrator.Results() {
+		if result.SequenceNumber == 0 {
+			assert.NoError(t, result.Err)
+			assert.NotEmpty(t, result.Hash)
+		} else {
+			assert.Error(t, result.Err)
+		}
+	}
+
+	// Only the numbers of transactions not in the mempool were filled, 0 isn't used twice
+	sequenceNumbers := make([]uint64, 0)
+	for _, txn := range node.Submitted() {
+		sequenceNumbers = append(sequenceNumbers, txn.Transaction.SequenceNumber)
+	}
+	assert.ElementsMatch(t, []uint64{0, 1, 2}, sequenceNumbers)
+}
@@ -1,9 +1,14 @@
 package endless
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/endless-labs/endless-go-sdk/api"
 )
@@ -143,52 +148,262 @@ func (rc *NodeClient) SubmitTransactions(requests chan TransactionSubmissionRequ
 	}
 }
 
-// BatchSubmitTransactions consumes signed transactions, submits to endless-node, yields responses.
-// closes output chan `responses` when input chan `signedTxns` is closed.
-func (rc *NodeClient) BatchSubmitTransactions(requests chan TransactionSubmissionRequest, responses chan TransactionSubmissionResponse) {
+// SubmissionErrorKind classifies why a transaction was rejected on submission
+type SubmissionErrorKind uint8
+
+const (
+	// SubmissionErrorPermanent is a rejection that will happen again, e.g. a bad signature or insufficient balance
+	SubmissionErrorPermanent SubmissionErrorKind = iota
+	// SubmissionErrorTransient is a rejection that may not happen again, e.g. mempool is full, or the node is overloaded
+	SubmissionErrorTransient
+	// SubmissionErrorSequenceNumber is a rejection due to the sequence number, it needs to be signed with a new one
+	SubmissionErrorSequenceNumber
+)
+
+// String returns a readable name of the SubmissionErrorKind
+func (kind SubmissionErrorKind) String() string {
+	switch kind {
+	case SubmissionErrorPermanent:
+		return "permanent"
+	case SubmissionErrorTransient:
+		return "transient"
+	case SubmissionErrorSequenceNumber:
+		return "sequence_number"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(kind))
+	}
+}
+
+// ClassifySubmissionError classifies an [api.Error] returned by the node on submission
+func ClassifySubmissionError(apiError *api.Error) SubmissionErrorKind {
+	switch apiError.ErrorCode {
+	case "sequence_number_too_old":
+		return SubmissionErrorSequenceNumber
+	case "mempool_is_full", "internal_error", "health_check_failed":
+		return SubmissionErrorTransient
+	}
+	if apiError.VmErrorCode == VmStatusSequenceNumberTooOld || apiError.VmErrorCode == VmStatusSequenceNumberTooNew ||
+		strings.Contains(apiError.Message, "SEQUENCE_NUMBER_TOO_OLD") || strings.Contains(apiError.Message, "SEQUENCE_NUMBER_TOO_NEW") {
+		return SubmissionErrorSequenceNumber
+	}
+	return SubmissionErrorPermanent
+}
+
+// TransactionSubmissionError is the error for a single transaction rejected in a batch submission
+type TransactionSubmissionError struct {
+	Request  TransactionSubmissionRequest // Request is the request that was rejected
+	Index    uint32                       // Index is the position of the request in the caller's batch, retries may submit it at another position
+	ApiError api.Error                    // ApiError is the error returned by the node
+	Kind     SubmissionErrorKind          // Kind is the classification of ApiError
+}
+
+// Error returns a string representation of the TransactionSubmissionError
+//
+// Implements:
+//   - [error]
+func (tse *TransactionSubmissionError) Error() string {
+	return fmt.Sprintf("transaction %d failed (%s): %s", tse.Request.Id, tse.Kind, tse.ApiError.Message)
+}
+
+// BatchSubmitSize is the maximum number of transactions per call to [NodeClient.BatchSubmitTransaction], defaults to 20
+type BatchSubmitSize int
+
+// BatchSubmitFlushInterval is how long to wait to fill a batch before submitting it anyway, defaults to 50ms
+type BatchSubmitFlushInterval time.Duration
+
+// BatchSubmitRetry enables retries of failed transactions in [NodeClient.BatchSubmitTransactions]
+//
+// Transient failures are resubmitted as is.  Sequence number failures are only retried if Resign is set.
+type BatchSubmitRetry struct {
+	MaxRetries int           // MaxRetries is the maximum number of retries per transaction
+	Delay      time.Duration // Delay before the first retry, it is doubled on every retry
+	// Resign signs the request again with a new sequence number, it is called only for
+	// [SubmissionErrorSequenceNumber] failures
+	Resign func(request TransactionSubmissionRequest, err *TransactionSubmissionError) (*SignedTransaction, error)
+}
+
+// BatchSubmitTransactions consumes signed transactions, submits to endless-node in batches, yields responses.
+// closes output chan `responses` when input chan `requests` is closed.
+//
+// Responses are yielded in the same order as the requests.  A rejected transaction has an Err of
+// [*TransactionSubmissionError], which tells the request and the classified error from the node.  Accepted
+// transactions have a Response built from the signed transaction, as the node does not return one for batches.
+//
+// Accepts options:
+//   - [BatchSubmitSize]
+//   - [BatchSubmitFlushInterval]
+//   - [BatchSubmitRetry]
+func (client *Client) BatchSubmitTransactions(requests chan TransactionSubmissionRequest, responses chan TransactionSubmissionResponse, options ...any) {
+	client.nodeClient.BatchSubmitTransactions(requests, responses, options...)
+}
+
+// BatchSubmitTransactions consumes signed transactions, submits to endless-node in batches, yields responses.
+// closes output chan `responses` when input chan `requests` is closed.
+//
+// Responses are yielded in the same order as the requests.  A rejected transaction has an Err of
+// [*TransactionSubmissionError], which tells the request and the classified error from the node.  Accepted
+// transactions have a Response built from the signed transaction, as the node does not return one for batches.
+//
+// Retried transactions are resubmitted in their original order.  Note that a transaction signed again with a new
+// sequence number will execute after other transactions from the same sender that were already accepted.
+//
+// Accepts options:
+//   - [BatchSubmitSize]
+//   - [BatchSubmitFlushInterval]
+//   - [BatchSubmitRetry]
+func (rc *NodeClient) BatchSubmitTransactions(requests chan TransactionSubmissionRequest, responses chan TransactionSubmissionResponse, options ...any) {
 	defer close(responses)
 
-	inputs := make([]*SignedTransaction, 20)
-	ids := make([]uint64, 20)
-	i := uint32(0)
+	batchSize := 20
+	flushInterval := 50 * time.Millisecond
+	retry := BatchSubmitRetry{}
+	for i, option := range options {
+		switch ovalue := option.(type) {
+		case BatchSubmitSize:
+			batchSize = int(ovalue)
+		case BatchSubmitFlushInterval:
+			flushInterval = time.Duration(ovalue)
+		case BatchSubmitRetry:
+			retry = ovalue
+		default:
+			err := fmt.Errorf("BatchSubmitTransactions arg [%d] unknown option type %T", i+3, option)
+			for request := range requests {
+				responses <- TransactionSubmissionResponse{Id: request.Id, Err: err}
+			}
+			return
+		}
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
 
-	for request := range requests {
-		// Collect 20 inputs before submitting
-		// TODO: Handle a timeout or something associated for it
-		inputs[i] = request.SignedTxn
-		ids[i] = request.Id
-
-		if i >= 19 {
-			i = 0
-			response, err := rc.BatchSubmitTransaction(inputs)
-
-			// Process the responses
-			if err != nil {
-				// Error, means all failed
-				for j := uint32(0); j < i; j++ {
-					responses <- TransactionSubmissionResponse{Id: ids[j], Err: err}
+	batch := make([]TransactionSubmissionRequest, 0, batchSize)
+	for {
+		request, ok := <-requests
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], request)
+
+		// Collect more requests, until the batch is full or the flush interval passes
+		timer := time.NewTimer(flushInterval)
+		open := true
+		collecting := true
+		for open && collecting && len(batch) < batchSize {
+			select {
+			case request, open = <-requests:
+				if open {
+					batch = append(batch, request)
+				}
+			case <-timer.C:
+				collecting = false
+			}
+		}
+		timer.Stop()
+
+		for _, response := range rc.submitBatchWithRetry(batch, retry) {
+			responses <- response
+		}
+		if !open {
+			return
+		}
+	}
+}
+
+// submitBatchWithRetry submits a batch, retrying failures according to retry.  Returns responses in batch order.
+func (rc *NodeClient) submitBatchWithRetry(batch []TransactionSubmissionRequest, retry BatchSubmitRetry) []TransactionSubmissionResponse {
+	// Copy the batch, as retries may replace signed transactions
+	batch = slices.Clone(batch)
+	results := make([]TransactionSubmissionResponse, len(batch))
+	pending := make([]int, len(batch))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	delay := retry.Delay
+	for attempt := 0; len(pending) > 0; attempt++ {
+		canRetry := attempt < retry.MaxRetries
+		signedTxns := make([]*SignedTransaction, len(pending))
+		for pos, i := range pending {
+			signedTxns[pos] = batch[i].SignedTxn
+		}
+
+		retryPending := make([]int, 0)
+		response, err := rc.BatchSubmitTransaction(signedTxns)
+		if err != nil {
+			// The whole request failed.  On a server error or timeout some may have been accepted anyway, but retrying
+			// resubmits the same signed bytes, which the mempool accepts again without a second transaction.
+			transient := false
+			var httpError *HttpError
+			if errors.As(err, &httpError) {
+				transient = httpError.StatusCode == http.StatusTooManyRequests || httpError.StatusCode >= 500
+			}
+			for _, i := range pending {
+				results[i] = TransactionSubmissionResponse{Id: batch[i].Id, Err: err}
+				if transient && canRetry {
+					retryPending = append(retryPending, i)
+				}
+			}
+		} else {
+			failures := make(map[uint32]api.Error, len(response.TransactionFailures))
+			for _, failure := range response.TransactionFailures {
+				failures[failure.TransactionIndex] = failure.Error
+			}
+			for pos, i := range pending {
+				apiError, failed := failures[uint32(pos)]
+				if !failed {
+					results[i] = TransactionSubmissionResponse{Id: batch[i].Id, Response: pendingTransactionFromSignedTransaction(batch[i].SignedTxn)}
+					continue
 				}
-			} else {
-				// Partial failure, means we need to send errors for those that failed
-				// and responses for those that succeeded
-
-				for j := uint32(0); j < i; j++ {
-					failed := -1
-					for k := 0; k < len(response.TransactionFailures); k++ {
-						if response.TransactionFailures[k].TransactionIndex == j {
-							failed = k
-							break
+				submissionErr := &TransactionSubmissionError{
+					Request:  batch[i],
+					Index:    uint32(i),
+					ApiError: apiError,
+					Kind:     ClassifySubmissionError(&apiError),
+				}
+				results[i] = TransactionSubmissionResponse{Id: batch[i].Id, Err: submissionErr}
+				if !canRetry {
+					continue
+				}
+				switch submissionErr.Kind {
+				case SubmissionErrorTransient:
+					retryPending = append(retryPending, i)
+				case SubmissionErrorSequenceNumber:
+					if retry.Resign != nil {
+						signedTxn, resignErr := retry.Resign(batch[i], submissionErr)
+						if resignErr != nil {
+							results[i].Err = fmt.Errorf("failed to sign transaction %d again: %w", batch[i].Id, resignErr)
+							continue
 						}
+						batch[i].SignedTxn = signedTxn
+						retryPending = append(retryPending, i)
 					}
-					if failed >= 0 {
-						responses <- TransactionSubmissionResponse{Id: ids[j], Response: nil}
-					} else {
-						responses <- TransactionSubmissionResponse{Id: ids[j], Err: fmt.Errorf("transaction failed: %s", response.TransactionFailures[failed].Error.Message)}
-					}
+				default:
+					// Permanent failures are not retried
 				}
 			}
 		}
-		i++
+
+		pending = retryPending
+		if len(pending) > 0 && delay > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return results
+}
+
+// pendingTransactionFromSignedTransaction fills in the response for a transaction accepted in a batch submission
+func pendingTransactionFromSignedTransaction(signedTxn *SignedTransaction) *api.SubmitTransactionResponse {
+	hash, _ := signedTxn.Hash()
+	sender := signedTxn.Transaction.Sender
+	return &api.SubmitTransactionResponse{
+		Hash:                    hash,
+		Sender:                  &sender,
+		SequenceNumber:          signedTxn.Transaction.SequenceNumber,
+		MaxGasAmount:            signedTxn.Transaction.MaxGasAmount,
+		GasUnitPrice:            signedTxn.Transaction.GasUnitPrice,
+		ExpirationTimestampSecs: signedTxn.Transaction.ExpirationTimestampSeconds,
 	}
 }
 
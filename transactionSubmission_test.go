@@ -0,0 +1,133 @@
+package endless
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifySubmissionError(t *testing.T) {
+	assert.Equal(t, SubmissionErrorTransient, ClassifySubmissionError(&api.Error{ErrorCode: "mempool_is_full"}))
+	assert.Equal(t, SubmissionErrorSequenceNumber, ClassifySubmissionError(&api.Error{ErrorCode: "sequence_number_too_old"}))
+	assert.Equal(t, SubmissionErrorSequenceNumber, ClassifySubmissionError(&api.Error{ErrorCode: "vm_error", VmErrorCode: VmStatusSequenceNumberTooNew}))
+	assert.Equal(t, SubmissionErrorPermanent, ClassifySubmissionError(&api.Error{ErrorCode: "vm_error", VmErrorCode: 1}))
+}
+
+func batchSubmitTestRequests(t *testing.T, node *testNode, sender *Account, count int) []TransactionSubmissionRequest {
+	requests := make([]TransactionSubmissionRequest, count)
+	for i := range requests {
+		entryFunction, err := CoinTransferPayload(nil, AccountOne, uint64(i+1))
+		assert.NoError(t, err)
+		requests[i] = TransactionSubmissionRequest{
+			Id:        uint64(100 + i),
+			SignedTxn: buildTestTxn(t, node, sender, TransactionPayload{Payload: entryFunction}, uint64(i)),
+		}
+	}
+	return requests
+}
+
+func runBatchSubmitTransactions(node *testNode, requests []TransactionSubmissionRequest, options ...any) []TransactionSubmissionResponse {
+	requestChan := make(chan TransactionSubmissionRequest)
+	responseChan := make(chan TransactionSubmissionResponse, len(requests))
+	go node.client.BatchSubmitTransactions(requestChan, responseChan, options...)
+	for _, request := range requests {
+		requestChan <- request
+	}
+	close(requestChan)
+
+	responses := make([]TransactionSubmissionResponse, 0, len(requests))
+	for response := range responseChan {
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func amountOf(txn *SignedTransaction) byte {
+	return txn.Transaction.Payload.Payload.(*EntryFunction).Args[1][0]
+}
+
+func TestNodeClient_BatchSubmitTransactions(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	// Amount 2 always fails, amount 4 fails once with a transient error
+	transientFailed := false
+	node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		switch amountOf(txn) {
+		case 2:
+			return http.StatusBadRequest, map[string]any{"message": "Insufficient balance", "error_code": "vm_error", "vm_error_code": 5}
+		case 4:
+			if !transientFailed {
+				transientFailed = true
+				return http.StatusServiceUnavailable, map[string]any{"message": "Mempool is full", "error_code": "mempool_is_full"}
+			}
+		}
+		return 0, nil
+	}
+
+	requests := batchSubmitTestRequests(t, node, sender, 5)
+	responses := runBatchSubmitTransactions(node, requests, BatchSubmitSize(3), BatchSubmitFlushInterval(time.Second), BatchSubmitRetry{MaxRetries: 2})
+
+	// Responses are in order, and a partial batch is flushed when input closes
+	assert.Len(t, responses, 5)
+	for i, response := range responses {
+		assert.Equal(t, requests[i].Id, response.Id)
+		if i == 1 {
+			var submissionErr *TransactionSubmissionError
+			assert.True(t, errors.As(response.Err, &submissionErr))
+			assert.Equal(t, requests[1].Id, submissionErr.Request.Id)
+			assert.Equal(t, uint32(1), submissionErr.Index)
+			assert.Equal(t, SubmissionErrorPermanent, submissionErr.Kind)
+			assert.Equal(t, "Insufficient balance", submissionErr.ApiError.Message)
+			assert.Nil(t, response.Response)
+			continue
+		}
+		assert.NoError(t, response.Err)
+		expectedHash, err := requests[i].SignedTxn.Hash()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedHash, response.Response.Hash)
+		assert.Equal(t, uint64(i), response.Response.SequenceNumber)
+	}
+	assert.True(t, transientFailed)
+	assert.Len(t, node.Submitted(), 4)
+}
+
+func TestNodeClient_BatchSubmitTransactionsResign(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+
+	// Sequence number 1 was already used
+	node.SubmitHook = func(txn *SignedTransaction) (int, map[string]any) {
+		if txn.Transaction.SequenceNumber == 1 {
+			return http.StatusBadRequest, map[string]any{"message": "SEQUENCE_NUMBER_TOO_OLD", "error_code": "sequence_number_too_old"}
+		}
+		return 0, nil
+	}
+	resign := func(request TransactionSubmissionRequest, submissionErr *TransactionSubmissionError) (*SignedTransaction, error) {
+		assert.Equal(t, SubmissionErrorSequenceNumber, submissionErr.Kind)
+		assert.True(t, IsSequenceNumberTooOld(submissionErr))
+		return buildTestTxn(t, node, sender, request.SignedTxn.Transaction.Payload, 10), nil
+	}
+
+	requests := batchSubmitTestRequests(t, node, sender, 3)
+	responses := runBatchSubmitTransactions(node, requests, BatchSubmitRetry{MaxRetries: 1, Resign: resign})
+	assert.Len(t, responses, 3)
+	for i, response := range responses {
+		assert.Equal(t, requests[i].Id, response.Id)
+		assert.NoError(t, response.Err)
+	}
+	assert.Equal(t, uint64(10), responses[1].Response.SequenceNumber)
+
+	// Without a resign function, it isn't retried
+	responses = runBatchSubmitTransactions(node, requests[1:2], BatchSubmitRetry{MaxRetries: 1})
+	assert.Len(t, responses, 1)
+	assert.True(t, IsSequenceNumberTooOld(responses[0].Err))
+}
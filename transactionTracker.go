@@ -0,0 +1,335 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/endless-labs/endless-go-sdk/api"
+)
+
+// TrackedTransactionStatus is the status of a transaction watched by a [TransactionTracker]
+type TrackedTransactionStatus uint8
+
+const (
+	// TrackedTransactionPending is a transaction that has not committed yet, and is not considered stuck
+	TrackedTransactionPending TrackedTransactionStatus = iota
+	// TrackedTransactionReplaced is a transaction that was stuck, and was replaced with a higher gas unit price
+	TrackedTransactionReplaced
+	// TrackedTransactionCommitted is a transaction where the original or one of the replacements committed
+	TrackedTransactionCommitted
+	// TrackedTransactionExpired is a transaction that expired before the original or any replacement committed
+	TrackedTransactionExpired
+)
+
+// String returns a readable name of the TrackedTransactionStatus
+func (status TrackedTransactionStatus) String() string {
+	switch status {
+	case TrackedTransactionPending:
+		return "pending"
+	case TrackedTransactionReplaced:
+		return "replaced"
+	case TrackedTransactionCommitted:
+		return "committed"
+	case TrackedTransactionExpired:
+		return "expired"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(status))
+	}
+}
+
+// TrackerStuckThreshold is how long a transaction can be uncommitted before it is considered stuck, defaults to 30s
+type TrackerStuckThreshold time.Duration
+
+// TrackerMaxReplacements is the maximum number of replacements submitted per transaction, defaults to 3
+type TrackerMaxReplacements int
+
+// TrackedTransaction is a snapshot of a transaction watched by a [TransactionTracker]
+type TrackedTransaction struct {
+	Hash          string                   // Hash of the original transaction, it identifies the tracked transaction
+	Status        TrackedTransactionStatus // Status of the transaction
+	Hashes        []string                 // Hashes of the original and every replacement, in order of submission
+	GasUnitPrices []uint64                 // GasUnitPrices of the original and every replacement, in order of submission
+	CommittedHash string                   // CommittedHash is the hash of the transaction that committed, if any
+	Result        *api.UserTransaction     // Result of the committed transaction, if any
+	SubmittedAt   time.Time                // SubmittedAt is when the latest replacement was submitted
+}
+
+type trackedTransaction struct {
+	TrackedTransaction
+	signer TransactionSigner
+	latest *SignedTransaction
+}
+
+func (tracked *trackedTransaction) snapshot() TrackedTransaction {
+	out := tracked.TrackedTransaction
+	out.Hashes = slices.Clone(tracked.Hashes)
+	out.GasUnitPrices = slices.Clone(tracked.GasUnitPrices)
+	return out
+}
+
+// TransactionTracker watches submitted transactions, and replaces those stuck in mempool.
+//
+// A transaction not committed within the [TrackerStuckThreshold] is rebuilt with the same sequence number and the
+// next [EstimateGasInfo] tier of gas unit price, signed, and submitted to replace the one in mempool.  The tracker
+// keeps checking every submitted hash, and reports which one finally committed.
+type TransactionTracker struct {
+	client          *NodeClient
+	stuckThreshold  time.Duration
+	maxReplacements int
+
+	mutex   sync.Mutex
+	tracked map[string]*trackedTransaction
+}
+
+// NewTransactionTracker creates a [TransactionTracker]
+//
+// Accepts options:
+//   - [TrackerStuckThreshold]
+//   - [TrackerMaxReplacements]
+func NewTransactionTracker(client *NodeClient, options ...any) (*TransactionTracker, error) {
+	stuckThreshold := 30 * time.Second
+	maxReplacements := 3
+	for i, option := range options {
+		switch ovalue := option.(type) {
+		case TrackerStuckThreshold:
+			stuckThreshold = time.Duration(ovalue)
+		case TrackerMaxReplacements:
+			maxReplacements = int(ovalue)
+		default:
+			return nil, fmt.Errorf("NewTransactionTracker arg [%d] unknown option type %T", i+2, option)
+		}
+	}
+	return &TransactionTracker{
+		client:          client,
+		stuckThreshold:  stuckThreshold,
+		maxReplacements: maxReplacements,
+		tracked:         make(map[string]*trackedTransaction),
+	}, nil
+}
+
+// Track starts watching a submitted single signer transaction.  The signer is used to sign replacements.
+func (tt *TransactionTracker) Track(signedTxn *SignedTransaction, signer TransactionSigner) (TrackedTransaction, error) {
+	if signer.AccountAddress() != signedTxn.Transaction.Sender {
+		return TrackedTransaction{}, errors.New("signer does not match the transaction sender")
+	}
+	hash, err := signedTxn.Hash()
+	if err != nil {
+		return TrackedTransaction{}, err
+	}
+
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	tracked := &trackedTransaction{
+		TrackedTransaction: TrackedTransaction{
+			Hash:          hash,
+			Status:        TrackedTransactionPending,
+			Hashes:        []string{hash},
+			GasUnitPrices: []uint64{signedTxn.Transaction.GasUnitPrice},
+			SubmittedAt:   time.Now(),
+		},
+		signer: signer,
+		latest: signedTxn,
+	}
+	tt.tracked[hash] = tracked
+	return tracked.snapshot(), nil
+}
+
+// BuildSignSubmitAndTrack submits a transaction, and starts tracking it
+func (tt *TransactionTracker) BuildSignSubmitAndTrack(sender TransactionSigner, payload TransactionPayload, options ...any) (TrackedTransaction, error) {
+	rawTxn, err := tt.client.BuildTransaction(sender.AccountAddress(), payload, options...)
+	if err != nil {
+		return TrackedTransaction{}, err
+	}
+	signedTxn, err := rawTxn.SignedTransaction(sender)
+	if err != nil {
+		return TrackedTransaction{}, err
+	}
+	_, err = tt.client.SubmitTransaction(signedTxn)
+	if err != nil {
+		return TrackedTransaction{}, err
+	}
+	return tt.Track(signedTxn, sender)
+}
+
+// Get returns the tracked transaction by the hash of the original transaction
+func (tt *TransactionTracker) Get(hash string) (TrackedTransaction, bool) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	tracked, ok := tt.tracked[hash]
+	if !ok {
+		return TrackedTransaction{}, false
+	}
+	return tracked.snapshot(), true
+}
+
+// Forget stops tracking a transaction by the hash of the original transaction
+func (tt *TransactionTracker) Forget(hash string) {
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	delete(tt.tracked, hash)
+}
+
+// Check looks up every unfinished tracked transaction once, replacing the stuck ones.  Returns the transactions whose
+// status changed, or that were replaced again.
+func (tt *TransactionTracker) Check() ([]TrackedTransaction, error) {
+	tt.mutex.Lock()
+	unfinished := make([]*trackedTransaction, 0, len(tt.tracked))
+	for _, tracked := range tt.tracked {
+		if tracked.Status == TrackedTransactionPending || tracked.Status == TrackedTransactionReplaced {
+			unfinished = append(unfinished, tracked)
+		}
+	}
+	tt.mutex.Unlock()
+	if len(unfinished) == 0 {
+		return nil, nil
+	}
+
+	info, err := tt.client.Info()
+	if err != nil {
+		return nil, err
+	}
+	ledgerSeconds := info.LedgerTimestamp() / 1_000_000
+	var gasInfo *EstimateGasInfo
+
+	updates := make([]TrackedTransaction, 0)
+	var errs []error
+	for _, tracked := range unfinished {
+		updated, err := tt.check(tracked, ledgerSeconds, &gasInfo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tracked transaction %s: %w", tracked.Hash, err))
+		}
+		if updated {
+			tt.mutex.Lock()
+			updates = append(updates, tracked.snapshot())
+			tt.mutex.Unlock()
+		}
+	}
+	return updates, errors.Join(errs...)
+}
+
+// Run checks tracked transactions every period until stop is closed, calling onUpdate for every update from
+// [TransactionTracker.Check].  Errors from checks are passed to onError, if not nil.
+func (tt *TransactionTracker) Run(period time.Duration, stop <-chan struct{}, onUpdate func(TrackedTransaction), onError func(error)) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			updates, err := tt.Check()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+			if onUpdate != nil {
+				for _, update := range updates {
+					onUpdate(update)
+				}
+			}
+		}
+	}
+}
+
+// check looks up a single tracked transaction, returning true if it was updated
+func (tt *TransactionTracker) check(tracked *trackedTransaction, ledgerSeconds uint64, gasInfo **EstimateGasInfo) (bool, error) {
+	tt.mutex.Lock()
+	hashes := slices.Clone(tracked.Hashes)
+	latest := tracked.latest
+	submittedAt := tracked.SubmittedAt
+	tt.mutex.Unlock()
+
+	// Any of the submitted versions may be the one that committed
+	for _, hash := range hashes {
+		txn, err := tt.client.TransactionByHash(hash)
+		if err != nil {
+			var httpError *HttpError
+			if errors.As(err, &httpError) && httpError.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return false, err
+		}
+		if txn.Type != api.TransactionVariantUser {
+			continue
+		}
+		userTxn, err := txn.UserTransaction()
+		if err != nil {
+			return false, err
+		}
+		tt.mutex.Lock()
+		tracked.Status = TrackedTransactionCommitted
+		tracked.CommittedHash = hash
+		tracked.Result = userTxn
+		tt.mutex.Unlock()
+		return true, nil
+	}
+
+	if ledgerSeconds >= latest.Transaction.ExpirationTimestampSeconds {
+		tt.mutex.Lock()
+		tracked.Status = TrackedTransactionExpired
+		tt.mutex.Unlock()
+		return true, nil
+	}
+	if time.Since(submittedAt) < tt.stuckThreshold || len(hashes)-1 >= tt.maxReplacements {
+		return false, nil
+	}
+
+	// Stuck, replace it with the next tier of gas unit price
+	if *gasInfo == nil {
+		info, err := tt.client.EstimateGasPrice()
+		if err != nil {
+			return false, err
+		}
+		*gasInfo = &info
+	}
+	replacement, err := tt.replace(tracked.signer, latest, nextGasUnitPrice(latest.Transaction.GasUnitPrice, **gasInfo))
+	if err != nil {
+		return false, err
+	}
+	hash, err := replacement.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	tt.mutex.Lock()
+	defer tt.mutex.Unlock()
+	tracked.Status = TrackedTransactionReplaced
+	tracked.Hashes = append(tracked.Hashes, hash)
+	tracked.GasUnitPrices = append(tracked.GasUnitPrices, replacement.Transaction.GasUnitPrice)
+	tracked.SubmittedAt = time.Now()
+	tracked.latest = replacement
+	return true, nil
+}
+
+// replace signs and submits a copy of the transaction with a new gas unit price
+func (tt *TransactionTracker) replace(signer TransactionSigner, signedTxn *SignedTransaction, gasUnitPrice uint64) (*SignedTransaction, error) {
+	rawTxn := *signedTxn.Transaction
+	rawTxn.GasUnitPrice = gasUnitPrice
+	replacement, err := rawTxn.SignedTransaction(signer)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tt.client.SubmitTransaction(replacement)
+	if err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// nextGasUnitPrice is the next [EstimateGasInfo] tier above the current price, or 50% more past the highest tier
+func nextGasUnitPrice(current uint64, gasInfo EstimateGasInfo) uint64 {
+	for _, tier := range []uint64{gasInfo.DeprioritizedGasEstimate, gasInfo.GasEstimate, gasInfo.PrioritizedGasEstimate} {
+		if tier > current {
+			return tier
+		}
+	}
+	return current + max(current/2, 1)
+}
+
+// NewTransactionTracker creates a [TransactionTracker], see [NewTransactionTracker]
+func (client *Client) NewTransactionTracker(options ...any) (*TransactionTracker, error) {
+	return NewTransactionTracker(client.nodeClient, options...)
+}
@@ -0,0 +1,95 @@
+package endless
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextGasUnitPrice(t *testing.T) {
+	gasInfo := EstimateGasInfo{DeprioritizedGasEstimate: 100, GasEstimate: 150, PrioritizedGasEstimate: 200}
+	assert.Equal(t, uint64(100), nextGasUnitPrice(50, gasInfo))
+	assert.Equal(t, uint64(150), nextGasUnitPrice(100, gasInfo))
+	assert.Equal(t, uint64(200), nextGasUnitPrice(150, gasInfo))
+	assert.Equal(t, uint64(300), nextGasUnitPrice(200, gasInfo))
+	assert.Equal(t, uint64(2), nextGasUnitPrice(1, EstimateGasInfo{}))
+}
+
+func TestTransactionTracker_ReplaceStuck(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+	node.SetLedgerTimestamp(1000)
+
+	tracker, err := NewTransactionTracker(node.client, TrackerStuckThreshold(0), TrackerMaxReplacements(2))
+	assert.NoError(t, err)
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	tracked, err := tracker.BuildSignSubmitAndTrack(sender, TransactionPayload{Payload: entryFunction}, GasUnitPrice(100), ExpirationSeconds(2000))
+	assert.NoError(t, err)
+	assert.Equal(t, TrackedTransactionPending, tracked.Status)
+
+	// Each check replaces it with the next tier, with the same sequence number
+	for _, expectedGas := range []uint64{150, 200} {
+		updates, err := tracker.Check()
+		assert.NoError(t, err)
+		assert.Len(t, updates, 1)
+		assert.Equal(t, TrackedTransactionReplaced, updates[0].Status)
+		assert.Equal(t, expectedGas, updates[0].GasUnitPrices[len(updates[0].GasUnitPrices)-1])
+	}
+	submitted := node.Submitted()
+	assert.Len(t, submitted, 3)
+	for _, txn := range submitted {
+		assert.Equal(t, uint64(0), txn.Transaction.SequenceNumber)
+		assert.NoError(t, txn.Verify())
+	}
+
+	// No more than max replacements
+	updates, err := tracker.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+
+	// The first replacement is the one that commits
+	current, ok := tracker.Get(tracked.Hash)
+	assert.True(t, ok)
+	node.Commit(current.Hashes[1], true, "Executed successfully")
+	updates, err = tracker.Check()
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, TrackedTransactionCommitted, updates[0].Status)
+	assert.Equal(t, current.Hashes[1], updates[0].CommittedHash)
+	assert.True(t, updates[0].Result.Success)
+
+	// Finished transactions aren't checked again
+	updates, err = tracker.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+}
+
+func TestTransactionTracker_Expired(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(sender.Address, 0)
+	node.SetLedgerTimestamp(1000)
+
+	tracker, err := NewTransactionTracker(node.client)
+	assert.NoError(t, err)
+	entryFunction, err := CoinTransferPayload(nil, AccountOne, 1)
+	assert.NoError(t, err)
+	tracked, err := tracker.BuildSignSubmitAndTrack(sender, TransactionPayload{Payload: entryFunction}, GasUnitPrice(100), ExpirationSeconds(1010))
+	assert.NoError(t, err)
+
+	// Not stuck yet under the default threshold
+	updates, err := tracker.Check()
+	assert.NoError(t, err)
+	assert.Empty(t, updates)
+
+	node.SetLedgerTimestamp(1010)
+	updates, err = tracker.Check()
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.Equal(t, tracked.Hash, updates[0].Hash)
+	assert.Equal(t, TrackedTransactionExpired, updates[0].Status)
+}
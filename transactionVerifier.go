@@ -0,0 +1,161 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+)
+
+var (
+	// ErrTransactionSignature is returned when a signature on a transaction doesn't verify
+	ErrTransactionSignature = errors.New("signature is invalid")
+	// ErrTransactionAuthKey is returned when a key that signed a transaction isn't an authentication key of the account
+	ErrTransactionAuthKey = errors.New("key is not an authentication key of the account")
+	// ErrTransactionThreshold is returned when fewer of an account's keys signed than it requires
+	ErrTransactionThreshold = errors.New("not enough of the account's keys signed")
+)
+
+// SignerRole is the part an account plays in signing a transaction
+type SignerRole uint8
+
+const (
+	SignerRoleSender    SignerRole = 0 // SignerRoleSender is the sender of the transaction
+	SignerRoleSecondary SignerRole = 1 // SignerRoleSecondary is a secondary signer of a multi-agent transaction
+	SignerRoleFeePayer  SignerRole = 2 // SignerRoleFeePayer is the account paying the gas of a fee payer transaction
+)
+
+// String returns the role as it reads in errors, e.g. "fee payer"
+func (role SignerRole) String() string {
+	switch role {
+	case SignerRoleSender:
+		return "sender"
+	case SignerRoleSecondary:
+		return "secondary signer"
+	case SignerRoleFeePayer:
+		return "fee payer"
+	default:
+		return fmt.Sprintf("signer role %d", uint8(role))
+	}
+}
+
+// SignerVerification is the result of checking one account's signature on a transaction
+type SignerVerification struct {
+	Role                  SignerRole
+	Address               AccountAddress
+	Variant               crypto.AccountAuthenticatorType // Variant of the account's authenticator
+	AuthKeys              []crypto.AuthenticationKey      // AuthKeys are the account's authentication keys that signed
+	NumSignaturesRequired int                             // NumSignaturesRequired by the account, 1 if it doesn't exist yet
+	Err                   error                           // Err is nil if the signature is valid for the account
+}
+
+// TransactionVerification is the result of [NodeClient.VerifyTransaction], with one entry per signer, sender first,
+// then the secondary signers, then the fee payer
+type TransactionVerification struct {
+	Signers []SignerVerification
+}
+
+// Err returns the errors of all signers that failed, or nil if the transaction's signatures are valid
+func (verification *TransactionVerification) Err() error {
+	var errs []error
+	for _, signer := range verification.Signers {
+		if signer.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", signer.Role, signer.Address.String(), signer.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// VerifyTransaction checks a signed transaction before submitting it.  For the sender, each secondary signer, and
+// the fee payer, it checks the signature over the message that signer must sign, that the keys are authentication
+// keys of the account on-chain, and that at least the account's NumSignaturesRequired keys signed.  An account that
+// doesn't exist yet can only be signed for by the key its address was derived from.
+//
+// The error is only for failing to check, e.g. the node is unreachable.  Signer failures are in the result, see
+// [TransactionVerification.Err].
+func (rc *NodeClient) VerifyTransaction(txn *SignedTransaction) (*TransactionVerification, error) {
+	signatures, err := txn.signers()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[AccountAddress]AccountInfo)
+	verification := &TransactionVerification{Signers: make([]SignerVerification, len(signatures))}
+	for i, signature := range signatures {
+		signer := &verification.Signers[i]
+		signer.Role = signature.role
+		signer.Address = signature.address
+		if signature.auth != nil {
+			signer.Variant = signature.auth.Variant
+		}
+		if !signature.verify() {
+			signer.Err = ErrTransactionSignature
+			continue
+		}
+
+		info, ok := accounts[signature.address]
+		if !ok {
+			info, err = rc.Account(signature.address)
+			if err != nil {
+				var httpError *HttpError
+				if !errors.As(err, &httpError) || httpError.StatusCode != http.StatusNotFound {
+					return nil, err
+				}
+				info = AccountInfo{AuthenticationKeyHex: []string{signature.address.StringLong()}, NumSignaturesRequired: 1}
+			}
+			accounts[signature.address] = info
+		}
+		signer.NumSignaturesRequired = info.NumSignaturesRequired
+		signer.AuthKeys, signer.Err = matchAuthKeys(signature.auth, info)
+	}
+	return verification, nil
+}
+
+// matchAuthKeys returns the account's authentication keys that signed, with an error if another key signed or too
+// few of the account's keys signed
+func matchAuthKeys(auth *crypto.AccountAuthenticator, info AccountInfo) ([]crypto.AuthenticationKey, error) {
+	onChainKeys, err := info.AuthenticationKey()
+	if err != nil {
+		return nil, err
+	}
+	var signed []crypto.AuthenticationKey
+	seen := make(map[crypto.AuthenticationKey]bool)
+	for _, candidates := range authenticatorAuthKeys(auth) {
+		found := false
+		for _, authKey := range candidates {
+			for _, onChainKey := range onChainKeys {
+				if string(onChainKey) == string(authKey[:]) {
+					found = true
+					if !seen[*authKey] {
+						seen[*authKey] = true
+						signed = append(signed, *authKey)
+					}
+				}
+			}
+		}
+		if !found {
+			return signed, fmt.Errorf("%w: %s", ErrTransactionAuthKey, candidates[0].ToHex())
+		}
+	}
+	if len(signed) < info.NumSignaturesRequired {
+		return signed, fmt.Errorf("%w: %d of %d", ErrTransactionThreshold, len(signed), info.NumSignaturesRequired)
+	}
+	return signed, nil
+}
+
+// authenticatorAuthKeys are the possible [crypto.AuthenticationKey]s of each key that signed.  Every authenticator
+// has one key, except a [crypto.MultiAuthKeyAuthenticator] which has one for each owner.  Its Ed25519 owners are
+// wrapped in [crypto.AnyPublicKey], but may be legacy Ed25519 accounts, so either auth key is accepted.
+func authenticatorAuthKeys(auth *crypto.AccountAuthenticator) [][]*crypto.AuthenticationKey {
+	if multiAuthKey, ok := auth.Auth.(*crypto.MultiAuthKeyAuthenticator); ok {
+		authKeys := make([][]*crypto.AuthenticationKey, len(multiAuthKey.PubKeys))
+		for i, pubKey := range multiAuthKey.PubKeys {
+			authKeys[i] = []*crypto.AuthenticationKey{pubKey.AuthKey()}
+			if ed25519PubKey, ok := pubKey.PubKey.(*crypto.Ed25519PublicKey); ok {
+				authKeys[i] = append(authKeys[i], ed25519PubKey.AuthKey())
+			}
+		}
+		return authKeys
+	}
+	return [][]*crypto.AuthenticationKey{{auth.PubKey().AuthKey()}}
+}
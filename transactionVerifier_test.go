@@ -0,0 +1,151 @@
+package endless
+
+import (
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyTransaction_FeePayer(t *testing.T) {
+	node := newTestNode(t)
+	sender, err := NewEd25519Account()
+	assert.NoError(t, err)
+	secondary, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	sponsor, err := NewEd25519SingleSenderAccount()
+	assert.NoError(t, err)
+	node.SetAccount(secondary.Address, 0, secondary.AuthKey().ToHex())
+	node.SetAccount(sponsor.Address, 0, sponsor.AuthKey().ToHex())
+
+	// The sender and secondary signer sign before the fee payer is known, the sender's account doesn't exist yet
+	rawTxn, err := node.client.BuildTransactionMultiAgent(sender.Address, envelopeTestPayload(t), AdditionalSigners{secondary.Address}, FeePayer(&AccountZero), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+	senderAuth, err := rawTxn.Sign(sender)
+	assert.NoError(t, err)
+	secondaryAuth, err := rawTxn.Sign(secondary)
+	assert.NoError(t, err)
+	assert.True(t, rawTxn.SetFeePayer(sponsor.Address))
+	sponsorAuth, err := rawTxn.Sign(sponsor)
+	assert.NoError(t, err)
+	signedTxn, ok := rawTxn.ToFeePayerSignedTransaction(senderAuth, sponsorAuth, []crypto.AccountAuthenticator{*secondaryAuth})
+	assert.True(t, ok)
+	assert.NoError(t, signedTxn.Verify())
+
+	verification, err := node.client.VerifyTransaction(signedTxn)
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Err())
+	assert.Len(t, verification.Signers, 3)
+	assert.Equal(t, SignerRoleSender, verification.Signers[0].Role)
+	assert.Equal(t, sender.Address, verification.Signers[0].Address)
+	assert.Equal(t, SignerRoleSecondary, verification.Signers[1].Role)
+	assert.Equal(t, crypto.AccountAuthenticatorSingleSender, verification.Signers[1].Variant)
+	assert.Equal(t, SignerRoleFeePayer, verification.Signers[2].Role)
+	assert.Equal(t, []crypto.AuthenticationKey{*sponsor.AuthKey()}, verification.Signers[2].AuthKeys)
+
+	// The fee payer must sign with itself as the fee payer
+	unknownFeePayerAuth, err := (&RawTransactionWithData{
+		Variant: MultiAgentWithFeePayerRawTransactionWithDataVariant,
+		Inner:   &MultiAgentWithFeePayerRawTransactionWithData{RawTxn: signedTxn.Transaction, SecondarySigners: []AccountAddress{secondary.Address}, FeePayer: &AccountZero},
+	}).Sign(sponsor)
+	assert.NoError(t, err)
+	signedTxn.Authenticator.Auth.(*FeePayerTransactionAuthenticator).FeePayerAuthenticator = unknownFeePayerAuth
+	assert.ErrorIs(t, signedTxn.Verify(), ErrTransactionSignature)
+	verification, err = node.client.VerifyTransaction(signedTxn)
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Signers[0].Err)
+	assert.ErrorIs(t, verification.Signers[2].Err, ErrTransactionSignature)
+	assert.ErrorIs(t, verification.Err(), ErrTransactionSignature)
+}
+
+func TestVerifyTransaction_AuthKeys(t *testing.T) {
+	node := newTestNode(t)
+	owner1, err := NewEd25519Account()
+	assert.NoError(t, err)
+	owner2, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+	outsider, err := NewEd25519Account()
+	assert.NoError(t, err)
+	node.SetAccount(owner1.Address, 0, owner1.AuthKey().ToHex(), owner2.AuthKey().ToHex())
+
+	rawTxn, err := node.client.BuildTransaction(owner1.Address, envelopeTestPayload(t), SequenceNumber(0), GasUnitPrice(100))
+	assert.NoError(t, err)
+	auth1, err := rawTxn.Sign(owner1)
+	assert.NoError(t, err)
+	auth2, err := rawTxn.Sign(owner2)
+	assert.NoError(t, err)
+	outsiderAuth, err := rawTxn.Sign(outsider)
+	assert.NoError(t, err)
+	multiAuthKeyTxn := func(auths ...*crypto.AccountAuthenticator) *SignedTransaction {
+		multiAuthKey := &crypto.MultiAuthKeyAuthenticator{}
+		assert.NoError(t, multiAuthKey.FromAuthenticators(auths))
+		signedTxn, err := rawTxn.SignedTransactionWithAuthenticator(&crypto.AccountAuthenticator{Variant: crypto.AccountAuthenticatorMultiAuthKey, Auth: multiAuthKey})
+		assert.NoError(t, err)
+		return signedTxn
+	}
+
+	// One of the account's keys is enough
+	verification, err := node.client.VerifyTransaction(multiAuthKeyTxn(auth2))
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Err())
+
+	// Until it requires two
+	node.accounts[owner1.Address].NumSignaturesRequired = 2
+	verification, err = node.client.VerifyTransaction(multiAuthKeyTxn(auth2))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, verification.Err(), ErrTransactionThreshold)
+	verification, err = node.client.VerifyTransaction(multiAuthKeyTxn(auth2, auth1))
+	assert.NoError(t, err)
+	assert.NoError(t, verification.Err())
+	assert.Len(t, verification.Signers[0].AuthKeys, 2)
+	assert.Equal(t, 2, verification.Signers[0].NumSignaturesRequired)
+
+	// A valid signature by a key the account doesn't have
+	signedTxn, err := rawTxn.SignedTransactionWithAuthenticator(outsiderAuth)
+	assert.NoError(t, err)
+	assert.NoError(t, signedTxn.Verify())
+	verification, err = node.client.VerifyTransaction(signedTxn)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, verification.Err(), ErrTransactionAuthKey)
+	verification, err = node.client.VerifyTransaction(multiAuthKeyTxn(auth1, outsiderAuth))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, verification.Err(), ErrTransactionAuthKey)
+}
+
+func TestVerifySignedTransactions(t *testing.T) {
+	node := newTestNode(t)
+	signers := make([]*Account, 0)
+	for i := 0; i < 6; i++ {
+		ed25519Account, err := NewEd25519Account()
+		assert.NoError(t, err)
+		singleSenderAccount, err := NewEd25519SingleSenderAccount()
+		assert.NoError(t, err)
+		secp256k1Account, err := NewSecp256k1Account()
+		assert.NoError(t, err)
+		signers = append(signers, ed25519Account, singleSenderAccount, secp256k1Account)
+	}
+
+	txns := make([]*SignedTransaction, len(signers))
+	for i, signer := range signers {
+		rawTxn, err := node.client.BuildTransaction(signer.Address, envelopeTestPayload(t), SequenceNumber(i), GasUnitPrice(100))
+		assert.NoError(t, err)
+		txns[i], err = rawTxn.SignedTransaction(signer)
+		assert.NoError(t, err)
+	}
+	for i, err := range VerifySignedTransactions(txns) {
+		assert.NoError(t, err, "transaction %d", i)
+	}
+
+	// Changing a transaction after signing invalidates only its own signature
+	txns[4].Transaction.SequenceNumber++
+	txns[8].Transaction.SequenceNumber++
+	errs := VerifySignedTransactions(txns)
+	for i, err := range errs {
+		if i == 4 || i == 8 {
+			assert.ErrorIs(t, err, ErrTransactionSignature)
+			assert.Equal(t, txns[i].Verify(), err)
+		} else {
+			assert.NoError(t, err, "transaction %d", i)
+		}
+	}
+}
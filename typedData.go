@@ -0,0 +1,289 @@
+package endless
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/endless-labs/endless-go-sdk/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+const typedDataPrehashStr = "ENDLESS::TypedData::"
+
+// ErrTypedDataSignature is returned when a [TypedData] signature doesn't verify
+var ErrTypedDataSignature = errors.New("typed data signature is invalid")
+
+//region TypedDataDomain
+
+// TypedDataDomain separates signatures between apps, versions of an app, and chains, so a signature for one can't be
+// used for another
+//
+// Implements:
+//   - [bcs.Marshaler]
+//   - [bcs.Unmarshaler]
+//   - [bcs.Struct]
+type TypedDataDomain struct {
+	Name    string // Name of the app e.g. example.com
+	Version string // Version of the app's data format, changed when the meaning of the data changes
+	ChainId uint8  // ChainId is the chain the data is used on
+}
+
+// MarshalBCS serializes the domain to bytes
+//
+// Implements:
+//   - [bcs.Marshaler]
+func (domain *TypedDataDomain) MarshalBCS(ser *bcs.Serializer) {
+	ser.WriteString(domain.Name)
+	ser.WriteString(domain.Version)
+	ser.U8(domain.ChainId)
+}
+
+// UnmarshalBCS deserializes the domain from bytes
+//
+// Implements:
+//   - [bcs.Unmarshaler]
+func (domain *TypedDataDomain) UnmarshalBCS(des *bcs.Deserializer) {
+	domain.Name = des.ReadString()
+	domain.Version = des.ReadString()
+	domain.ChainId = des.U8()
+}
+
+//endregion
+
+//region TypedData
+
+// TypedData is a BCS struct signed by a user, in the style of EIP-712.  The signing message is [TypedDataPrehash] of
+// the domain and type name, followed by the BCS of the value, so it can't be confused with a transaction, another
+// type, or another app's data.
+//
+//	order := &Order{Maker: account.Address, Amount: 100}
+//	data := &endless.TypedData{Domain: endless.TypedDataDomain{Name: "example.com", Version: "1", ChainId: 4}, TypeName: "Order", Value: order}
+//	fmt.Println(data.String()) // Show the user what they are signing
+//	auth, err := data.Sign(account)
+//
+// Every struct field in the value must be exported, so [TypedData.String] shows all of it.  [TypedData.SigningMessage]
+// returns an error otherwise.
+type TypedData struct {
+	Domain   TypedDataDomain
+	TypeName string        // TypeName names the type of Value, e.g. Order or 0x1::market::Order
+	Value    bcs.Marshaler // Value is the data being signed
+}
+
+// TypedDataPrehash is the sha3-256 of "ENDLESS::TypedData::" followed by the BCS of the domain and type name
+func TypedDataPrehash(domain *TypedDataDomain, typeName string) ([]byte, error) {
+	ser := &bcs.Serializer{}
+	ser.Struct(domain)
+	ser.WriteString(typeName)
+	if err := ser.Error(); err != nil {
+		return nil, err
+	}
+	hasher := sha3.New256()
+	hasher.Write([]byte(typedDataPrehashStr))
+	hasher.Write(ser.ToBytes())
+	return hasher.Sum(nil), nil
+}
+
+// SigningMessage is the bytes to sign, [TypedDataPrehash] followed by the BCS of the value
+func (data *TypedData) SigningMessage() ([]byte, error) {
+	if data.Value == nil {
+		return nil, errors.New("typed data has no value")
+	}
+	// Unexported fields aren't rendered, but may be serialized, so the user could sign something they weren't shown
+	if err := checkTypedValue(reflect.ValueOf(data.Value), data.TypeName); err != nil {
+		return nil, err
+	}
+	prehash, err := TypedDataPrehash(&data.Domain, data.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, err := bcs.Serialize(data.Value)
+	if err != nil {
+		return nil, err
+	}
+	return append(prehash, valueBytes...), nil
+}
+
+// Sign signs the data with any [crypto.Signer], including an [Account]
+func (data *TypedData) Sign(signer crypto.Signer) (*crypto.AccountAuthenticator, error) {
+	message, err := data.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(message)
+}
+
+// Verify checks the authenticator signed this data, returning [ErrTypedDataSignature] if not.  Check the
+// authenticator's key belongs to the expected account separately, e.g. with its AuthKey.
+func (data *TypedData) Verify(auth *crypto.AccountAuthenticator) error {
+	if auth == nil || auth.Auth == nil {
+		return ErrTypedDataSignature
+	}
+	message, err := data.SigningMessage()
+	if err != nil {
+		return err
+	}
+	if !auth.Verify(message) {
+		return ErrTypedDataSignature
+	}
+	return nil
+}
+
+// String renders the domain, type, and each field of the value, for a user to confirm before signing.  Strings are
+// quoted, so control characters can't hide or fake fields.
+func (data *TypedData) String() string {
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "Domain:  %q version %q on chain %d\n", data.Domain.Name, data.Domain.Version, data.Domain.ChainId)
+	fmt.Fprintf(out, "Type:    %q\n", data.TypeName)
+	out.WriteString("Value:")
+	renderTypedValue(out, reflect.ValueOf(data.Value), 1)
+	out.WriteString("\n")
+	return out.String()
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// hasOwnFormat tells if a value is rendered with its own String method, rather than field by field
+func hasOwnFormat(value reflect.Value) bool {
+	base := value.Type()
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	if base.Kind() == reflect.Struct && base != bigIntType {
+		return false
+	}
+	return (value.Type().Implements(stringerType) && (value.Kind() != reflect.Pointer || !value.IsNil())) ||
+		(value.CanAddr() && value.Addr().Type().Implements(stringerType))
+}
+
+// checkTypedValue returns an error if the value has a struct field that [renderTypedValue] can't show
+func checkTypedValue(value reflect.Value, path string) error {
+	if !value.IsValid() || hasOwnFormat(value) {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return checkTypedValue(value.Elem(), path)
+	case reflect.Struct:
+		if !value.CanAddr() {
+			addressable := reflect.New(value.Type()).Elem()
+			addressable.Set(value)
+			value = addressable
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				return fmt.Errorf("typed data field %s.%s is unexported, so it can't be shown to the signer", path, field.Name)
+			}
+			if err := checkTypedValue(value.Field(i), path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := checkTypedValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := checkTypedValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderTypedValue writes a value, on the same line if it's a single value, otherwise with each field, element, or
+// entry on an indented line
+func renderTypedValue(out *strings.Builder, value reflect.Value, depth int) {
+	indent := "\n" + strings.Repeat("  ", depth)
+	if !value.IsValid() {
+		out.WriteString(" null")
+		return
+	}
+	// Types such as AccountAddress and big.Int have their own formatting, but other structs show every field so a
+	// String method can't hide them
+	base := value.Type()
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	if base.Kind() != reflect.Struct || base == bigIntType {
+		if value.Type().Implements(stringerType) && (value.Kind() != reflect.Pointer || !value.IsNil()) {
+			fmt.Fprintf(out, " %s", value.Interface().(fmt.Stringer).String())
+			return
+		}
+		if value.CanAddr() && value.Addr().Type().Implements(stringerType) {
+			fmt.Fprintf(out, " %s", value.Addr().Interface().(fmt.Stringer).String())
+			return
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			out.WriteString(" null")
+			return
+		}
+		renderTypedValue(out, value.Elem(), depth)
+	case reflect.Struct:
+		// Copy unaddressable structs, so pointer receiver String methods are found on fields
+		if !value.CanAddr() {
+			addressable := reflect.New(value.Type()).Elem()
+			addressable.Set(value)
+			value = addressable
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fmt.Fprintf(out, "%s%s:", indent, field.Name)
+			renderTypedValue(out, value.Field(i), depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			bytes := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(bytes), value)
+			fmt.Fprintf(out, " %s", BytesToHex(bytes))
+			return
+		}
+		if value.Len() == 0 {
+			out.WriteString(" []")
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			fmt.Fprintf(out, "%s[%d]:", indent, i)
+			renderTypedValue(out, value.Index(i), depth+1)
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		if len(keys) == 0 {
+			out.WriteString(" {}")
+			return
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			fmt.Fprintf(out, "%s%q:", indent, fmt.Sprint(key.Interface()))
+			renderTypedValue(out, value.MapIndex(key), depth+1)
+		}
+	case reflect.String:
+		fmt.Fprintf(out, " %q", value.String())
+	default:
+		fmt.Fprintf(out, " %v", value.Interface())
+	}
+}
+
+//endregion
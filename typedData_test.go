@@ -0,0 +1,133 @@
+package endless
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/endless-labs/endless-go-sdk/bcs"
+	"github.com/stretchr/testify/assert"
+)
+
+type testOrder struct {
+	Maker  AccountAddress
+	Amount uint64
+	Price  *big.Int
+	Note   string
+	Tags   []string
+}
+
+func (order *testOrder) MarshalBCS(ser *bcs.Serializer) {
+	ser.Struct(&order.Maker)
+	ser.U64(order.Amount)
+	ser.U128(*order.Price)
+	ser.WriteString(order.Note)
+	bcs.SerializeSequenceWithFunction(order.Tags, ser, func(ser *bcs.Serializer, tag string) {
+		ser.WriteString(tag)
+	})
+}
+
+// String would hide the other fields, so must not be used for rendering
+func (order *testOrder) String() string {
+	return "harmless"
+}
+
+func testTypedData(t *testing.T, maker AccountAddress) *TypedData {
+	return &TypedData{
+		Domain:   TypedDataDomain{Name: "example.com", Version: "1", ChainId: testNodeChainId},
+		TypeName: "Order",
+		Value:    &testOrder{Maker: maker, Amount: 100, Price: big.NewInt(12345), Note: "buy\nAmount: 1", Tags: []string{"limit"}},
+	}
+}
+
+func TestTypedData_SignAndVerify(t *testing.T) {
+	ed25519Account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	secp256k1Account, err := NewSecp256k1Account()
+	assert.NoError(t, err)
+
+	for _, account := range []*Account{ed25519Account, secp256k1Account} {
+		data := testTypedData(t, account.Address)
+		auth, err := data.Sign(account)
+		assert.NoError(t, err)
+		assert.NoError(t, data.Verify(auth))
+		assert.Equal(t, account.AuthKey(), auth.PubKey().AuthKey())
+
+		// Any change to the domain, type, or value breaks the signature
+		other := testTypedData(t, account.Address)
+		other.Domain.ChainId++
+		assert.ErrorIs(t, other.Verify(auth), ErrTypedDataSignature)
+		other = testTypedData(t, account.Address)
+		other.Domain.Version = "2"
+		assert.ErrorIs(t, other.Verify(auth), ErrTypedDataSignature)
+		other = testTypedData(t, account.Address)
+		other.TypeName = "Cancel"
+		assert.ErrorIs(t, other.Verify(auth), ErrTypedDataSignature)
+		other = testTypedData(t, account.Address)
+		other.Value.(*testOrder).Amount++
+		assert.ErrorIs(t, other.Verify(auth), ErrTypedDataSignature)
+	}
+}
+
+func TestTypedData_Prehash(t *testing.T) {
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+	data := testTypedData(t, account.Address)
+	message, err := data.SigningMessage()
+	assert.NoError(t, err)
+	prehash, err := TypedDataPrehash(&data.Domain, data.TypeName)
+	assert.NoError(t, err)
+	assert.Equal(t, prehash, message[:32])
+	assert.NotEqual(t, RawTransactionPrehash(), prehash)
+	assert.NotEqual(t, OffchainMessagePrehash(), prehash)
+}
+
+func TestTypedData_String(t *testing.T) {
+	data := testTypedData(t, AccountOne)
+	assert.Equal(t, `Domain:  "example.com" version "1" on chain 4
+Type:    "Order"
+Value:
+  Maker: 0x1
+  Amount: 100
+  Price: 12345
+  Note: "buy\nAmount: 1"
+  Tags:
+    [0]: "limit"
+`, data.String())
+}
+
+type testHiddenOrder struct {
+	Amount uint64
+	fee    uint64
+}
+
+func (order *testHiddenOrder) MarshalBCS(ser *bcs.Serializer) {
+	ser.U64(order.Amount)
+	ser.U64(order.fee)
+}
+
+func TestTypedData_UnexportedFields(t *testing.T) {
+	account, err := NewEd25519Account()
+	assert.NoError(t, err)
+
+	// The fee is signed but wouldn't be shown, so it can't be signed
+	data := &TypedData{Domain: TypedDataDomain{Name: "example.com", Version: "1"}, TypeName: "Order", Value: &testHiddenOrder{Amount: 1, fee: 1000}}
+	assert.NotContains(t, data.String(), "1000")
+	_, err = data.Sign(account)
+	assert.ErrorContains(t, err, "Order.fee")
+
+	// Nested in an exported field too
+	data.Value = &testOrderBatch{Orders: []testHiddenOrder{{Amount: 1}}}
+	_, err = data.Sign(account)
+	assert.ErrorContains(t, err, "Order.Orders[0].fee")
+	data.Value = &testOrderBatch{}
+	_, err = data.Sign(account)
+	assert.NoError(t, err)
+}
+
+type testOrderBatch struct {
+	Orders []testHiddenOrder
+}
+
+func (batch *testOrderBatch) MarshalBCS(ser *bcs.Serializer) {
+	bcs.SerializeSequence(batch.Orders, ser)
+}